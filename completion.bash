@@ -18,14 +18,14 @@ _hostmanager_completion() {
             return 0
             ;;
         connect|c)
-            # 连接命令：补全主机名和IP
-            local hosts=$(hostmanager list 2>/dev/null | grep -E '^\s+' | sed 's/.*(\([^@]*\)@\([^:]*\):.*/\1 \2/' | tr '\n' ' ')
+            # 连接命令：补全主机名
+            local hosts=$(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u)
             COMPREPLY=( $(compgen -W "${hosts}" -- ${cur}) )
             return 0
             ;;
         status|s)
-            # 状态命令：补全主机名和IP  
-            local hosts=$(hostmanager list 2>/dev/null | grep -E '^\s+' | sed 's/.*(\([^@]*\)@\([^:]*\):.*/\1 \2/' | tr '\n' ' ')
+            # 状态命令：补全主机名
+            local hosts=$(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u)
             COMPREPLY=( $(compgen -W "${hosts}" -- ${cur}) )
             return 0
             ;;
@@ -37,7 +37,7 @@ _hostmanager_completion() {
         *)
             # 默认情况：如果在连接相关命令后，提供主机补全
             if [[ ${COMP_WORDS[1]} == "connect" ]] || [[ ${COMP_WORDS[1]} == "c" ]] || [[ ${COMP_WORDS[1]} == "status" ]] || [[ ${COMP_WORDS[1]} == "s" ]]; then
-                local hosts=$(hostmanager list 2>/dev/null | grep -E '^\s+' | sed 's/.*(\([^@]*\)@\([^:]*\):.*/\1 \2/' | tr '\n' ' ')
+                local hosts=$(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u)
                 COMPREPLY=( $(compgen -W "${hosts}" -- ${cur}) )
             else
                 COMPREPLY=( $(compgen -W "${commands}" -- ${cur}) )
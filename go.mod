@@ -3,11 +3,17 @@ module github.com/daihao4371/hostmanager
 go 1.24.4
 
 require (
+	github.com/mattn/go-runewidth v0.0.16
 	github.com/nsf/termbox-go v1.1.1
+	github.com/pkg/sftp v1.13.9
+	golang.org/x/crypto v0.31.0
+	golang.org/x/term v0.27.0
 	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/mattn/go-runewidth v0.0.16 // indirect
+	github.com/kr/fs v0.1.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 )
@@ -7,15 +7,25 @@ import (
 	"strconv"
 	"strings"
 	"sort"
+	"time"
+
+	"golang.org/x/term"
 
 	"github.com/daihao4371/hostmanager/internal/config"
+	"github.com/daihao4371/hostmanager/internal/export"
+	"github.com/daihao4371/hostmanager/internal/fuzzy"
+	"github.com/daihao4371/hostmanager/internal/history"
 	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/output"
+	"github.com/daihao4371/hostmanager/internal/query"
 	"github.com/daihao4371/hostmanager/internal/ssh"
 )
 
 // CLI命令处理器
 type CLI struct {
 	config *config.Config
+	scores history.Scores // 主机使用频率评分（首次搜索时加载）
+	output output.Format  // 全局 --output 指定的输出格式
 }
 
 // 创建新的CLI实例
@@ -25,8 +35,12 @@ func NewCLI(cfg *config.Config) *CLI {
 
 // 处理命令行参数
 func (c *CLI) HandleCommand(args []string) error {
+	args, err := c.parseOutputFlag(args)
+	if err != nil {
+		return err
+	}
 	if len(args) == 0 {
-		return fmt.Errorf("无效的命令参数")
+		return usageError("无效的命令参数")
 	}
 
 	command := args[0]
@@ -38,23 +52,43 @@ func (c *CLI) HandleCommand(args []string) error {
 	case "status", "s":
 		return c.handleStatus(args[1:])
 	case "history", "h":
-		return c.handleHistory()
+		return c.handleHistory(args[1:])
 	case "favorites", "fav", "f":
 		return c.handleFavorites()
 	case "groups", "g":
 		return c.handleGroups()
+	case "group":
+		return c.handleGroup(args[1:])
+	case "tag":
+		return c.handleTag(args[1:])
 	case "search":
 		return c.handleSearch(args[1:])
 	case "init":
 		return c.handleInit()
+	case "config":
+		return c.handleConfig(args[1:])
 	case "add-host":
-		return c.handleAddHost()
+		return c.handleAddHost(args[1:])
 	case "remove", "rm":
 		return c.handleRemove(args[1:])
 	case "edit":
 		return c.handleEdit(args[1:])
 	case "completion":
 		return c.handleCompletion(args[1:])
+	case "vault":
+		return c.handleVault(args[1:])
+	case "import":
+		return c.handleImport(args[1:])
+	case "export":
+		return c.handleExport(args[1:])
+	case "exec":
+		return c.handleExec(args[1:])
+	case "tunnel":
+		return c.handleTunnel(args[1:])
+	case "cp":
+		return c.handleCp(args[1:])
+	case "hostkey":
+		return c.handleHostkey(args[1:])
 	case "help", "--help", "-h":
 		c.showHelp()
 		return nil
@@ -62,7 +96,7 @@ func (c *CLI) HandleCommand(args []string) error {
 		c.showVersion()
 		return nil
 	default:
-		return fmt.Errorf("未知命令: %s. 使用 'hostmanager help' 查看帮助", command)
+		return usageError("未知命令: %s. 使用 'hostmanager help' 查看帮助", command)
 	}
 }
 
@@ -85,26 +119,34 @@ func (c *CLI) handleConnect(args []string) error {
 		// 尝试模糊搜索
 		hosts := c.searchHosts(target)
 		if len(hosts) == 0 {
-			return fmt.Errorf("未找到主机: %s", target)
+			return notFoundError(target)
 		} else if len(hosts) == 1 {
 			host = &hosts[0]
+		} else if preferred := c.preferredHost(hosts); preferred != nil {
+			// 按使用频率有明确的首选
+			host = preferred
+			fmt.Printf("🔍 找到 %d 台匹配的主机，按使用频率选择 %s\n", len(hosts), host.Name)
 		} else {
 			fmt.Printf("🔍 找到多个匹配的主机:\n")
 			for i, h := range hosts {
 				fmt.Printf("  %d. %s (%s@%s:%d)\n", i+1, h.Name, h.Username, h.IP, h.Port)
 			}
-			fmt.Printf("请使用更具体的名称或IP地址\n")
-			return nil
+			return usageError("%s 匹配到 %d 台主机，请使用更具体的名称或IP地址", target, len(hosts))
 		}
 	}
 
 	fmt.Printf("🚀 正在连接到 %s (%s@%s:%d)...\n", host.Name, host.Username, host.IP, host.Port)
 	
-	// 直接调用SSH连接
-	ssh.Connect(*host, func(h models.Host) {
-		// 简单的历史记录回调
-		fmt.Printf("✅ 连接历史已更新\n")
-	})
+	// 直接调用SSH连接，结束后记录历史
+	start := time.Now()
+	err := ssh.Connect(*host, nil)
+	entry := history.NewEntry(*host, history.SourceCLI, start, ssh.ExitStatus(err))
+	if histErr := history.Append(history.DefaultPath(), entry); histErr != nil {
+		fmt.Printf("⚠️  保存连接历史失败: %v\n", histErr)
+	}
+	if err != nil && !ssh.IsExitError(err) {
+		return fmt.Errorf("与 %s 的连接异常结束", host.Name)
+	}
 	
 	return nil
 }
@@ -113,6 +155,7 @@ func (c *CLI) handleConnect(args []string) error {
 func (c *CLI) handleList(args []string) error {
 	showGroups := false
 	showFavOnly := false
+	var terms []string
 	
 	for _, arg := range args {
 		switch arg {
@@ -120,15 +163,29 @@ func (c *CLI) handleList(args []string) error {
 			showGroups = true
 		case "--favorites", "-f":
 			showFavOnly = true
+		default:
+			terms = append(terms, arg)
 		}
 	}
 
+	// 其余参数作为筛选条件，例如: list tag:prod -tag:legacy
+	groups, err := c.queryGroups(query.Join(terms))
+	if err != nil {
+		return err
+	}
+	if showFavOnly {
+		groups = favoriteGroups(groups)
+	}
+
+	if c.output.Structured() {
+		return c.writeHosts(hostRecords(groups))
+	}
 	if showFavOnly {
-		c.listFavorites()
+		c.listFavorites(groups)
 	} else if showGroups {
-		c.listByGroups()
+		c.listByGroups(groups)
 	} else {
-		c.listAllHosts()
+		c.listAllHosts(groups)
 	}
 	
 	return nil
@@ -137,7 +194,7 @@ func (c *CLI) handleList(args []string) error {
 // 处理状态检查命令
 func (c *CLI) handleStatus(args []string) error {
 	if len(args) == 0 {
-		return c.checkAllStatus()
+		return c.checkAllStatus(c.config.Groups)
 	}
 	
 	target := args[0]
@@ -146,8 +203,21 @@ func (c *CLI) handleStatus(args []string) error {
 		host = c.findHostByIP(target)
 	}
 	
-	if host == nil {
-		return fmt.Errorf("未找到主机: %s", target)
+	if host == nil || len(args) > 1 {
+		// 不是主机名称或IP时按筛选条件检查，例如: status group:生产环境 status:offline
+		groups, err := c.queryGroups(query.Join(args))
+		if err != nil {
+			return err
+		}
+		if len(groups) == 0 {
+			return withExitCode(ExitNotFound, fmt.Errorf("没有匹配 %s 的主机", query.Join(args)))
+		}
+		return c.checkAllStatus(groups)
+	}
+
+	if c.output.Structured() {
+		groupIndex, _ := c.findHostLocation(host.Name)
+		return c.checkAllStatus([]models.Group{{Name: c.config.Groups[groupIndex].Name, Hosts: []models.Host{*host}}})
 	}
 	
 	fmt.Printf("🔍 正在检查 %s 的状态...\n", host.Name)
@@ -165,51 +235,76 @@ func (c *CLI) handleStatus(args []string) error {
 	}
 	
 	fmt.Printf("   %s %s (%s@%s:%d) - %s\n", statusIcon, host.Name, host.Username, host.IP, host.Port, statusText)
-	return nil
-}
-
-// 处理历史记录命令
-func (c *CLI) handleHistory() error {
-	fmt.Printf("📋 连接历史记录:\n")
-	// 这里需要从配置中读取历史记录
-	// 临时显示空历史
-	fmt.Printf("   暂无历史记录\n")
+	if status != "online" {
+		return withExitCode(ExitOffline, fmt.Errorf("%s 不在线", host.Name))
+	}
 	return nil
 }
 
 // 处理收藏夹命令
 func (c *CLI) handleFavorites() error {
-	c.listFavorites()
+	if c.output.Structured() {
+		return c.writeHosts(hostRecords(favoriteGroups(c.config.Groups)))
+	}
+	c.listFavorites(c.config.Groups)
 	return nil
 }
 
 // 处理分组命令
 func (c *CLI) handleGroups() error {
-	c.listByGroups()
+	if c.output.Structured() {
+		return c.writeGroups(c.config.Groups)
+	}
+	c.listByGroups(c.config.Groups)
 	return nil
 }
 
 // 处理搜索命令
 func (c *CLI) handleSearch(args []string) error {
 	if len(args) == 0 {
-		return fmt.Errorf("请提供搜索关键词")
+		return usageError("请提供搜索关键词")
 	}
 	
 	keyword := strings.Join(args, " ")
-	hosts := c.searchHosts(keyword)
+	results := c.searchMatches(keyword)
+
+	if c.output.Structured() {
+		records := []export.HostRecord{}
+		for _, r := range results {
+			records = append(records, export.NewHostRecord(r.group, r.host))
+		}
+		return c.writeHosts(records)
+	}
 	
-	if len(hosts) == 0 {
+	if len(results) == 0 {
 		fmt.Printf("🔍 未找到匹配 '%s' 的主机\n", keyword)
 		return nil
 	}
 	
-	fmt.Printf("🔍 搜索结果 (%d个匹配):\n", len(hosts))
-	for _, host := range hosts {
+	// 输出到终端时高亮匹配的字符
+	colored := term.IsTerminal(int(os.Stdout.Fd()))
+	mark := func(r searchResult, field, text string) string {
+		if !colored {
+			return text
+		}
+		return highlightMatches(text, r.match.Positions[field])
+	}
+
+	fmt.Printf("🔍 搜索结果 (%d个匹配):\n", len(results))
+	for _, r := range results {
 		favoriteIcon := ""
-		if host.Favorite {
+		if r.host.Favorite {
 			favoriteIcon = "⭐"
 		}
-		fmt.Printf("   %s%s (%s@%s:%d)\n", favoriteIcon, host.Name, host.Username, host.IP, host.Port)
+		fmt.Printf("   %s%s (%s@%s:%d)", favoriteIcon, mark(r, fuzzy.FieldName, r.host.Name),
+			mark(r, fuzzy.FieldUsername, r.host.Username), mark(r, fuzzy.FieldIP, r.host.IP), r.host.Port)
+		if len(r.host.Tags) > 0 {
+			fmt.Printf(" [%s]", mark(r, fuzzy.FieldTags, strings.Join(r.host.Tags, " ")))
+		}
+		if r.host.Description != "" {
+			fmt.Printf(" - %s", mark(r, fuzzy.FieldDescription, r.host.Description))
+		}
+		fmt.Println()
 	}
 	
 	return nil
@@ -239,30 +334,95 @@ func (c *CLI) findHostByIP(ip string) *models.Host {
 	return nil
 }
 
+// 搜索结果
+type searchResult struct {
+	group string
+	host  models.Host
+	match fuzzy.HostMatch
+}
+
 // 搜索主机
 func (c *CLI) searchHosts(keyword string) []models.Host {
-	var results []models.Host
-	keyword = strings.ToLower(keyword)
-	
+	var hosts []models.Host
+	for _, r := range c.searchMatches(keyword) {
+		hosts = append(hosts, r.host)
+	}
+	return hosts
+}
+
+// 模糊搜索主机（名称、IP、用户名、标签、描述），按匹配评分排序，评分相同时按使用频率
+func (c *CLI) searchMatches(keyword string) []searchResult {
+	var results []searchResult
 	for _, group := range c.config.Groups {
 		for _, host := range group.Hosts {
-			if strings.Contains(strings.ToLower(host.Name), keyword) ||
-			   strings.Contains(strings.ToLower(host.IP), keyword) ||
-			   strings.Contains(strings.ToLower(host.Username), keyword) {
-				results = append(results, host)
+			if match, ok := fuzzy.MatchHost(keyword, host); ok {
+				results = append(results, searchResult{group: group.Name, host: host, match: match})
 			}
 		}
 	}
-	
+
+	scores := c.frecency()
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].match.Score != results[j].match.Score {
+			return results[i].match.Score > results[j].match.Score
+		}
+		return scores.Score(results[i].host) > scores.Score(results[j].host)
+	})
 	return results
 }
 
+// 使用频率明显最高的主机，没有明确首选时返回 nil
+func (c *CLI) preferredHost(hosts []models.Host) *models.Host {
+	var best *models.Host
+	bestScore, tie := 0.0, false
+	for i := range hosts {
+		score := c.frecency().Score(hosts[i])
+		if score > bestScore {
+			best, bestScore, tie = &hosts[i], score, false
+		} else if score == bestScore {
+			tie = true
+		}
+	}
+	if tie {
+		return nil
+	}
+	return best
+}
+
+// 用 ANSI 颜色高亮匹配的字符
+func highlightMatches(text string, positions []int) string {
+	if len(positions) == 0 {
+		return text
+	}
+	marked := map[int]bool{}
+	for _, p := range positions {
+		marked[p] = true
+	}
+	var b strings.Builder
+	for i, r := range []rune(text) {
+		if marked[i] {
+			b.WriteString("\033[1;33m" + string(r) + "\033[0m")
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// 主机使用频率评分
+func (c *CLI) frecency() history.Scores {
+	if c.scores == nil {
+		c.scores = history.LoadScores(history.DefaultPath())
+	}
+	return c.scores
+}
+
 // 列出所有主机
-func (c *CLI) listAllHosts() {
+func (c *CLI) listAllHosts(groups []models.Group) {
 	fmt.Printf("📋 所有主机列表:\n")
 	
 	var allHosts []models.Host
-	for _, group := range c.config.Groups {
+	for _, group := range groups {
 		allHosts = append(allHosts, group.Hosts...)
 	}
 	
@@ -281,10 +441,10 @@ func (c *CLI) listAllHosts() {
 }
 
 // 按分组列出主机
-func (c *CLI) listByGroups() {
+func (c *CLI) listByGroups(groups []models.Group) {
 	fmt.Printf("📂 按分组显示:\n")
 	
-	for _, group := range c.config.Groups {
+	for _, group := range groups {
 		fmt.Printf("\n  📁 %s (%d台主机):\n", group.Name, len(group.Hosts))
 		for _, host := range group.Hosts {
 			favoriteIcon := ""
@@ -297,11 +457,11 @@ func (c *CLI) listByGroups() {
 }
 
 // 列出收藏夹
-func (c *CLI) listFavorites() {
+func (c *CLI) listFavorites(groups []models.Group) {
 	fmt.Printf("⭐ 收藏夹:\n")
 	
 	var favorites []models.Host
-	for _, group := range c.config.Groups {
+	for _, group := range groups {
 		for _, host := range group.Hosts {
 			if host.Favorite {
 				favorites = append(favorites, host)
@@ -319,17 +479,43 @@ func (c *CLI) listFavorites() {
 	}
 }
 
-// 检查所有主机状态
-func (c *CLI) checkAllStatus() error {
-	fmt.Printf("🔍 检查所有主机状态...\n")
+// 检查主机状态（已检查过的主机直接使用结果）
+// 有主机不在线时返回 ExitOffline
+func (c *CLI) checkAllStatus(groups []models.Group) error {
+	if !c.output.Structured() {
+		fmt.Printf("🔍 检查所有主机状态...\n")
+	}
 	
-	for _, group := range c.config.Groups {
+	if !statusesChecked(groups) {
+		groups = checkStatuses(groups)
+	}
+
+	offline := 0
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			if host.Status != "online" {
+				offline++
+			}
+		}
+	}
+	var err error
+	if offline > 0 {
+		err = withExitCode(ExitOffline, fmt.Errorf("%d 台主机不在线", offline))
+	}
+
+	if c.output.Structured() {
+		if writeErr := c.writeStatuses(groups); writeErr != nil {
+			return writeErr
+		}
+		return err
+	}
+
+	for _, group := range groups {
 		fmt.Printf("\n📁 %s:\n", group.Name)
 		for _, host := range group.Hosts {
-			status := ssh.CheckHostStatus(host)
 			statusIcon := "❓"
 			statusText := "未知"
-			switch status {
+			switch host.Status {
 			case "online":
 				statusIcon = "🟢"
 				statusText = "在线"
@@ -341,7 +527,7 @@ func (c *CLI) checkAllStatus() error {
 		}
 	}
 	
-	return nil
+	return err
 }
 
 // 显示连接帮助
@@ -364,38 +550,117 @@ func (c *CLI) showHelp() {
 
 可用命令:
    connect, c <主机>      连接到指定主机
-   list, ls, l [选项]     显示主机列表
-   status, s [主机]       检查主机状态
-   history, h             显示连接历史
+   list, ls, l [选项] [条件] 显示主机列表
+   status, s [主机|条件]   检查主机状态
+   history, h [选项]       显示连接历史
    favorites, fav, f      显示收藏夹
    groups, g              按分组显示主机
+   group <子命令>          管理分组 (add/rename/rm/move-host/reorder/merge)
+   tag <子命令>            管理标签 (ls/add/rm/rename)
    search <关键词>         搜索主机
    init                   生成配置文件模板
-   add-host              交互式添加新主机
-   edit <主机>            编辑指定主机配置
-   remove, rm <主机>      删除指定主机
+   config <子命令>         配置文件管理 (path/restore/lint)
+   add-host [选项]        添加新主机（无选项时交互式输入）
+   edit <主机> [--set 字段=值] 编辑指定主机配置
+   remove, rm <主机> [--yes] 删除指定主机
    completion <shell>     生成shell补全脚本
+   vault <子命令>          管理加密密码保险库
+   import ssh-config [路径] 从 OpenSSH 配置导入主机
+   export --format <格式>  导出为 ssh-config/ansible-ini/ansible-yaml/csv/json
+   exec [主机...] -- <命令> 在多台主机上并发执行命令
+   tunnel <主机> [--name 名称] 只建立端口转发，断线自动重连
+   tunnel start|stop|ls|logs 管理后台隧道
+   cp [-r] [主机:]源 [主机:]目标 通过 SFTP 复制文件，支持通配符和续传
+   hostkey scan|show|forget <主机> 管理主机密钥（known_hosts）
    help, --help, -h       显示此帮助信息
    version, --version, -v 显示版本信息
 
+全局选项:
+   --config <路径>        使用指定的配置文件 (也可通过 HOSTMANAGER_CONFIG 指定)
+                         默认依次查找 ./config.yaml、~/.config/hostmanager/config.yaml 等位置
+
 列表选项:
    --groups, -g          按分组显示
    --favorites, -f       仅显示收藏的主机
 
+输出选项 (list/groups/favorites/search/status/history/exec):
+   --output, -o <格式>    输出格式: json, yaml, table, tsv (默认为文本)
+   JSON/YAML 字段固定为 export --format json 中的主机字段，status 额外包含 status
+
+退出码:
+   0 成功  1 一般错误  2 参数错误  3 主机不存在  4 存在离线主机  5 远程命令执行失败
+
+筛选条件 (list/status/exec --query/export --query 及界面搜索通用):
+   tag:prod  user:root  group:生产环境  port:2222  ip:10.0.*  name:web*
+   auth:key  status:offline  fav:true    条件前加 - 表示排除，如 -tag:legacy
+   逗号分隔多个值表示“或”（tag:web,db），其余文本按模糊搜索匹配
+
+历史选项:
+   --host <主机>          按主机名称或IP筛选
+   --since <时间>         只显示指定时间之后的记录 (如 2h, 7d, 2024-01-31)
+   --limit, -n <N>       最多显示条数 (默认: 20)
+   --json                以JSON输出
+
+主机选项 (add-host):
+   --name <名称> --ip <地址> --user <用户名>   必填
+   --port <端口> --auth key|password --key <私钥路径> --group <分组>
+   --tag <标签> (可重复) --fav --desc <描述> --jump <跳板机>
+   --password-stdin      从标准输入读取密码（不提供时连接时输入）
+   edit --set 支持同名字段，例如 --set port=2222 --set tags=web,prod
+
 配置管理:
    hostmanager init                    # 创建配置文件模板
    hostmanager add-host               # 交互式添加主机
    hostmanager edit server1           # 编辑指定主机配置
    hostmanager remove server1         # 删除指定主机
+   hostmanager add-host --name web3 --ip 10.0.0.13 --user deploy --group 生产环境 --tag web
+   hostmanager edit web3 --set port=2222 --set fav=true
+   hostmanager remove web3 --yes      # 不经确认直接删除
+   hostmanager group move-host web3 测试环境   # 移动主机到其他分组
+   hostmanager group merge 临时 测试环境       # 合并分组
+   hostmanager tag add web --query "name:web*" # 为匹配的主机批量添加标签
    hostmanager completion bash >> ~/.bashrc   # 安装Bash补全
    hostmanager completion zsh >> ~/.zshrc     # 安装Zsh补全
+   hostmanager import ssh-config --dry-run     # 预览从 ~/.ssh/config 导入的主机
+   hostmanager export --format ansible-ini     # 导出 Ansible 清单
+
+批量执行:
+   hostmanager exec --group 生产环境 -- uptime          # 在分组内所有主机上执行
+   hostmanager exec --tag web --parallel 5 -- df -h /  # 按标签筛选并限制并发
+   hostmanager exec web1 web2 --json -- hostname       # 以JSON输出结果
+
+端口转发:
+   hostmanager tunnel db1                 # 建立主机配置的全部端口转发 (forwards)
+   hostmanager tunnel db1 --name pg       # 只建立名为 pg 的转发
+   hostmanager tunnel start db1           # 在后台启动隧道，自动重连并做健康检查
+   hostmanager tunnel ls                  # 查看隧道状态
+   hostmanager tunnel logs db1 -f         # 持续查看后台隧道日志
+   hostmanager tunnel stop db1            # 停止后台隧道
+
+文件传输:
+   hostmanager cp ./app.tar.gz web1:/tmp/         # 上传文件
+   hostmanager cp -r web1:/var/log/nginx ./logs  # 递归下载目录
+   hostmanager cp 'web1:/var/log/*.log' ./logs/  # 远端通配符（加引号）
+   hostmanager cp --resume db1:/backup/dump.gz db2:/backup/  # 主机间复制，支持续传
+
+主机密钥:
+   hostmanager hostkey scan web1      # 获取主机密钥，核对指纹后记录
+   hostmanager hostkey show web1      # 查看已记录的指纹
+   hostmanager hostkey forget web1    # 主机重装后删除旧记录
+
+密码保险库:
+   hostmanager vault init             # 创建加密保险库
+   hostmanager vault migrate          # 迁移配置中的明文密码
+   hostmanager vault set server1      # 设置主机密码
+   hostmanager vault rekey            # 更换保险库口令
 
 示例:
    hostmanager                    # 启动交互式UI
    hostmanager connect server1    # 连接到server1
    hostmanager list --groups      # 按分组显示主机列表
    hostmanager status server1     # 检查server1状态
-   hostmanager search web         # 搜索包含'web'的主机
+   hostmanager search web         # 模糊搜索名称、IP、用户、标签和描述
+   hostmanager list -o json       # 以JSON输出主机列表，便于脚本处理
 
 更多信息请访问: https://github.com/daihao4371/hostmanager
 `)
@@ -409,7 +674,7 @@ func (c *CLI) showVersion() {
 
 // 处理配置初始化命令
 func (c *CLI) handleInit() error {
-	configPath := "config.yaml"
+	configPath := c.config.Path()
 	
 	// 检查配置文件是否已存在
 	if _, err := os.Stat(configPath); err == nil {
@@ -430,6 +695,12 @@ func (c *CLI) handleInit() error {
 	template := `# HostManager 配置文件模板
 # 生成时间: ` + fmt.Sprintf("%v", "now") + `
 
+# 主机未配置的字段依次从 分组 > defaults > 内置默认值 继承
+# defaults:
+#   username: "admin"
+#   key_path: "~/.ssh/id_ed25519"
+#   connect_timeout: 10
+
 groups:
   - name: "生产环境"
     hosts:
@@ -470,7 +741,7 @@ ui_config:
     show_details: false
 `
 	
-	err := os.WriteFile(configPath, []byte(template), 0644)
+	err := config.Replace(configPath, []byte(template))
 	if err != nil {
 		return fmt.Errorf("创建配置文件失败: %v", err)
 	}
@@ -481,13 +752,19 @@ ui_config:
 }
 
 // 处理添加主机命令
-func (c *CLI) handleAddHost() error {
+func (c *CLI) handleAddHost(args []string) error {
+	// 带参数时不进行交互，便于脚本调用
+	if len(args) > 0 {
+		return c.addHostFromFlags(args)
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 	
 	fmt.Printf("📝 添加新主机到配置\n\n")
 	
-	// 收集主机信息
+	// 收集主机信息，默认值来自配置的 defaults 段
 	host := models.Host{}
+	defaults := c.config.HostDefaults(models.Group{})
 	
 	// 主机名称（必填）
 	for {
@@ -512,16 +789,16 @@ func (c *CLI) handleAddHost() error {
 	}
 	
 	// 端口号
-	fmt.Printf("端口号 [22]: ")
+	fmt.Printf("端口号 [%d]: ", defaults.Port)
 	portInput, _ := reader.ReadString('\n')
 	portInput = strings.TrimSpace(portInput)
 	if portInput == "" {
-		host.Port = 22
+		host.Port = defaults.Port
 	} else {
 		port, err := strconv.Atoi(portInput)
 		if err != nil || port <= 0 || port > 65535 {
-			fmt.Printf("❌ 无效端口号，使用默认端口 22\n")
-			host.Port = 22
+			fmt.Printf("❌ 无效端口号，使用默认端口 %d\n", defaults.Port)
+			host.Port = defaults.Port
 		} else {
 			host.Port = port
 		}
@@ -529,9 +806,12 @@ func (c *CLI) handleAddHost() error {
 	
 	// 用户名（必填）
 	for {
-		fmt.Printf("用户名: ")
+		fmt.Printf("用户名 [%s]: ", defaults.Username)
 		input, _ := reader.ReadString('\n')
 		host.Username = strings.TrimSpace(input)
+		if host.Username == "" {
+			host.Username = defaults.Username
+		}
 		if host.Username != "" {
 			break
 		}
@@ -539,16 +819,23 @@ func (c *CLI) handleAddHost() error {
 	}
 	
 	// 认证方式
-	fmt.Printf("认证方式 (key/password) [key]: ")
+	fmt.Printf("认证方式 (key/password) [%s]: ", defaults.AuthType)
 	authInput, _ := reader.ReadString('\n')
 	authInput = strings.TrimSpace(strings.ToLower(authInput))
-	if authInput == "" || authInput == "key" {
+	if authInput == "" {
+		authInput = defaults.AuthType
+	}
+	if authInput == "key" {
 		host.AuthType = "key"
-		fmt.Printf("私钥路径 [~/.ssh/id_rsa]: ")
+		keyDefault := defaults.KeyPath
+		if keyDefault == "" {
+			keyDefault = "~/.ssh/id_rsa"
+		}
+		fmt.Printf("私钥路径 [%s]: ", keyDefault)
 		keyInput, _ := reader.ReadString('\n')
 		keyInput = strings.TrimSpace(keyInput)
 		if keyInput == "" {
-			host.KeyPath = "~/.ssh/id_rsa"
+			host.KeyPath = keyDefault
 		} else {
 			host.KeyPath = keyInput
 		}
@@ -556,7 +843,9 @@ func (c *CLI) handleAddHost() error {
 		host.AuthType = "password"
 		fmt.Printf("密码: ")
 		passInput, _ := reader.ReadString('\n')
-		host.Password = strings.TrimSpace(passInput)
+		if err := storeHostPassword(&host, strings.TrimSpace(passInput)); err != nil {
+			return err
+		}
 	}
 	
 	// 描述（可选）
@@ -564,6 +853,11 @@ func (c *CLI) handleAddHost() error {
 	descInput, _ := reader.ReadString('\n')
 	host.Description = strings.TrimSpace(descInput)
 	
+	// 标签（可选）
+	fmt.Printf("标签 (逗号分隔) [可选]: ")
+	tagInput, _ := reader.ReadString('\n')
+	host.Tags = splitList(tagInput)
+	
 	// 是否收藏
 	fmt.Printf("添加到收藏夹? (y/N): ")
 	favInput, _ := reader.ReadString('\n')
@@ -624,7 +918,7 @@ func (c *CLI) addHostToGroup(host models.Host) error {
 	}
 	
 	// 保存配置到文件
-	err := config.SaveConfig("config.yaml", c.config)
+	err := config.SaveConfig(c.config.Path(), c.config)
 	if err != nil {
 		return fmt.Errorf("保存配置失败: %v", err)
 	}
@@ -665,7 +959,7 @@ _hostmanager_completion() {
     cur="${COMP_WORDS[COMP_CWORD]}"
     prev="${COMP_WORDS[COMP_CWORD-1]}"
     
-    commands="connect c list ls l status s search history h favorites fav f groups g init add-host edit remove rm completion help version"
+    commands="connect c list ls l status s search history h favorites fav f groups g group tag init config add-host edit remove rm completion vault import export exec tunnel cp hostkey help version"
     
     case "${prev}" in
         hostmanager|hm)
@@ -675,27 +969,117 @@ _hostmanager_completion() {
         connect|c|status|s)
             # 动态获取主机列表
             if command -v hostmanager >/dev/null 2>&1; then
-                local hosts=$(hostmanager list 2>/dev/null | grep -o '[a-zA-Z0-9_-]*@[0-9.]*' | cut -d'@' -f1 | sort -u)
+                local hosts=$(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u)
+                COMPREPLY=( $(compgen -W "${hosts}" -- ${cur}) )
+            fi
+            return 0
+            ;;
+        edit|remove|rm|start)
+            # 编辑、删除和启动后台隧道也需要主机名补全
+            if command -v hostmanager >/dev/null 2>&1; then
+                local hosts=$(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u)
                 COMPREPLY=( $(compgen -W "${hosts}" -- ${cur}) )
             fi
             return 0
             ;;
-        edit|remove|rm)
-            # 编辑和删除命令也需要主机名补全
+        tunnel)
+            # 子命令或主机名（前台运行）
+            local hosts=""
             if command -v hostmanager >/dev/null 2>&1; then
-                local hosts=$(hostmanager list 2>/dev/null | grep -o '[a-zA-Z0-9_-]*@[0-9.]*' | cut -d'@' -f1 | sort -u)
+                hosts=$(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u)
+            fi
+            COMPREPLY=( $(compgen -W "start stop ls logs ${hosts}" -- ${cur}) )
+            return 0
+            ;;
+        stop|logs)
+            # 后台隧道名称
+            if command -v hostmanager >/dev/null 2>&1; then
+                local tunnels=$(hostmanager tunnel ls --output tsv 2>/dev/null | tail -n +2 | cut -f1 | grep -v '^$')
+                COMPREPLY=( $(compgen -W "${tunnels} --all" -- ${cur}) )
+            fi
+            return 0
+            ;;
+        cp)
+            # 本地文件或 主机名:
+            local hosts=""
+            if command -v hostmanager >/dev/null 2>&1; then
+                hosts=$(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u | sed 's/$/:/')
+            fi
+            COMPREPLY=( $(compgen -W "${hosts}" -- ${cur}) $(compgen -f -- ${cur}) )
+            return 0
+            ;;
+        hostkey)
+            COMPREPLY=( $(compgen -W "scan show forget" -- ${cur}) )
+            return 0
+            ;;
+        scan|show|forget)
+            # hostkey 子命令的主机名
+            if command -v hostmanager >/dev/null 2>&1; then
+                local hosts=$(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u)
                 COMPREPLY=( $(compgen -W "${hosts}" -- ${cur}) )
             fi
             return 0
             ;;
+        group)
+            COMPREPLY=( $(compgen -W "add rename rm move-host reorder merge" -- ${cur}) )
+            return 0
+            ;;
+        tag)
+            COMPREPLY=( $(compgen -W "ls add rm rename" -- ${cur}) )
+            return 0
+            ;;
+        config)
+            COMPREPLY=( $(compgen -W "path restore lint" -- ${cur}) )
+            return 0
+            ;;
+        --config)
+            COMPREPLY=( $(compgen -f -- ${cur}) )
+            return 0
+            ;;
+        add-host)
+            COMPREPLY=( $(compgen -W "--name --ip --port --user --auth --key --group --tag --fav --desc --jump --password-stdin" -- ${cur}) )
+            return 0
+            ;;
+        --auth)
+            COMPREPLY=( $(compgen -W "key password" -- ${cur}) )
+            return 0
+            ;;
         list|ls|l)
-            COMPREPLY=( $(compgen -W "--groups --favorites -g -f" -- ${cur}) )
+            COMPREPLY=( $(compgen -W "--groups --favorites --output -g -f -o" -- ${cur}) )
+            return 0
+            ;;
+        --output|-o)
+            COMPREPLY=( $(compgen -W "json yaml table tsv" -- ${cur}) )
+            return 0
+            ;;
+        history|h)
+            COMPREPLY=( $(compgen -W "--host --since --limit --json" -- ${cur}) )
             return 0
             ;;
         completion)
             COMPREPLY=( $(compgen -W "bash zsh" -- ${cur}) )
             return 0
             ;;
+        vault)
+            COMPREPLY=( $(compgen -W "init unlock set rm rekey migrate" -- ${cur}) )
+            return 0
+            ;;
+        import)
+            COMPREPLY=( $(compgen -W "ssh-config" -- ${cur}) )
+            return 0
+            ;;
+        export)
+            COMPREPLY=( $(compgen -W "--format --file --query" -- ${cur}) )
+            return 0
+            ;;
+        exec)
+            COMPREPLY=( $(compgen -W "--group --tag --query --parallel --all --json" -- ${cur}) )
+            return 0
+            ;;
+        --format)
+            COMPREPLY=( $(compgen -W "ssh-config ansible-ini ansible-yaml csv json" -- ${cur}) )
+            return 0
+            ;;
     esac
 }
 
@@ -738,12 +1122,22 @@ _hostmanager() {
                 'f:显示收藏夹(简写)'
                 'groups:按分组显示'
                 'g:按分组显示(简写)'
+                'group:管理分组'
+                'tag:管理标签'
                 'init:生成配置文件模板'
+                'config:配置文件管理'
                 'add-host:交互式添加新主机'
                 'edit:编辑指定主机配置'
                 'remove:删除指定主机'
                 'rm:删除指定主机(简写)'
                 'completion:生成shell补全脚本'
+                'vault:管理密码保险库'
+                'import:导入主机'
+                'export:导出主机清单'
+                'exec:批量执行远程命令'
+                'tunnel:建立端口转发隧道'
+                'cp:通过 SFTP 复制文件'
+                'hostkey:管理主机密钥'
                 'help:显示帮助信息'
                 'version:显示版本信息'
             )
@@ -754,14 +1148,59 @@ _hostmanager() {
                 connect|c|status|s)
                     # 动态获取主机列表
                     if (( $+commands[hostmanager] )); then
-                        local hosts; hosts=($(hostmanager list 2>/dev/null | grep -o '[a-zA-Z0-9_-]*@[0-9.]*' | cut -d'@' -f1 | sort -u))
+                        local hosts; hosts=($(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u))
                         _describe 'hosts' hosts
                     fi
                     ;;
                 edit|remove|rm)
                     # 编辑和删除命令也需要主机名补全
                     if (( $+commands[hostmanager] )); then
-                        local hosts; hosts=($(hostmanager list 2>/dev/null | grep -o '[a-zA-Z0-9_-]*@[0-9.]*' | cut -d'@' -f1 | sort -u))
+                        local hosts; hosts=($(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u))
+                        _describe 'hosts' hosts
+                    fi
+                    ;;
+                tunnel)
+                    if (( CURRENT == 3 )); then
+                        local subcommands; subcommands=(
+                            'start:在后台启动隧道'
+                            'stop:停止后台隧道'
+                            'ls:列出隧道'
+                            'logs:查看后台隧道日志'
+                        )
+                        _describe 'subcommands' subcommands
+                    fi
+                    if (( $+commands[hostmanager] )); then
+                        case "${words[3]}" in
+                            stop|logs)
+                                local tunnels; tunnels=($(hostmanager tunnel ls --output tsv 2>/dev/null | tail -n +2 | cut -f1 | grep -v '^$'))
+                                _describe 'tunnels' tunnels
+                                ;;
+                            ls) ;;
+                            *)
+                                local hosts; hosts=($(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u))
+                                _describe 'hosts' hosts
+                                ;;
+                        esac
+                    fi
+                    ;;
+                cp)
+                    # 本地文件或 主机名:
+                    if (( $+commands[hostmanager] )); then
+                        local hosts; hosts=($(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u))
+                        compadd -S ':' -- $hosts
+                    fi
+                    _files
+                    ;;
+                hostkey)
+                    if (( CURRENT == 3 )); then
+                        local subcommands; subcommands=(
+                            'scan:获取并记录主机密钥'
+                            'show:显示已记录的指纹'
+                            'forget:删除主机密钥记录'
+                        )
+                        _describe 'subcommands' subcommands
+                    elif (( $+commands[hostmanager] )); then
+                        local hosts; hosts=($(hostmanager list --output tsv 2>/dev/null | tail -n +2 | cut -f2 | sort -u))
                         _describe 'hosts' hosts
                     fi
                     ;;
@@ -771,6 +1210,17 @@ _hostmanager() {
                         '-g:按分组显示(简写)'
                         '--favorites:仅显示收藏'
                         '-f:仅显示收藏(简写)'
+                        '--output:输出格式(json/yaml/table/tsv)'
+                        '-o:输出格式(简写)'
+                    )
+                    _describe 'options' options
+                    ;;
+                history|h)
+                    local options; options=(
+                        '--host:按主机筛选'
+                        '--since:起始时间'
+                        '--limit:最多条数'
+                        '--json:JSON输出'
                     )
                     _describe 'options' options
                     ;;
@@ -778,6 +1228,87 @@ _hostmanager() {
                     local shells; shells=('bash:Bash补全脚本' 'zsh:Zsh补全脚本')
                     _describe 'shells' shells
                     ;;
+                vault)
+                    local actions; actions=(
+                        'init:创建保险库'
+                        'unlock:验证口令'
+                        'set:设置主机密码'
+                        'rm:删除主机密码'
+                        'rekey:更换口令'
+                        'migrate:迁移明文密码'
+                    )
+                    _describe 'actions' actions
+                    ;;
+                import)
+                    local sources; sources=('ssh-config:从OpenSSH配置导入')
+                    _describe 'sources' sources
+                    ;;
+                export)
+                    local formats; formats=(
+                        'ssh-config:OpenSSH配置'
+                        'ansible-ini:Ansible INI清单'
+                        'ansible-yaml:Ansible YAML清单'
+                        'csv:CSV表格'
+                        'json:JSON'
+                    )
+                    _describe 'formats' formats
+                    ;;
+                group)
+                    local subcommands; subcommands=(
+                        'add:创建分组'
+                        'rename:重命名分组'
+                        'rm:删除分组'
+                        'move-host:移动主机到分组'
+                        'reorder:调整分组顺序'
+                        'merge:合并分组'
+                    )
+                    _describe 'subcommands' subcommands
+                    ;;
+                config)
+                    local subcommands; subcommands=(
+                        'path:显示配置文件路径'
+                        'restore:从备份恢复配置'
+                        'lint:检查配置'
+                    )
+                    _describe 'subcommands' subcommands
+                    ;;
+                tag)
+                    local subcommands; subcommands=(
+                        'ls:列出标签'
+                        'add:添加标签'
+                        'rm:删除标签'
+                        'rename:重命名标签'
+                    )
+                    _describe 'subcommands' subcommands
+                    ;;
+                add-host)
+                    local options; options=(
+                        '--name:主机名称'
+                        '--ip:IP地址'
+                        '--port:端口号'
+                        '--user:用户名'
+                        '--auth:认证方式(key/password)'
+                        '--key:私钥路径'
+                        '--group:分组'
+                        '--tag:标签'
+                        '--fav:加入收藏'
+                        '--desc:描述'
+                        '--jump:跳板机'
+                        '--password-stdin:从标准输入读取密码'
+                    )
+                    _describe 'options' options
+                    ;;
+                exec)
+                    local options; options=(
+                        '--group:按分组筛选'
+                        '--tag:按标签筛选'
+                        '--query:按条件筛选'
+                        '--parallel:并发数'
+                        '--all:所有主机'
+                        '--json:JSON输出'
+                    )
+                    _describe 'options' options
+                    ;;
                 search)
                     _message '搜索关键词'
                     ;;
@@ -796,30 +1327,45 @@ _hostmanager "$@"
 
 // 处理删除主机命令
 func (c *CLI) handleRemove(args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("请指定要删除的主机名称")
+	var hostName string
+	yes := false
+	for _, arg := range args {
+		switch arg {
+		case "--yes", "-y":
+			yes = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return usageError("未知参数: %s", arg)
+			}
+			if hostName != "" {
+				return usageError("一次只能删除一台主机")
+			}
+			hostName = arg
+		}
+	}
+	if hostName == "" {
+		return usageError("请指定要删除的主机名称")
 	}
-	
-	hostName := args[0]
 	
 	// 查找主机
 	groupIndex, hostIndex := c.findHostLocation(hostName)
 	if groupIndex == -1 {
-		return fmt.Errorf("未找到主机: %s", hostName)
+		return notFoundError(hostName)
 	}
 	
 	host := c.config.Groups[groupIndex].Hosts[hostIndex]
 	
-	// 确认删除
-	fmt.Printf("⚠️  确认删除主机 '%s' (%s@%s:%d)? (y/N): ", host.Name, host.Username, host.IP, host.Port)
-	
+	// 确认删除（--yes 跳过确认）
 	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(strings.ToLower(input))
-	
-	if input != "y" && input != "yes" {
-		fmt.Printf("操作已取消\n")
-		return nil
+	if !yes {
+		fmt.Printf("⚠️  确认删除主机 '%s' (%s@%s:%d)? (y/N): ", host.Name, host.Username, host.IP, host.Port)
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(strings.ToLower(input))
+		
+		if input != "y" && input != "yes" {
+			fmt.Printf("操作已取消\n")
+			return nil
+		}
 	}
 	
 	// 从切片中删除主机
@@ -828,8 +1374,8 @@ func (c *CLI) handleRemove(args []string) error {
 		c.config.Groups[groupIndex].Hosts[hostIndex+1:]...,
 	)
 	
-	// 如果分组为空，询问是否删除分组
-	if len(c.config.Groups[groupIndex].Hosts) == 0 {
+	// 如果分组为空，询问是否删除分组（非交互模式下保留分组）
+	if len(c.config.Groups[groupIndex].Hosts) == 0 && !yes {
 		fmt.Printf("分组 '%s' 已为空，是否删除此分组? (y/N): ", c.config.Groups[groupIndex].Name)
 		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(strings.ToLower(input))
@@ -843,10 +1389,11 @@ func (c *CLI) handleRemove(args []string) error {
 	}
 	
 	// 保存配置
-	err := config.SaveConfig("config.yaml", c.config)
+	err := config.SaveConfig(c.config.Path(), c.config)
 	if err != nil {
 		return fmt.Errorf("保存配置失败: %v", err)
 	}
+	c.removeVaultEntries(host.PasswordRef)
 	
 	fmt.Printf("✅ 主机 '%s' 已删除\n", hostName)
 	return nil
@@ -855,18 +1402,22 @@ func (c *CLI) handleRemove(args []string) error {
 // 处理编辑主机命令
 func (c *CLI) handleEdit(args []string) error {
 	if len(args) == 0 {
-		return fmt.Errorf("请指定要编辑的主机名称")
+		return usageError("请指定要编辑的主机名称")
 	}
 	
 	hostName := args[0]
+	if len(args) > 1 {
+		return c.editHostFromFlags(hostName, args[1:])
+	}
 	
 	// 查找主机
 	groupIndex, hostIndex := c.findHostLocation(hostName)
 	if groupIndex == -1 {
-		return fmt.Errorf("未找到主机: %s", hostName)
+		return notFoundError(hostName)
 	}
 	
 	host := &c.config.Groups[groupIndex].Hosts[hostIndex]
+	oldPasswordRef := host.PasswordRef
 	reader := bufio.NewReader(os.Stdin)
 	
 	fmt.Printf("📝 编辑主机: %s\n\n", host.Name)
@@ -874,6 +1425,7 @@ func (c *CLI) handleEdit(args []string) error {
 	// 编辑各个字段
 	fmt.Printf("主机名称 [%s]: ", host.Name)
 	if input := c.readInputWithDefault(reader); input != "" {
+		c.config.HostRenamed(host.Name, input)
 		host.Name = input
 	}
 	
@@ -906,10 +1458,13 @@ func (c *CLI) handleEdit(args []string) error {
 					host.KeyPath = keyInput
 				}
 				host.Password = ""
+				host.PasswordRef = ""
 			} else {
 				fmt.Printf("密码: ")
 				if passInput := c.readInputWithDefault(reader); passInput != "" {
-					host.Password = passInput
+					if err := storeHostPassword(host, passInput); err != nil {
+						return err
+					}
 				}
 				host.KeyPath = ""
 			}
@@ -921,11 +1476,22 @@ func (c *CLI) handleEdit(args []string) error {
 		host.Description = input
 	}
 	
+	// 输入 - 清空标签
+	fmt.Printf("标签 (逗号分隔，- 清空) [%s]: ", strings.Join(host.Tags, ","))
+	if input := c.readInputWithDefault(reader); input == "-" {
+		host.Tags = nil
+	} else if input != "" {
+		host.Tags = splitList(input)
+	}
+	
 	// 保存配置
-	err := config.SaveConfig("config.yaml", c.config)
+	err := config.SaveConfig(c.config.Path(), c.config)
 	if err != nil {
 		return fmt.Errorf("保存配置失败: %v", err)
 	}
+	if host.PasswordRef != oldPasswordRef {
+		c.removeVaultEntries(oldPasswordRef)
+	}
 	
 	fmt.Printf("✅ 主机 '%s' 已更新\n", host.Name)
 	return nil
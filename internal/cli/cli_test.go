@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daihao4371/hostmanager/internal/config"
+	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/vault"
+)
+
+// 测试模糊匹配到多台主机时不连接并返回参数错误
+func TestConnectAmbiguous(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	c := NewCLI(&config.Config{Groups: []models.Group{{
+		Name: "web",
+		Hosts: []models.Host{
+			{Name: "web1", IP: "10.0.0.1", Port: 22, Username: "root"},
+			{Name: "web2", IP: "10.0.0.2", Port: 22, Username: "root"},
+		},
+	}}})
+	if err := c.handleConnect([]string{"web"}); ExitCode(err) != ExitUsage {
+		t.Errorf("匹配到多台主机时应返回参数错误, 得到 %v", err)
+	}
+}
+
+// 测试导出格式无效时返回参数错误且不修改已有文件
+func TestExportInvalidFormat(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "inventory.ini")
+	if err := os.WriteFile(path, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewCLI(&config.Config{})
+	if err := c.handleExport([]string{"--format", "bogus", "--file", path}); ExitCode(err) != ExitUsage {
+		t.Errorf("无效格式应返回参数错误, 得到 %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep" {
+		t.Errorf("已有文件被修改: %q", data)
+	}
+}
+
+// 测试 history 的无效参数返回参数错误
+func TestHistoryInvalidArgs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	c := NewCLI(&config.Config{})
+	for _, args := range [][]string{{"--limit", "x"}, {"-n", "-1"}, {"--since", "zz"}} {
+		if err := c.handleHistory(args); ExitCode(err) != ExitUsage {
+			t.Errorf("%v 应返回参数错误, 得到 %v", args, err)
+		}
+	}
+}
+
+// 测试删除主机和分组后清理保险库中不再使用的密码
+func TestRemoveDeletesVaultEntries(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", dir)
+	t.Setenv("HOSTMANAGER_VAULT", filepath.Join(dir, "vault.yaml"))
+	v, err := vault.Create(vault.DefaultPath(), "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"pw-web", "pw-db", "pw-shared"} {
+		v.Set(id, "secret")
+	}
+	if err := v.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.NewConfig(filepath.Join(dir, "config.yaml"))
+	cfg.Groups = []models.Group{
+		{Name: "web", Hosts: []models.Host{
+			{Name: "web1", IP: "10.0.0.1", Port: 22, Username: "root", AuthType: "password", PasswordRef: "pw-web"},
+			{Name: "web2", IP: "10.0.0.2", Port: 22, Username: "root", AuthType: "password", PasswordRef: "pw-shared"},
+		}},
+		{Name: "db", Hosts: []models.Host{
+			{Name: "db1", IP: "10.0.1.1", Port: 22, Username: "root", AuthType: "password", PasswordRef: "pw-db"},
+			{Name: "db2", IP: "10.0.1.2", Port: 22, Username: "root", AuthType: "password", PasswordRef: "pw-shared"},
+		}},
+	}
+	c := NewCLI(cfg)
+
+	if err := c.handleRemove([]string{"web1", "--yes"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.handleGroup([]string{"rm", "db", "--yes"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := vault.Open(vault.DefaultPath(), "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// pw-shared 仍被 web2 使用
+	if ids := reopened.IDs(); len(ids) != 1 || ids[0] != "pw-shared" {
+		t.Errorf("应只保留仍在使用的条目, 得到 %v", ids)
+	}
+
+	// 改为密钥认证后不再需要密码
+	if err := c.handleEdit([]string{"web2", "--set", "auth=key", "--set", "key=~/.ssh/id_ed25519"}); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err = vault.Open(vault.DefaultPath(), "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := reopened.IDs(); len(ids) != 0 {
+		t.Errorf("改为密钥认证后应删除密码条目, 得到 %v", ids)
+	}
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/daihao4371/hostmanager/internal/config"
+	"github.com/daihao4371/hostmanager/internal/output"
+)
+
+// 处理配置文件管理命令
+func (c *CLI) handleConfig(args []string) error {
+	if len(args) == 0 {
+		c.showConfigHelp()
+		return nil
+	}
+
+	switch args[0] {
+	case "path":
+		return c.configPath(args[1:])
+	case "restore":
+		return c.configRestore(args[1:])
+	case "lint", "check":
+		return c.configLint(args[1:])
+	default:
+		return usageError("未知的 config 子命令: %s", args[0])
+	}
+}
+
+// 显示配置文件路径，--all 同时列出通过 include 引入的文件
+func (c *CLI) configPath(args []string) error {
+	all := false
+	for _, arg := range args {
+		switch arg {
+		case "--all", "-a":
+			all = true
+		default:
+			return usageError("未知参数: %s", arg)
+		}
+	}
+	if !all {
+		fmt.Println(c.config.Path())
+		return nil
+	}
+	for _, file := range c.config.Files() {
+		fmt.Println(file)
+	}
+	return nil
+}
+
+// 不带参数时列出备份，否则用指定备份（序号或文件路径）覆盖配置文件
+func (c *CLI) configRestore(args []string) error {
+	var target string
+	yes := false
+	for _, arg := range args {
+		switch {
+		case arg == "--yes" || arg == "-y":
+			yes = true
+		case strings.HasPrefix(arg, "-"):
+			return usageError("未知参数: %s", arg)
+		default:
+			target = arg
+		}
+	}
+
+	configPath := c.config.Path()
+	backups, err := config.ListBackups(configPath)
+	if err != nil {
+		return err
+	}
+
+	if target == "" {
+		if len(backups) == 0 {
+			fmt.Printf("📭 没有 %s 的备份\n", configPath)
+			return nil
+		}
+		fmt.Printf("🗂️  %s 的备份 (目录: %s):\n\n", configPath, config.BackupDir())
+		for i, b := range backups {
+			fmt.Printf("  %2d. %s  %6d 字节  %s\n", i+1, b.Time.Format("2006-01-02 15:04:05"), b.Size, b.Path)
+		}
+		fmt.Printf("\n使用 hostmanager config restore <序号> 恢复\n")
+		return nil
+	}
+
+	backupPath := target
+	if index, err := strconv.Atoi(target); err == nil {
+		if index < 1 || index > len(backups) {
+			return usageError("备份序号应在 1-%d 之间", len(backups))
+		}
+		backupPath = backups[index-1].Path
+	} else if _, err := os.Stat(backupPath); err != nil {
+		return withExitCode(ExitNotFound, fmt.Errorf("备份不存在: %s", target))
+	}
+
+	if !yes && !confirm(fmt.Sprintf("⚠️  用 %s 覆盖 %s? 当前内容会先备份 (y/N): ", backupPath, configPath)) {
+		fmt.Printf("操作已取消\n")
+		return nil
+	}
+	if err := config.Restore(configPath, backupPath); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已从 %s 恢复配置\n", backupPath)
+	return nil
+}
+
+// 检查配置文件，存在错误时返回非零退出码
+func (c *CLI) configLint(args []string) error {
+	if len(args) > 0 {
+		return usageError("用法: hostmanager config lint")
+	}
+	if _, err := os.Stat(c.config.Path()); err != nil {
+		return withExitCode(ExitNotFound, fmt.Errorf("配置文件不存在: %s", c.config.Path()))
+	}
+
+	diagnostics := c.config.Validate()
+	errorCount := 0
+	for _, d := range diagnostics {
+		if d.Severity == config.SeverityError {
+			errorCount++
+		}
+	}
+
+	if c.output.Structured() {
+		rows := output.Rows{Header: []string{"severity", "file", "line", "path", "message"}}
+		for _, d := range diagnostics {
+			rows.Rows = append(rows.Rows, []string{string(d.Severity), d.File, strconv.Itoa(d.Line), d.Path, d.Message})
+		}
+		if err := output.Write(os.Stdout, c.output, append([]config.Diagnostic{}, diagnostics...), rows); err != nil {
+			return err
+		}
+	} else if len(diagnostics) == 0 {
+		fmt.Printf("✅ 配置检查通过: %s\n", c.config.Path())
+	} else {
+		PrintDiagnostics(os.Stdout, diagnostics)
+		fmt.Printf("\n共 %d 个错误, %d 个警告\n", errorCount, len(diagnostics)-errorCount)
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("配置存在 %d 个错误", errorCount)
+	}
+	return nil
+}
+
+// 输出配置检查结果
+func PrintDiagnostics(w io.Writer, diagnostics []config.Diagnostic) {
+	for _, d := range diagnostics {
+		icon := "⚠️ "
+		if d.Severity == config.SeverityError {
+			icon = "❌"
+		}
+		fmt.Fprintf(w, "%s %s\n", icon, d)
+	}
+}
+
+// 是否为 config lint 命令（自行输出检查结果，启动时不再重复提示）
+func IsLintCommand(args []string) bool {
+	return len(args) >= 2 && args[0] == "config" && (args[1] == "lint" || args[1] == "check")
+}
+
+// 显示 config 命令帮助
+func (c *CLI) showConfigHelp() {
+	fmt.Printf("⚙️  配置文件管理用法:\n")
+	fmt.Printf("   hostmanager config path                  显示当前使用的配置文件路径\n")
+	fmt.Printf("   hostmanager config path --all            同时列出通过 include 引入的文件\n")
+	fmt.Printf("   hostmanager config restore               列出配置文件的自动备份\n")
+	fmt.Printf("   hostmanager config restore <序号|路径>     从备份恢复配置 (--yes 跳过确认)\n")
+	fmt.Printf("   hostmanager config lint                  检查配置，有错误时退出码为 1\n\n")
+	fmt.Printf("配置文件查找顺序:\n")
+	fmt.Printf("   1. --config <路径>\n")
+	fmt.Printf("   2. 环境变量 HOSTMANAGER_CONFIG\n")
+	fmt.Printf("   3. ./config.yaml\n")
+	fmt.Printf("   4. $XDG_CONFIG_HOME/hostmanager/config.yaml (默认 ~/.config/hostmanager/config.yaml)\n")
+	fmt.Printf("   5. ~/config.yaml、/etc/hostmanager/config.yaml、程序所在目录\n\n")
+	fmt.Printf("每次保存前会把旧内容备份到 %s (保留最近 20 份)\n", config.BackupDir())
+	if _, err := os.Stat(c.config.Path()); err != nil {
+		fmt.Printf("\n当前配置文件 %s 不存在，运行 hostmanager init 创建\n", c.config.Path())
+	}
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/daihao4371/hostmanager/internal/ssh"
+	"github.com/daihao4371/hostmanager/internal/transfer"
+)
+
+// cp 命令的参数
+type cpOptions struct {
+	sources   []string
+	dest      string
+	recursive bool
+	resume    bool
+	quiet     bool
+	help      bool
+}
+
+// 解析 cp 命令的参数，最后一个路径为目标
+func parseCpOptions(args []string) (cpOptions, error) {
+	var opts cpOptions
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			paths = append(paths, args[i+1:]...)
+			i = len(args)
+		case arg == "--help" || arg == "-h":
+			opts.help = true
+		case arg == "-r" || arg == "-R" || arg == "--recursive":
+			opts.recursive = true
+		case arg == "--resume":
+			opts.resume = true
+		case arg == "-q" || arg == "--quiet":
+			opts.quiet = true
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			return opts, usageError("未知参数: %s", arg)
+		default:
+			paths = append(paths, arg)
+		}
+	}
+	if opts.help {
+		return opts, nil
+	}
+	if len(paths) < 2 {
+		return opts, usageError("请指定源路径和目标路径")
+	}
+	opts.sources, opts.dest = paths[:len(paths)-1], paths[len(paths)-1]
+	return opts, nil
+}
+
+// 拆分 [主机:]路径；冒号前包含 / 时视为本地路径（例如 ./a:b），Windows 盘符同样视为本地
+func splitRemotePath(arg string) (host, path string) {
+	i := strings.Index(arg, ":")
+	if i <= 0 || strings.ContainsAny(arg[:i], `/\`) || filepath.VolumeName(arg) != "" {
+		return "", arg
+	}
+	return arg[:i], arg[i+1:]
+}
+
+// 处理 cp 命令：通过 SFTP 在本地和主机之间、或两台主机之间复制文件
+func (c *CLI) handleCp(args []string) error {
+	opts, err := parseCpOptions(args)
+	if opts.help {
+		c.showCpHelp()
+		return nil
+	}
+	if err != nil {
+		c.showCpHelp()
+		return err
+	}
+
+	sessions := make(map[string]*ssh.SFTP)
+	defer func() {
+		for _, session := range sessions {
+			session.Close()
+		}
+	}()
+	endpoint := func(arg string) (transfer.Endpoint, error) {
+		name, path := splitRemotePath(arg)
+		if name == "" {
+			return transfer.Endpoint{FS: transfer.Local{}, Path: path}, nil
+		}
+		host := c.findHostByName(name)
+		if host == nil {
+			return transfer.Endpoint{}, notFoundError(name)
+		}
+		session, ok := sessions[host.Name]
+		if !ok {
+			fmt.Printf("🔗 连接 %s (%s@%s:%d)...\n", host.Name, host.Username, host.IP, host.Port)
+			if session, err = ssh.OpenSFTP(*host); err != nil {
+				return transfer.Endpoint{}, fmt.Errorf("连接 %s 失败: %v", host.Name, err)
+			}
+			sessions[host.Name] = session
+		}
+		return transfer.Endpoint{FS: transfer.Remote{Host: host.Name, Client: session.Client}, Path: path}, nil
+	}
+
+	var sources []transfer.Endpoint
+	for _, arg := range opts.sources {
+		src, err := endpoint(arg)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, src)
+	}
+	dest, err := endpoint(opts.dest)
+	if err != nil {
+		return err
+	}
+
+	jobs, err := transfer.Plan(sources, dest, opts.recursive)
+	if err != nil {
+		return err
+	}
+	files := 0
+	for _, job := range jobs {
+		if !job.Dir {
+			files++
+		}
+	}
+	total := transfer.TotalSize(jobs)
+
+	copier := transfer.Copier{Resume: opts.resume}
+	if !opts.quiet {
+		copier.Progress = transfer.NewProgress(os.Stdout, term.IsTerminal(int(os.Stdout.Fd())), files, total)
+	}
+	started := time.Now()
+	result, errs := copier.Run(jobs)
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+	}
+
+	if !opts.quiet {
+		fmt.Printf("✅ 已复制 %d 个文件，传输 %s，用时 %s", result.Files, transfer.FormatSize(result.Bytes), time.Since(started).Round(time.Millisecond))
+		if result.Resumed > 0 {
+			fmt.Printf("，续传 %d 个", result.Resumed)
+		}
+		if result.Skipped > 0 {
+			fmt.Printf("，跳过 %d 个已存在的文件", result.Skipped)
+		}
+		fmt.Println()
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d 个文件复制失败", len(errs))
+	}
+	return nil
+}
+
+// 显示 cp 命令帮助
+func (c *CLI) showCpHelp() {
+	fmt.Printf("📁 文件传输用法:\n")
+	fmt.Printf("   hostmanager cp [-r] [--resume] [-q] [主机:]源路径... [主机:]目标路径\n\n")
+	fmt.Printf("通过 SFTP 复制文件，使用主机配置的认证方式和跳板机；源路径支持通配符\n")
+	fmt.Printf("（远端通配符请加引号，避免被本地 Shell 展开），两端都可以是远端主机。\n")
+	fmt.Printf("与 Zmodem (rz/sz) 不同，cp 不需要交互式会话，也不依赖远端安装 lrzsz。\n\n")
+	fmt.Printf("参数:\n")
+	fmt.Printf("   -r, --recursive    递归复制目录\n")
+	fmt.Printf("   --resume           续传：目标文件较小时从已有大小处继续，大小相同时跳过\n")
+	fmt.Printf("   -q, --quiet        不显示进度\n\n")
+	fmt.Printf("示例:\n")
+	fmt.Printf("   hostmanager cp ./app.tar.gz web1:/tmp/\n")
+	fmt.Printf("   hostmanager cp -r web1:/var/log/nginx ./logs\n")
+	fmt.Printf("   hostmanager cp 'web1:/var/log/*.log' ./logs/\n")
+	fmt.Printf("   hostmanager cp --resume db1:/backup/dump.sql.gz db2:/backup/\n")
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+// 测试 cp 参数解析
+func TestParseCpOptions(t *testing.T) {
+	opts, err := parseCpOptions([]string{"-r", "a", "web1:b", "--resume", "db1:/tmp/"})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if !opts.recursive || !opts.resume || strings.Join(opts.sources, ",") != "a,web1:b" || opts.dest != "db1:/tmp/" {
+		t.Errorf("解析结果错误: %+v", opts)
+	}
+
+	if opts, _ := parseCpOptions([]string{"--", "-x", "y"}); strings.Join(opts.sources, ",") != "-x" || opts.dest != "y" {
+		t.Errorf("-- 之后应视为路径: %+v", opts)
+	}
+	if _, err := parseCpOptions([]string{"a"}); err == nil {
+		t.Error("缺少目标路径应返回错误")
+	}
+	if _, err := parseCpOptions([]string{"a", "b", "--bogus"}); err == nil {
+		t.Error("未知参数应返回错误")
+	}
+}
+
+// 测试拆分 [主机:]路径
+func TestSplitRemotePath(t *testing.T) {
+	tests := []struct {
+		arg, host, path string
+	}{
+		{"web1:/var/log", "web1", "/var/log"},
+		{"web1:", "web1", ""},
+		{"web1:~/app", "web1", "~/app"},
+		{"./a:b", "", "./a:b"},
+		{"/tmp/a:b", "", "/tmp/a:b"},
+		{":x", "", ":x"},
+		{"file.txt", "", "file.txt"},
+	}
+	for _, test := range tests {
+		if host, path := splitRemotePath(test.arg); host != test.host || path != test.path {
+			t.Errorf("splitRemotePath(%q) = %q, %q", test.arg, host, path)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/output"
+	"github.com/daihao4371/hostmanager/internal/query"
+	"github.com/daihao4371/hostmanager/internal/ssh"
+	"github.com/daihao4371/hostmanager/internal/vault"
+)
+
+// 默认并发数
+const defaultExecParallel = 10
+
+// exec 命令参数
+type execOptions struct {
+	targets  []string
+	groups   []string
+	tags     []string
+	queries  []string
+	all      bool
+	parallel int
+	json     bool
+	command  string
+}
+
+// 单台主机的执行结果
+type execResult struct {
+	Host       string `json:"host" yaml:"host"`
+	Group      string `json:"group" yaml:"group"`
+	ExitCode   int    `json:"exit_code" yaml:"exit_code"`
+	DurationMs int64  `json:"duration_ms" yaml:"duration_ms"`
+	Stdout     string `json:"stdout,omitempty" yaml:"stdout,omitempty"`
+	Stderr     string `json:"stderr,omitempty" yaml:"stderr,omitempty"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// 带分组信息的主机
+type groupedHost struct {
+	group string
+	host  models.Host
+}
+
+// 处理远程命令执行
+func (c *CLI) handleExec(args []string) error {
+	opts, err := parseExecOptions(args)
+	if err != nil {
+		return err
+	}
+	// 结构化输出时不逐行打印，统一在结束后输出
+	if opts.json {
+		c.output = output.JSON
+	}
+	opts.json = c.output.Structured()
+	if opts.command == "" {
+		c.showExecHelp()
+		return nil
+	}
+
+	hosts, err := c.selectExecHosts(opts)
+	if err != nil {
+		return err
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("没有匹配的主机")
+	}
+
+	// 并发执行前统一解锁保险库，避免多个主机同时提示输入口令
+	for _, h := range hosts {
+		if h.host.PasswordRef != "" {
+			if _, err := vault.Unlock(); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	if !opts.json {
+		fmt.Printf("🚀 在 %d 台主机上执行: %s\n\n", len(hosts), opts.command)
+	}
+	results := runOnHosts(hosts, opts)
+
+	if opts.json {
+		rows := output.Rows{Header: []string{"host", "group", "exit_code", "duration_ms", "error"}}
+		for _, r := range results {
+			rows.Rows = append(rows.Rows, []string{r.Host, r.Group, strconv.Itoa(r.ExitCode), strconv.FormatInt(r.DurationMs, 10), r.Error})
+		}
+		if err := output.Write(os.Stdout, c.output, results, rows); err != nil {
+			return err
+		}
+	} else {
+		printExecSummary(results)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.ExitCode != 0 {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return withExitCode(ExitRemoteFailure, fmt.Errorf("%d/%d 台主机执行失败", failed, len(results)))
+	}
+	return nil
+}
+
+// 解析参数，-- 之后的内容为远程命令
+func parseExecOptions(args []string) (execOptions, error) {
+	opts := execOptions{parallel: defaultExecParallel}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		needValue := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", usageError("%s 需要参数值", arg)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch arg {
+		case "--":
+			opts.command = strings.Join(args[i+1:], " ")
+			return opts, nil
+		case "--group", "-g":
+			value, err := needValue()
+			if err != nil {
+				return opts, err
+			}
+			opts.groups = append(opts.groups, value)
+		case "--tag", "-t":
+			value, err := needValue()
+			if err != nil {
+				return opts, err
+			}
+			opts.tags = append(opts.tags, value)
+		case "--query", "-q":
+			value, err := needValue()
+			if err != nil {
+				return opts, err
+			}
+			opts.queries = append(opts.queries, value)
+		case "--parallel", "-p":
+			value, err := needValue()
+			if err != nil {
+				return opts, err
+			}
+			opts.parallel, err = strconv.Atoi(value)
+			if err != nil || opts.parallel <= 0 {
+				return opts, usageError("无效的并发数: %s", value)
+			}
+		case "--all", "-a":
+			opts.all = true
+		case "--json":
+			opts.json = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return opts, usageError("未知参数: %s", arg)
+			}
+			opts.targets = append(opts.targets, arg)
+		}
+	}
+
+	if len(opts.targets) > 0 || len(opts.groups) > 0 || len(opts.tags) > 0 || len(opts.queries) > 0 || opts.all {
+		return opts, usageError("请使用 -- 分隔要执行的命令，例如: hostmanager exec web -- uptime")
+	}
+	return opts, nil
+}
+
+// 选择目标主机：主机参数按名称/IP/搜索匹配，再按分组、标签和查询条件过滤
+func (c *CLI) selectExecHosts(opts execOptions) ([]groupedHost, error) {
+	if len(opts.targets) == 0 && len(opts.groups) == 0 && len(opts.tags) == 0 && len(opts.queries) == 0 && !opts.all {
+		return nil, fmt.Errorf("请指定主机、--group、--tag、--query 或 --all")
+	}
+
+	wanted := map[string]bool{}
+	for _, target := range opts.targets {
+		matches := c.resolveTargets(target)
+		if len(matches) == 0 {
+			return nil, notFoundError(target)
+		}
+		for _, host := range matches {
+			wanted[strings.ToLower(host.Name)] = true
+		}
+	}
+
+	// --group 之间为“或”，--tag 之间为“且”
+	terms := append([]string{}, opts.queries...)
+	if len(opts.groups) > 0 {
+		terms = append(terms, query.Term("group", strings.Join(opts.groups, ",")))
+	}
+	for _, tag := range opts.tags {
+		terms = append(terms, query.Term("tag", tag))
+	}
+	groups, err := c.queryGroups(strings.Join(terms, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []groupedHost
+	seen := map[string]bool{}
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			key := strings.ToLower(host.Name)
+			if seen[key] || (len(opts.targets) > 0 && !wanted[key]) {
+				continue
+			}
+			seen[key] = true
+			selected = append(selected, groupedHost{group: group.Name, host: host})
+		}
+	}
+	return selected, nil
+}
+
+// 与 connect 相同的查找顺序：名称 > IP > 模糊搜索（返回全部匹配）
+func (c *CLI) resolveTargets(target string) []models.Host {
+	if host := c.findHostByName(target); host != nil {
+		return []models.Host{*host}
+	}
+	if host := c.findHostByIP(target); host != nil {
+		return []models.Host{*host}
+	}
+	return c.searchHosts(target)
+}
+
+// 并发在所有主机上执行命令
+func runOnHosts(hosts []groupedHost, opts execOptions) []execResult {
+	results := make([]execResult, len(hosts))
+	semaphore := make(chan struct{}, opts.parallel)
+	var outputMu sync.Mutex
+	var wg sync.WaitGroup
+
+	width := 0
+	for _, h := range hosts {
+		width = max(width, runewidth.StringWidth(h.host.Name))
+	}
+
+	for i, h := range hosts {
+		wg.Add(1)
+		go func(i int, h groupedHost) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			var stdout, stderr io.Writer
+			var stdoutBuf, stderrBuf bytes.Buffer
+			var outWriter, errWriter *prefixWriter
+			if opts.json {
+				stdout, stderr = &stdoutBuf, &stderrBuf
+			} else {
+				prefix := "[" + runewidth.FillRight(h.host.Name, width) + "] "
+				outWriter = &prefixWriter{mu: &outputMu, out: os.Stdout, prefix: prefix}
+				errWriter = &prefixWriter{mu: &outputMu, out: os.Stderr, prefix: prefix}
+				stdout, stderr = outWriter, errWriter
+			}
+
+			start := time.Now()
+			exitCode, err := ssh.RunCommand(h.host, opts.command, stdout, stderr)
+			if outWriter != nil {
+				outWriter.Flush()
+				errWriter.Flush()
+			}
+
+			result := execResult{
+				Host:       h.host.Name,
+				Group:      h.group,
+				ExitCode:   exitCode,
+				DurationMs: time.Since(start).Milliseconds(),
+				Stdout:     stdoutBuf.String(),
+				Stderr:     stderrBuf.String(),
+			}
+			if err != nil {
+				result.Error = err.Error()
+				if !opts.json {
+					errWriter.Write([]byte(fmt.Sprintf("❌ %v\n", err)))
+				}
+			}
+			results[i] = result
+		}(i, h)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// 打印执行结果汇总表
+func printExecSummary(results []execResult) {
+	hostWidth, groupWidth := runewidth.StringWidth("主机"), runewidth.StringWidth("分组")
+	for _, r := range results {
+		hostWidth = max(hostWidth, runewidth.StringWidth(r.Host))
+		groupWidth = max(groupWidth, runewidth.StringWidth(r.Group))
+	}
+
+	fmt.Printf("\n📊 执行结果:\n")
+	fmt.Printf("   %s  %s  状态  退出码  耗时\n",
+		runewidth.FillRight("主机", hostWidth), runewidth.FillRight("分组", groupWidth))
+	for _, r := range results {
+		status := "✅"
+		exitCode := strconv.Itoa(r.ExitCode)
+		if r.Error != "" {
+			status = "🔴"
+			exitCode = "-"
+		} else if r.ExitCode != 0 {
+			status = "❌"
+		}
+		duration := (time.Duration(r.DurationMs) * time.Millisecond).Round(10 * time.Millisecond)
+		fmt.Printf("   %s  %s  %s    %-6s  %s\n",
+			runewidth.FillRight(r.Host, hostWidth), runewidth.FillRight(r.Group, groupWidth), status, exitCode, duration)
+	}
+}
+
+// 为每一行输出加上前缀的 Writer（多个主机共享同一把锁，避免行交错）
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	for {
+		idx := bytes.IndexByte(p.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		p.writeLine(p.buf[:idx+1])
+		p.buf = p.buf[idx+1:]
+	}
+	return len(data), nil
+}
+
+// 输出缓冲中剩余的不完整行
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		p.writeLine(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	io.WriteString(p.out, p.prefix)
+	p.out.Write(line)
+}
+
+// 显示 exec 命令帮助
+func (c *CLI) showExecHelp() {
+	fmt.Printf("⚡ 远程命令执行用法:\n")
+	fmt.Printf("   hostmanager exec [主机...] [选项] -- <命令>\n\n")
+	fmt.Printf("选项:\n")
+	fmt.Printf("   --group, -g <分组>     只在指定分组中执行（可重复）\n")
+	fmt.Printf("   --tag, -t <标签>       只在带有标签的主机上执行（可重复，需全部匹配）\n")
+	fmt.Printf("   --query, -q <条件>     按筛选条件选择主机，例如 \"tag:prod -tag:legacy\"\n")
+	fmt.Printf("   --all, -a             在所有主机上执行\n")
+	fmt.Printf("   --parallel, -p <N>    并发数 (默认: %d)\n", defaultExecParallel)
+	fmt.Printf("   --json                以JSON输出结果\n\n")
+	fmt.Printf("示例:\n")
+	fmt.Printf("   hostmanager exec web1 web2 -- uptime\n")
+	fmt.Printf("   hostmanager exec --group 生产环境 --parallel 5 -- df -h /\n")
+	fmt.Printf("   hostmanager exec --tag web --json -- systemctl is-active nginx\n")
+	fmt.Printf("   hostmanager exec -q \"user:root status:online\" -- uptime\n")
+}
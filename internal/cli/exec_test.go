@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+// 测试 exec 参数解析
+func TestParseExecOptions(t *testing.T) {
+	opts, err := parseExecOptions([]string{"web1", "--group", "生产", "-p", "3", "--json", "--", "df", "-h"})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if opts.command != "df -h" || opts.parallel != 3 || !opts.json {
+		t.Errorf("解析结果错误: %+v", opts)
+	}
+	if len(opts.targets) != 1 || len(opts.groups) != 1 {
+		t.Errorf("主机或分组解析错误: %+v", opts)
+	}
+
+	if _, err := parseExecOptions([]string{"web1", "uptime"}); err == nil {
+		t.Error("缺少 -- 时应返回错误")
+	}
+	if _, err := parseExecOptions([]string{"--parallel", "0", "--", "uptime"}); err == nil {
+		t.Error("无效并发数应返回错误")
+	}
+}
+
+// 测试按行加前缀输出
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &prefixWriter{mu: &sync.Mutex{}, out: &out, prefix: "[web] "}
+	w.Write([]byte("line1\nli"))
+	w.Write([]byte("ne2\npartial"))
+	w.Flush()
+
+	expected := "[web] line1\n[web] line2\n[web] partial\n"
+	if out.String() != expected {
+		t.Errorf("输出错误:\n%q\n期望:\n%q", out.String(), expected)
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 进程退出码（脚本可依赖，不随版本变化）
+const (
+	ExitOK            = 0 // 成功
+	ExitFailure       = 1 // 一般错误
+	ExitUsage         = 2 // 命令或参数错误
+	ExitNotFound      = 3 // 未找到主机
+	ExitOffline       = 4 // status 发现离线主机
+	ExitRemoteFailure = 5 // exec 有主机执行失败
+)
+
+// 带退出码的错误
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// 根据错误得到进程退出码
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return ExitFailure
+}
+
+// 为错误指定退出码
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// 参数错误
+func usageError(format string, args ...interface{}) error {
+	return withExitCode(ExitUsage, fmt.Errorf(format, args...))
+}
+
+// 未找到主机
+func notFoundError(target string) error {
+	return withExitCode(ExitNotFound, fmt.Errorf("未找到主机: %s", target))
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/daihao4371/hostmanager/internal/export"
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+)
+
+// 处理导出命令
+func (c *CLI) handleExport(args []string) error {
+	format := ""
+	filePath := ""
+	var terms []string
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--format":
+			if i+1 >= len(args) {
+				return usageError("--format 需要指定格式")
+			}
+			i++
+			format = args[i]
+		case "--query", "-q":
+			if i+1 >= len(args) {
+				return usageError("--query 需要指定筛选条件")
+			}
+			i++
+			terms = append(terms, args[i])
+		case "--file", "-f":
+			if i+1 >= len(args) {
+				return usageError("--file 需要指定文件路径")
+			}
+			i++
+			filePath = args[i]
+		default:
+			if value, ok := strings.CutPrefix(args[i], "--format="); ok {
+				format = value
+			} else {
+				return usageError("未知参数: %s", args[i])
+			}
+		}
+	}
+
+	if format == "" {
+		c.showExportHelp()
+		return nil
+	}
+	if !slices.Contains(export.Formats, format) {
+		return usageError("不支持的导出格式: %s (支持: %s)", format, strings.Join(export.Formats, ", "))
+	}
+
+	groups, err := c.queryGroups(strings.Join(terms, " "))
+	if err != nil {
+		return err
+	}
+
+	if filePath == "" {
+		return export.Write(os.Stdout, format, groups)
+	}
+
+	// 导出成功后再原子替换目标文件，失败时不会破坏已有文件
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, groups); err != nil {
+		return err
+	}
+	if err := fsutil.WriteFile(filePath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	fmt.Printf("✅ 已导出到 %s\n", filePath)
+	return nil
+}
+
+// 显示导出命令帮助
+func (c *CLI) showExportHelp() {
+	fmt.Printf("📤 导出命令用法:\n")
+	fmt.Printf("   hostmanager export --format <格式> [--file 文件] [--query 条件]\n\n")
+	fmt.Printf("支持的格式: %s\n\n", strings.Join(export.Formats, ", "))
+	fmt.Printf("示例:\n")
+	fmt.Printf("   hostmanager export --format ssh-config >> ~/.ssh/config.d/hostmanager\n")
+	fmt.Printf("   hostmanager export --format ansible-ini --file inventory.ini\n")
+	fmt.Printf("   hostmanager export --format json | jq '.[].name'\n")
+	fmt.Printf("   hostmanager export --format ansible-ini --query \"tag:prod -tag:legacy\"\n")
+}
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/daihao4371/hostmanager/internal/config"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 处理分组管理命令
+func (c *CLI) handleGroup(args []string) error {
+	if len(args) == 0 {
+		c.showGroupHelp()
+		return nil
+	}
+
+	// 分离 --yes 等开关和位置参数
+	var params []string
+	yes, create := false, false
+	var jump models.JumpChain
+	for i := 1; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--yes", "-y":
+			yes = true
+		case "--create":
+			create = true
+		case "--jump":
+			if i+1 >= len(args) {
+				return usageError("--jump 需要参数值")
+			}
+			i++
+			jump = models.JumpChain(splitList(args[i]))
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return usageError("未知参数: %s", arg)
+			}
+			params = append(params, arg)
+		}
+	}
+
+	var err error
+	var removedRefs []string // 删除的分组中主机的保险库条目，保存成功后清理
+	switch args[0] {
+	case "add":
+		if len(params) != 1 {
+			return usageError("用法: hostmanager group add <分组> [--jump <跳板机>]")
+		}
+		err = c.config.AddGroup(params[0], jump)
+		if err == nil {
+			fmt.Printf("✅ 已创建分组 %s\n", params[0])
+		}
+	case "rename":
+		if len(params) != 2 {
+			return usageError("用法: hostmanager group rename <原名称> <新名称>")
+		}
+		err = c.config.RenameGroup(params[0], params[1])
+		if err == nil {
+			fmt.Printf("✅ 分组 %s 已重命名为 %s\n", params[0], params[1])
+		}
+	case "rm", "remove":
+		if len(params) != 1 {
+			return usageError("用法: hostmanager group rm <分组> [--yes]")
+		}
+		if index := c.config.GroupIndex(params[0]); index >= 0 {
+			for _, host := range c.config.Groups[index].Hosts {
+				removedRefs = append(removedRefs, host.PasswordRef)
+			}
+		}
+		err = c.groupRemove(params[0], yes)
+	case "move-host", "mv":
+		if len(params) != 2 {
+			return usageError("用法: hostmanager group move-host <主机> <分组> [--create]")
+		}
+		err = c.groupMoveHost(params[0], params[1], create)
+	case "reorder":
+		err = c.groupReorder(params)
+	case "merge":
+		if len(params) != 2 {
+			return usageError("用法: hostmanager group merge <源分组> <目标分组> [--yes]")
+		}
+		err = c.groupMerge(params[0], params[1], yes)
+	default:
+		return usageError("未知的 group 子命令: %s", args[0])
+	}
+	if err != nil {
+		if err == errCancelled {
+			fmt.Printf("操作已取消\n")
+			return nil
+		}
+		return withExitCode(ExitUsage, err)
+	}
+	if err := c.saveGroups(); err != nil {
+		return err
+	}
+	c.removeVaultEntries(removedRefs...)
+	return nil
+}
+
+// 用户取消了操作
+var errCancelled = errors.New("操作已取消")
+
+// 删除分组（分组内有主机时需要确认）
+func (c *CLI) groupRemove(name string, yes bool) error {
+	index := c.config.GroupIndex(name)
+	if index < 0 {
+		return fmt.Errorf("分组 %s 不存在", name)
+	}
+	group := c.config.Groups[index]
+	if len(group.Hosts) > 0 && !yes &&
+		!confirm(fmt.Sprintf("⚠️  分组 '%s' 中还有 %d 台主机，将一并删除，确认? (y/N): ", group.Name, len(group.Hosts))) {
+		return errCancelled
+	}
+	if err := c.config.RemoveGroup(name); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 分组 '%s' 已删除\n", group.Name)
+	return nil
+}
+
+// 移动主机到其他分组
+func (c *CLI) groupMoveHost(hostName, groupName string, create bool) error {
+	if c.config.GroupIndex(groupName) < 0 {
+		if !create {
+			return fmt.Errorf("分组 %s 不存在，使用 --create 自动创建", groupName)
+		}
+		if err := c.config.AddGroup(groupName, nil); err != nil {
+			return err
+		}
+	}
+	if err := c.config.MoveHost(hostName, groupName); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 主机 %s 已移动到分组 %s\n", hostName, groupName)
+	return nil
+}
+
+// 调整分组顺序：reorder <分组> <位置> 或 reorder <分组1> <分组2> ...
+func (c *CLI) groupReorder(params []string) error {
+	if len(params) == 0 {
+		return fmt.Errorf("用法: hostmanager group reorder <分组> <位置> 或 reorder <分组1> <分组2> ...")
+	}
+	if len(params) == 2 {
+		if position, err := strconv.Atoi(params[1]); err == nil {
+			if err := c.config.MoveGroup(params[0], position); err != nil {
+				return err
+			}
+			c.printGroupOrder()
+			return nil
+		}
+	}
+	if err := c.config.ReorderGroups(params); err != nil {
+		return err
+	}
+	c.printGroupOrder()
+	return nil
+}
+
+// 合并分组（源分组会被删除，需要确认）
+func (c *CLI) groupMerge(src, dst string, yes bool) error {
+	from := c.config.GroupIndex(src)
+	if from < 0 {
+		return fmt.Errorf("分组 %s 不存在", src)
+	}
+	if !yes && !confirm(fmt.Sprintf("⚠️  将分组 '%s' 的 %d 台主机并入 '%s' 并删除 '%s'，确认? (y/N): ",
+		c.config.Groups[from].Name, len(c.config.Groups[from].Hosts), dst, c.config.Groups[from].Name)) {
+		return errCancelled
+	}
+	if err := c.config.MergeGroups(src, dst); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 分组 %s 已合并到 %s\n", src, dst)
+	return nil
+}
+
+// 打印当前分组顺序
+func (c *CLI) printGroupOrder() {
+	fmt.Printf("📂 分组顺序:\n")
+	for i, group := range c.config.Groups {
+		fmt.Printf("  %d. %s (%d)\n", i+1, group.Name, len(group.Hosts))
+	}
+}
+
+// 检查跳板机引用后保存配置
+func (c *CLI) saveGroups() error {
+	if err := c.config.ResolveJumps(); err != nil {
+		return fmt.Errorf("修改后的配置无效，未保存: %v", err)
+	}
+	if err := config.SaveConfig(c.config.Path(), c.config); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+	return nil
+}
+
+// 询问用户确认
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
+	return input == "y" || input == "yes"
+}
+
+// 显示 group 命令帮助
+func (c *CLI) showGroupHelp() {
+	fmt.Printf("📂 分组管理用法:\n")
+	fmt.Printf("   hostmanager group add <分组> [--jump <跳板机>]     创建分组\n")
+	fmt.Printf("   hostmanager group rename <原名称> <新名称>          重命名分组\n")
+	fmt.Printf("   hostmanager group rm <分组> [--yes]                删除分组及其主机\n")
+	fmt.Printf("   hostmanager group move-host <主机> <分组> [--create] 移动主机到分组\n")
+	fmt.Printf("   hostmanager group reorder <分组> <位置>             移动分组到指定位置\n")
+	fmt.Printf("   hostmanager group reorder <分组1> <分组2> ...       按给定顺序排列分组\n")
+	fmt.Printf("   hostmanager group merge <源分组> <目标分组> [--yes]  合并分组\n\n")
+	fmt.Printf("移动或合并时主机的收藏、标签和连接历史保持不变，原分组生效的连接设置（端口、用户名、认证方式、私钥、Zmodem、连接方式、超时）和跳板机会写入主机配置。\n")
+}
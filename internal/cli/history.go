@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/daihao4371/hostmanager/internal/history"
+	"github.com/daihao4371/hostmanager/internal/output"
+)
+
+// 默认显示的历史条数
+const defaultHistoryLimit = 20
+
+// 处理历史记录命令
+func (c *CLI) handleHistory(args []string) error {
+	filter := history.Filter{Limit: defaultHistoryLimit}
+	asJSON := false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--json" {
+			asJSON = true
+			continue
+		}
+		if arg != "--host" && arg != "--since" && arg != "--limit" && arg != "-n" {
+			return usageError("未知参数: %s", arg)
+		}
+		if i+1 >= len(args) {
+			return usageError("%s 需要参数值", arg)
+		}
+		i++
+		value := args[i]
+
+		switch arg {
+		case "--host":
+			filter.Host = value
+		case "--since":
+			since, err := history.ParseSince(value, time.Now())
+			if err != nil {
+				return withExitCode(ExitUsage, err)
+			}
+			filter.Since = since
+		default:
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 0 {
+				return usageError("无效的条数: %s", value)
+			}
+			filter.Limit = limit
+		}
+	}
+
+	entries, err := history.Load(history.DefaultPath())
+	if err != nil {
+		return fmt.Errorf("读取连接历史失败: %v", err)
+	}
+	entries = history.Apply(entries, filter)
+
+	if asJSON {
+		c.output = output.JSON
+	}
+	if c.output.Structured() {
+		rows := output.Rows{Header: []string{"time", "host", "ip", "port", "username", "duration_ms", "exit_status", "source"}}
+		for _, e := range entries {
+			rows.Rows = append(rows.Rows, []string{
+				e.Time.Format(time.RFC3339), e.Host, e.IP, strconv.Itoa(e.Port), e.Username,
+				strconv.FormatInt(e.DurationMs, 10), strconv.Itoa(e.ExitStatus), e.Source,
+			})
+		}
+		return output.Write(os.Stdout, c.output, entries, rows)
+	}
+
+	fmt.Printf("📋 连接历史记录:\n")
+	if len(entries) == 0 {
+		fmt.Printf("   暂无历史记录\n")
+		return nil
+	}
+
+	width := 0
+	for _, e := range entries {
+		width = max(width, runewidth.StringWidth(e.Host))
+	}
+	for _, e := range entries {
+		statusIcon := "✅"
+		if e.ExitStatus < 0 {
+			statusIcon = "🔴"
+		} else if e.ExitStatus > 0 {
+			statusIcon = "⚠️ "
+		}
+		fmt.Printf("   %s %s  %s  %-28s %8s  退出码 %-3d [%s]\n",
+			statusIcon, e.Time.Local().Format("2006-01-02 15:04"), runewidth.FillRight(e.Host, width),
+			fmt.Sprintf("%s@%s:%d", e.Username, e.IP, e.Port), e.Duration().Round(time.Second), e.ExitStatus, e.Source)
+	}
+	return nil
+}
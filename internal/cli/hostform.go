@@ -0,0 +1,388 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/daihao4371/hostmanager/internal/config"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 主机名（非IP）允许的字符，兼容内网中常见的下划线
+var hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9._-]*[A-Za-z0-9_])?$`)
+
+// 单个字段的校验错误
+type fieldError struct {
+	Field   string
+	Message string
+}
+
+// 按字段汇总的校验错误
+type fieldErrors []fieldError
+
+func (e *fieldErrors) add(field, format string, args ...interface{}) {
+	*e = append(*e, fieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e fieldErrors) Error() string {
+	lines := []string{"主机配置无效:"}
+	for _, fe := range e {
+		lines = append(lines, fmt.Sprintf("   %s: %s", fe.Field, fe.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// 有错误时返回参数错误，否则返回 nil
+func (e fieldErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return withExitCode(ExitUsage, e)
+}
+
+// 非交互式主机表单（add-host 的参数和 edit --set 共用）
+type hostForm struct {
+	host          models.Host
+	group         string
+	passwordStdin bool
+	errs          fieldErrors
+}
+
+// 设置单个字段，值无法解析时记录到对应字段；设置过的字段即使与默认值相同也写入配置
+func (f *hostForm) set(field, value string) {
+	h := &f.host
+	switch strings.ToLower(field) {
+	case "name":
+		h.Name = value
+	case "ip", "host":
+		h.IP = value
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			f.errs.add("port", "不是有效的数字: %q", value)
+			return
+		}
+		h.Port, h.Inherited.Port = port, 0
+	case "user", "username":
+		h.Username, h.Inherited.Username = value, ""
+	case "auth", "auth_type":
+		h.AuthType, h.Inherited.AuthType = strings.ToLower(value), ""
+	case "key", "key_path":
+		h.KeyPath, h.Inherited.KeyPath = value, ""
+	case "group":
+		f.group = value
+	case "tag", "tags":
+		h.Tags = splitList(value)
+	case "fav", "favorite":
+		fav, err := parseBool(value)
+		if err != nil {
+			f.errs.add("favorite", "应为 true 或 false: %q", value)
+			return
+		}
+		h.Favorite = fav
+	case "desc", "description":
+		h.Description = value
+	case "jump":
+		h.Jump = models.JumpChain(splitList(value))
+	case "connect_mode", "mode":
+		h.ConnectMode, h.Inherited.ConnectMode = value, ""
+	case "zmodem", "zmodem_enable":
+		enabled, err := parseBool(value)
+		if err != nil {
+			f.errs.add("zmodem_enable", "应为 true 或 false: %q", value)
+			return
+		}
+		h.ZmodemEnable, h.Inherited.ZmodemEnable = &enabled, nil
+	default:
+		f.errs.add(field, "未知字段 (可用: name, ip, port, user, auth, key, group, tags, fav, description, jump, connect_mode, zmodem)")
+	}
+}
+
+// 解析 add-host 参数
+func parseAddHostFlags(args []string) *hostForm {
+	// 未指定的字段留空，保存后继承分组和全局默认值
+	form := &hostForm{}
+	var tags []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") {
+			form.errs.add(arg, "无法识别的参数")
+			continue
+		}
+
+		switch name {
+		case "fav", "favorite":
+			// --fav 可以不带值
+			if !hasValue {
+				form.host.Favorite = true
+				continue
+			}
+		case "password-stdin":
+			form.passwordStdin = true
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				form.errs.add(name, "缺少参数值")
+				continue
+			}
+			i++
+			value = args[i]
+		}
+		// --tag 可重复
+		if name == "tag" {
+			tags = append(tags, splitList(value)...)
+			continue
+		}
+		form.set(name, value)
+	}
+	if tags != nil {
+		form.host.Tags = tags
+	}
+	return form
+}
+
+// 解析 edit 的 --set field=value 参数
+func parseEditFlags(host models.Host, group string, args []string) *hostForm {
+	form := &hostForm{host: host, group: group}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--set", "-s":
+			if i+1 >= len(args) {
+				form.errs.add("--set", "缺少 field=value")
+				continue
+			}
+			i++
+			field, value, ok := strings.Cut(args[i], "=")
+			if !ok {
+				form.errs.add(args[i], "格式应为 field=value")
+				continue
+			}
+			form.set(strings.TrimSpace(field), strings.TrimSpace(value))
+		case "--password-stdin":
+			form.passwordStdin = true
+		default:
+			form.errs.add(args[i], "无法识别的参数")
+		}
+	}
+	return form
+}
+
+// 校验主机配置；original 为编辑前的名称，新增时为空
+func validateHost(host models.Host, cfg *config.Config, original string) fieldErrors {
+	var errs fieldErrors
+
+	// 已有主机名称 -> 所在分组
+	existing := map[string]string{}
+	for _, group := range cfg.Groups {
+		for _, h := range group.Hosts {
+			existing[strings.ToLower(h.Name)] = group.Name
+		}
+	}
+
+	if strings.TrimSpace(host.Name) == "" {
+		errs.add("name", "不能为空")
+	} else if group, ok := existing[strings.ToLower(host.Name)]; ok && !strings.EqualFold(host.Name, original) {
+		errs.add("name", "主机 %s 已存在于分组 %s", host.Name, group)
+	}
+
+	switch {
+	case host.IP == "":
+		errs.add("ip", "不能为空")
+	case net.ParseIP(host.IP) == nil && !hostnamePattern.MatchString(host.IP):
+		errs.add("ip", "不是有效的IP地址或主机名: %q", host.IP)
+	}
+
+	if host.Port <= 0 || host.Port > 65535 {
+		errs.add("port", "端口号应在 1-65535 之间: %d", host.Port)
+	}
+	if strings.TrimSpace(host.Username) == "" {
+		errs.add("user", "不能为空")
+	}
+
+	switch host.AuthType {
+	case "key":
+		if host.KeyPath == "" {
+			errs.add("key", "密钥认证需要指定私钥路径")
+		}
+	case "password":
+	default:
+		errs.add("auth", "应为 key 或 password: %q", host.AuthType)
+	}
+
+	for _, tag := range host.Tags {
+		if strings.ContainsAny(tag, " \t,") {
+			errs.add("tags", "标签不能包含空白或逗号: %q", tag)
+		}
+	}
+	if host.ConnectMode != "" && host.ConnectMode != "native" && host.ConnectMode != "external" {
+		errs.add("connect_mode", "应为 native 或 external: %q", host.ConnectMode)
+	}
+	if !host.Jump.IsNone() {
+		for _, name := range host.Jump {
+			if strings.EqualFold(name, host.Name) || strings.EqualFold(name, original) {
+				errs.add("jump", "不能使用自身作为跳板机")
+			} else if _, ok := existing[strings.ToLower(name)]; !ok {
+				errs.add("jump", "跳板机 %s 不存在", name)
+			}
+		}
+	}
+	return errs
+}
+
+// 通过参数添加主机
+func (c *CLI) addHostFromFlags(args []string) error {
+	form := parseAddHostFlags(args)
+	if form.group == "" {
+		if len(c.config.Groups) == 0 {
+			form.errs.add("group", "配置中没有分组，请使用 --group 指定")
+		} else {
+			form.group = c.config.Groups[0].Name
+		}
+	}
+
+	// 按生效的值校验（主机 > 分组 > 全局 > 内置默认值）
+	effective := form.host
+	if form.passwordStdin {
+		effective.AuthType = "password"
+	}
+	c.config.ApplyHostDefaults(c.findGroup(form.group), &effective)
+	form.errs = append(form.errs, validateHost(effective, c.config, "")...)
+	if err := form.errs.err(); err != nil {
+		return err
+	}
+
+	if err := c.readFormPassword(form); err != nil {
+		return err
+	}
+	c.insertHost(form.group, form.host)
+
+	if err := config.SaveConfig(c.config.Path(), c.config); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+	fmt.Printf("✅ 主机 %s 已添加到分组 %s\n", form.host.Name, form.group)
+	return nil
+}
+
+// 通过 --set 编辑主机
+func (c *CLI) editHostFromFlags(hostName string, args []string) error {
+	groupIndex, hostIndex := c.findHostLocation(hostName)
+	if groupIndex == -1 {
+		return notFoundError(hostName)
+	}
+	original := c.config.Groups[groupIndex].Hosts[hostIndex]
+	groupName := c.config.Groups[groupIndex].Name
+
+	form := parseEditFlags(original, groupName, args)
+	if form.group == "" {
+		form.errs.add("group", "不能为空")
+	}
+	if form.host.AuthType == "key" {
+		form.host.Password, form.host.PasswordRef = "", ""
+	} else if form.host.AuthType == "password" {
+		form.host.KeyPath = ""
+		// 只在改为密码认证时要求提供密码；未保存密码的主机连接时会提示输入
+		if original.AuthType != "password" && !form.host.HasStoredPassword() && !form.passwordStdin {
+			form.errs.add("auth", "改为密码认证需要通过 --password-stdin 提供密码")
+		}
+	}
+	form.errs = append(form.errs, validateHost(form.host, c.config, original.Name)...)
+	if err := form.errs.err(); err != nil {
+		return err
+	}
+
+	if err := c.readFormPassword(form); err != nil {
+		return err
+	}
+	if form.host.Name != original.Name {
+		c.config.HostRenamed(original.Name, form.host.Name)
+	}
+	c.config.Groups[groupIndex].Hosts[hostIndex] = form.host
+	// 换分组与 group move-host 相同：保持生效的跳板机，继承新分组的默认值
+	if form.group != groupName {
+		if c.config.GroupIndex(form.group) < 0 {
+			if err := c.config.AddGroup(form.group, nil); err != nil {
+				return usageError("%v", err)
+			}
+		}
+		if err := c.config.MoveHost(form.host.Name, form.group); err != nil {
+			return err
+		}
+	}
+
+	if err := config.SaveConfig(c.config.Path(), c.config); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+	if form.host.PasswordRef != original.PasswordRef {
+		c.removeVaultEntries(original.PasswordRef)
+	}
+	fmt.Printf("✅ 主机 '%s' 已更新\n", form.host.Name)
+	return nil
+}
+
+// 从标准输入读取密码（第一行）
+func (c *CLI) readFormPassword(form *hostForm) error {
+	if !form.passwordStdin {
+		return nil
+	}
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	password := strings.TrimRight(input, "\r\n")
+	if password == "" {
+		if err != nil {
+			return fmt.Errorf("读取密码失败: %v", err)
+		}
+		return withExitCode(ExitUsage, fieldErrors{{Field: "password", Message: "不能为空"}})
+	}
+	form.host.AuthType = "password"
+	form.host.KeyPath = ""
+	return storeHostPassword(&form.host, password)
+}
+
+// 按名称查找分组，不存在时返回空分组（只继承全局默认值）
+func (c *CLI) findGroup(name string) models.Group {
+	for _, group := range c.config.Groups {
+		if group.Name == name {
+			return group
+		}
+	}
+	return models.Group{Name: name}
+}
+
+// 添加主机到指定分组，分组不存在时创建
+func (c *CLI) insertHost(groupName string, host models.Host) {
+	for i := range c.config.Groups {
+		if c.config.Groups[i].Name == groupName {
+			c.config.Groups[i].Hosts = append(c.config.Groups[i].Hosts, host)
+			return
+		}
+	}
+	c.config.Groups = append(c.config.Groups, models.Group{Name: groupName, Hosts: []models.Host{host}})
+}
+
+// 逗号分隔的列表，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// 解析布尔值，支持 true/false/yes/no/1/0
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "1":
+		return true, nil
+	case "false", "no", "n", "0", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("无效的布尔值: %s", value)
+}
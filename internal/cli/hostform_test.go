@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daihao4371/hostmanager/internal/config"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 测试 add-host 参数解析
+func TestParseAddHostFlags(t *testing.T) {
+	form := parseAddHostFlags([]string{"--name", "web3", "--ip=10.0.0.13", "--user", "deploy", "--port", "2222",
+		"--tag", "web", "--tag", "prod,blue", "--fav", "--group", "生产"})
+	if len(form.errs) != 0 {
+		t.Fatalf("不应有错误: %v", form.errs)
+	}
+	h := form.host
+	if h.Name != "web3" || h.IP != "10.0.0.13" || h.Username != "deploy" || h.Port != 2222 || !h.Favorite {
+		t.Errorf("解析结果错误: %+v", h)
+	}
+	if len(h.Tags) != 3 || form.group != "生产" {
+		t.Errorf("标签或分组错误: %v %s", h.Tags, form.group)
+	}
+	// 未指定的字段留空，继承分组和全局默认值
+	if h.AuthType != "" || h.KeyPath != "" {
+		t.Errorf("未指定的字段应留空: %s %s", h.AuthType, h.KeyPath)
+	}
+
+	form = parseAddHostFlags([]string{"--port", "abc", "--color", "red", "--name"})
+	fields := map[string]bool{}
+	for _, fe := range form.errs {
+		fields[fe.Field] = true
+	}
+	if !fields["port"] || !fields["color"] || !fields["name"] {
+		t.Errorf("应按字段报告错误: %v", form.errs)
+	}
+}
+
+// 测试主机校验
+func TestValidateHost(t *testing.T) {
+	cfg := &config.Config{Groups: []models.Group{{Name: "生产", Hosts: []models.Host{
+		{Name: "bastion", IP: "10.0.0.1", Port: 22, Username: "ops", AuthType: "key", KeyPath: "~/.ssh/id_rsa"},
+	}}}}
+
+	valid := models.Host{Name: "web", IP: "web.example.com", Port: 22, Username: "app", AuthType: "key", KeyPath: "k", Jump: models.JumpChain{"bastion"}}
+	if errs := validateHost(valid, cfg, ""); len(errs) != 0 {
+		t.Errorf("合法主机不应报错: %v", errs)
+	}
+
+	invalid := models.Host{Name: "Bastion", IP: "bad_host!", Port: 0, AuthType: "token", Tags: []string{"a b"}, Jump: models.JumpChain{"missing"}}
+	errs := validateHost(invalid, cfg, "")
+	fields := map[string]bool{}
+	for _, fe := range errs {
+		fields[fe.Field] = true
+	}
+	for _, field := range []string{"name", "ip", "port", "user", "auth", "tags", "jump"} {
+		if !fields[field] {
+			t.Errorf("缺少字段 %s 的错误: %v", field, errs)
+		}
+	}
+
+	// 主机名允许下划线
+	valid.IP = "web_01.corp"
+	if errs := validateHost(valid, cfg, ""); len(errs) != 0 {
+		t.Errorf("带下划线的主机名不应报错: %v", errs)
+	}
+
+	// 编辑时保留原名称不算重复
+	if errs := validateHost(cfg.Groups[0].Hosts[0], cfg, "bastion"); len(errs) != 0 {
+		t.Errorf("编辑原主机不应报错: %v", errs)
+	}
+}
+
+// 测试未保存密码的密码认证主机：添加时可以不提供密码，编辑其他字段时不要求密码
+func TestPasswordOptional(t *testing.T) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("groups:\n- name: 生产\n  hosts: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCLI(cfg)
+	if err := c.addHostFromFlags([]string{"--name", "db", "--ip", "10.0.0.2", "--user", "dba", "--auth", "password"}); err != nil {
+		t.Fatalf("添加时密码应为可选: %v", err)
+	}
+	if err := c.editHostFromFlags("db", []string{"--set", "port=2222"}); err != nil {
+		t.Errorf("编辑其他字段不应要求密码: %v", err)
+	}
+
+	if err := c.addHostFromFlags([]string{"--name", "web", "--ip", "10.0.0.3", "--user", "app", "--auth", "key", "--key", "~/.ssh/id"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.editHostFromFlags("web", []string{"--set", "auth=password"}); ExitCode(err) != ExitUsage {
+		t.Errorf("改为密码认证且未提供密码时应返回参数错误: %v", err)
+	}
+}
+
+// 测试 --set group= 换分组时保持生效的跳板机
+func TestEditMoveGroup(t *testing.T) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `groups:
+- name: 生产
+  jump: bastion
+  hosts:
+  - name: bastion
+    ip: 10.0.0.1
+    jump: none
+  - name: web
+    ip: 10.0.0.2
+- name: 直连
+  hosts: []
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCLI(cfg)
+	if err := c.editHostFromFlags("web", []string{"--set", "group=直连"}); err != nil {
+		t.Fatalf("编辑失败: %v", err)
+	}
+	if err := c.editHostFromFlags("bastion", []string{"--set", "group=新分组"}); err != nil {
+		t.Fatalf("编辑失败: %v", err)
+	}
+
+	reloaded, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := reloaded.Groups[1].Hosts[0]
+	if web.Name != "web" || web.ViaDescription() != "bastion" {
+		t.Errorf("换分组后应保持原来的跳板机: %+v", web)
+	}
+	if len(reloaded.Groups) != 3 || reloaded.Groups[2].Hosts[0].Name != "bastion" {
+		t.Errorf("目标分组不存在时应创建: %+v", reloaded.Groups)
+	}
+}
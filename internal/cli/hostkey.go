@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/daihao4371/hostmanager/internal/hostkey"
+	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/ssh"
+)
+
+// 处理 hostkey 命令：获取、查看和删除主机密钥记录
+func (c *CLI) handleHostkey(args []string) error {
+	if len(args) == 0 || args[0] == "--help" || args[0] == "-h" {
+		c.showHostkeyHelp()
+		return nil
+	}
+
+	yes := false
+	var run func(*models.Host) error
+	switch args[0] {
+	case "scan":
+		run = func(host *models.Host) error { return c.scanHostkey(host, yes) }
+	case "show":
+		run = c.showHostkey
+	case "forget", "rm":
+		run = c.forgetHostkey
+	default:
+		c.showHostkeyHelp()
+		return usageError("未知的 hostkey 子命令: %s", args[0])
+	}
+
+	var hosts []*models.Host
+	for _, arg := range args[1:] {
+		switch {
+		case arg == "--yes" || arg == "-y":
+			yes = true
+		case strings.HasPrefix(arg, "-"):
+			return usageError("未知参数: %s", arg)
+		default:
+			host := c.findHostByName(arg)
+			if host == nil {
+				return notFoundError(arg)
+			}
+			hosts = append(hosts, host)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return usageError("请指定主机名称")
+	}
+
+	failed := 0
+	for _, host := range hosts {
+		if err := run(host); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 台主机处理失败", failed)
+	}
+	return nil
+}
+
+// 获取主机密钥，首次获取时确认指纹后记录
+func (c *CLI) scanHostkey(host *models.Host, yes bool) error {
+	key, err := ssh.ScanHostKey(*host)
+	if err != nil {
+		return err
+	}
+	address := hostkey.Address(host.IP, host.Port)
+	fingerprint := hostkey.Fingerprint(key)
+
+	err = hostkey.Check(address, key)
+	var changed *hostkey.ChangedError
+	var unknown *hostkey.UnknownError
+	switch {
+	case err == nil:
+		fmt.Printf("✅ %s (%s) 的主机密钥与记录一致: %s %s\n", host.Name, address, key.Type(), fingerprint)
+		return nil
+	case errors.As(err, &changed):
+		fmt.Fprint(os.Stderr, changed.Warning(host.Name))
+		return err
+	case !errors.As(err, &unknown):
+		return err
+	}
+
+	fmt.Printf("🔐 %s (%s) 的 %s 密钥指纹: %s\n", host.Name, address, key.Type(), fingerprint)
+	if !yes {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return usageError("标准输入不是终端，请核对指纹后使用 --yes 记录")
+		}
+		fmt.Printf("请与主机管理员提供的指纹核对，是否信任该主机? (yes/no): ")
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input != "y" && input != "yes" {
+			fmt.Printf("操作已取消\n")
+			return nil
+		}
+	}
+	if err := hostkey.Add(address, key); err != nil {
+		return fmt.Errorf("记录主机密钥失败: %v", err)
+	}
+	fmt.Printf("✅ 已记录到 %s\n", hostkey.DefaultPath())
+	return nil
+}
+
+// 显示主机已记录的密钥
+func (c *CLI) showHostkey(host *models.Host) error {
+	address := hostkey.Address(host.IP, host.Port)
+	known, err := hostkey.Known(address)
+	if err != nil {
+		return err
+	}
+	if len(known) == 0 {
+		fmt.Printf("ℹ️  尚未记录 %s (%s) 的主机密钥，可运行 hostmanager hostkey scan %s\n", host.Name, address, host.Name)
+		return nil
+	}
+	fmt.Printf("🔑 %s (%s):\n", host.Name, address)
+	for _, entry := range known {
+		fmt.Printf("   %-20s %s  (%s:%d)\n", entry.Key.Type(), hostkey.Fingerprint(entry.Key), entry.File, entry.Line)
+	}
+	return nil
+}
+
+// 删除 hostmanager 管理的 known_hosts 中的主机记录
+func (c *CLI) forgetHostkey(host *models.Host) error {
+	address := hostkey.Address(host.IP, host.Port)
+	removed, err := hostkey.Forget(address)
+	if err != nil {
+		return fmt.Errorf("删除主机密钥记录失败: %v", err)
+	}
+	if removed > 0 {
+		fmt.Printf("🗑️  已删除 %s (%s) 的 %d 条主机密钥记录\n", host.Name, address, removed)
+	} else {
+		fmt.Printf("ℹ️  %s 中没有 %s (%s) 的记录\n", hostkey.DefaultPath(), host.Name, address)
+	}
+
+	// ~/.ssh/known_hosts 由 ssh 管理，只提示不修改
+	if known, err := hostkey.Known(address); err == nil && len(known) > 0 {
+		fmt.Printf("⚠️  %s 中仍有该主机的记录，如需删除请运行: ssh-keygen -R '%s'\n", hostkey.UserPath(), address)
+	}
+	return nil
+}
+
+// 显示 hostkey 命令帮助
+func (c *CLI) showHostkeyHelp() {
+	fmt.Printf("🔑 主机密钥管理用法:\n")
+	fmt.Printf("   hostmanager hostkey scan <主机>... [--yes]   获取主机密钥，核对指纹后记录\n")
+	fmt.Printf("   hostmanager hostkey show <主机>...           显示已记录的密钥指纹\n")
+	fmt.Printf("   hostmanager hostkey forget <主机>...         删除记录（主机更换密钥后使用）\n\n")
+	fmt.Printf("连接时会校验 ~/.ssh/known_hosts 和 %s 中的记录：\n", hostkey.DefaultPath())
+	fmt.Printf("首次连接时显示指纹请你确认，密钥改变时拒绝连接。新记录只写入 hostmanager 的文件。\n")
+	fmt.Printf("exec、后台隧道等非交互场景不会提示，请先用 hostkey scan 记录主机密钥。\n\n")
+	fmt.Printf("参数:\n")
+	fmt.Printf("   -y, --yes    不确认直接记录（仅在已通过其他途径核对指纹时使用）\n")
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/daihao4371/hostmanager/internal/config"
+	"github.com/daihao4371/hostmanager/internal/hostkey"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 测试 hostkey 命令的参数检查和删除记录
+func TestHandleHostkey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOSTMANAGER_KNOWN_HOSTS", filepath.Join(t.TempDir(), "known_hosts"))
+	c := NewCLI(&config.Config{Groups: []models.Group{{
+		Name:  "web",
+		Hosts: []models.Host{{Name: "web1", IP: "10.0.0.1", Port: 2222, Username: "root"}},
+	}}})
+
+	if ExitCode(c.handleHostkey([]string{"bogus", "web1"})) != ExitUsage {
+		t.Error("未知子命令应返回参数错误")
+	}
+	if ExitCode(c.handleHostkey([]string{"show"})) != ExitUsage {
+		t.Error("缺少主机应返回参数错误")
+	}
+	if ExitCode(c.handleHostkey([]string{"show", "nope"})) != ExitNotFound {
+		t.Error("未知主机应返回未找到")
+	}
+
+	public, _, _ := ed25519.GenerateKey(rand.Reader)
+	key, _ := ssh.NewPublicKey(public)
+	address := hostkey.Address("10.0.0.1", 2222)
+	if err := hostkey.Add(address, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.handleHostkey([]string{"show", "web1"}); err != nil {
+		t.Errorf("show 失败: %v", err)
+	}
+	if err := c.handleHostkey([]string{"forget", "web1"}); err != nil {
+		t.Errorf("forget 失败: %v", err)
+	}
+	if known, _ := hostkey.Known(address); len(known) != 0 {
+		t.Errorf("forget 后不应再有记录: %+v", known)
+	}
+}
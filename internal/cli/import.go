@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/daihao4371/hostmanager/internal/config"
+	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/sshconfig"
+)
+
+// 导入计划中的一项
+type importItem struct {
+	host      models.Host
+	alias     string // ssh_config 中的原始别名
+	duplicate string // 重复时为已有主机名称
+	renamed   bool   // 名称冲突被重命名
+}
+
+// 处理导入命令
+func (c *CLI) handleImport(args []string) error {
+	if len(args) == 0 || args[0] != "ssh-config" {
+		c.showImportHelp()
+		return nil
+	}
+
+	path := "~/.ssh/config"
+	groupName := "SSH Config"
+	dryRun := false
+
+	rest := args[1:]
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case "--group", "-g":
+			if i+1 >= len(rest) {
+				return usageError("--group 需要指定分组名称")
+			}
+			i++
+			groupName = rest[i]
+		case "--dry-run", "-n":
+			dryRun = true
+		default:
+			path = rest[i]
+		}
+	}
+
+	sshCfg, err := sshconfig.ParseFile(path)
+	if err != nil {
+		return fmt.Errorf("读取 %s 失败: %v", path, err)
+	}
+
+	items := c.planImport(sshCfg.Entries())
+	added := c.printImportPlan(path, groupName, items)
+
+	if dryRun {
+		fmt.Printf("\n(dry-run) 未修改配置\n")
+		return nil
+	}
+	if added == 0 {
+		return nil
+	}
+
+	return c.applyImport(groupName, items)
+}
+
+// 生成导入计划：检测重复（IP/端口/用户名相同）和名称冲突
+func (c *CLI) planImport(entries []sshconfig.Entry) []importItem {
+	var items []importItem
+	names := map[string]string{} // 别名 -> 最终主机名称
+
+	for _, entry := range entries {
+		item := importItem{host: entryToHost(entry), alias: entry.Alias}
+
+		if existing := c.findHostByEndpoint(item.host); existing != nil {
+			item.duplicate = existing.Name
+		} else if dup := findPlannedEndpoint(items, item.host); dup != "" {
+			item.duplicate = dup
+		} else if c.findHostByName(item.host.Name) != nil {
+			item.host.Name = item.host.Name + " (ssh-config)"
+			item.renamed = true
+		}
+
+		names[entry.Alias] = item.host.Name
+		if item.duplicate != "" {
+			names[entry.Alias] = item.duplicate
+		}
+		items = append(items, item)
+	}
+
+	// 跳板机引用 ssh_config 别名时改为最终的主机名称
+	for i := range items {
+		for j, hop := range items[i].host.Jump {
+			if name, ok := names[hop]; ok {
+				items[i].host.Jump[j] = name
+			}
+		}
+	}
+
+	// 跳板机既不是导入的别名也不是已有主机时，按 user@host:port 新建主机
+	for i := range items {
+		if items[i].duplicate != "" {
+			continue
+		}
+		for _, hop := range items[i].host.Jump {
+			if _, ok := names[hop]; ok || strings.EqualFold(hop, "none") || c.findHostByName(hop) != nil {
+				continue
+			}
+			names[hop] = hop
+			items = append(items, importItem{host: jumpSpecToHost(hop), alias: hop})
+		}
+	}
+	return items
+}
+
+// 打印导入差异，返回新增主机数量
+func (c *CLI) printImportPlan(path, groupName string, items []importItem) int {
+	fmt.Printf("📥 从 %s 导入到分组 '%s':\n", path, groupName)
+
+	added, duplicates := 0, 0
+	for _, item := range items {
+		host := item.host
+		switch {
+		case item.duplicate != "":
+			fmt.Printf("  = %s 与已有主机 '%s' 重复，跳过\n", item.alias, item.duplicate)
+			duplicates++
+			continue
+		case item.renamed:
+			fmt.Printf("  ~ %s 名称已存在，重命名为 '%s'\n", item.alias, host.Name)
+		}
+
+		line := fmt.Sprintf("  + %s (%s@%s:%d)", host.Name, host.Username, host.IP, host.Port)
+		if len(host.Jump) > 0 {
+			line += fmt.Sprintf(" 🛡️ %s", strings.Join(host.Jump, " → "))
+		}
+		fmt.Println(line)
+		added++
+	}
+
+	fmt.Printf("\n共 %d 个新主机，%d 个重复\n", added, duplicates)
+	return added
+}
+
+// 把导入计划写入配置
+func (c *CLI) applyImport(groupName string, items []importItem) error {
+	groupIndex := -1
+	for i, group := range c.config.Groups {
+		if group.Name == groupName {
+			groupIndex = i
+			break
+		}
+	}
+	if groupIndex == -1 {
+		c.config.Groups = append(c.config.Groups, models.Group{Name: groupName})
+		groupIndex = len(c.config.Groups) - 1
+	}
+
+	for _, item := range items {
+		if item.duplicate == "" {
+			c.config.Groups[groupIndex].Hosts = append(c.config.Groups[groupIndex].Hosts, item.host)
+		}
+	}
+
+	// 校验跳板机引用
+	if err := c.config.ResolveJumps(); err != nil {
+		return fmt.Errorf("导入的跳板机配置无效: %v", err)
+	}
+
+	err := config.SaveConfig(c.config.Path(), c.config)
+	if err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+
+	fmt.Printf("✅ 导入完成\n")
+	return nil
+}
+
+// 按 IP/端口/用户名查找已有主机
+func (c *CLI) findHostByEndpoint(target models.Host) *models.Host {
+	for _, group := range c.config.Groups {
+		for _, host := range group.Hosts {
+			if host.SameEndpoint(target) {
+				return &host
+			}
+		}
+	}
+	return nil
+}
+
+// 在本次导入计划中查找相同端点
+func findPlannedEndpoint(items []importItem, target models.Host) string {
+	for _, item := range items {
+		if item.duplicate == "" && item.host.SameEndpoint(target) {
+			return item.host.Name
+		}
+	}
+	return ""
+}
+
+// ssh_config 条目转换为主机配置
+func entryToHost(entry sshconfig.Entry) models.Host {
+	host := models.Host{
+		Name:     entry.Alias,
+		IP:       entry.HostName,
+		Port:     entry.Port,
+		Username: entry.User,
+		AuthType: "key",
+		KeyPath:  entry.IdentityFile,
+		Jump:     models.JumpChain(entry.ProxyJump),
+	}
+	if host.Port == 0 {
+		host.Port = 22
+	}
+	if host.Username == "" {
+		host.Username = config.CurrentUsername()
+	}
+	return host
+}
+
+// 解析 ProxyJump 中的 [user@]host[:port]
+func jumpSpecToHost(spec string) models.Host {
+	host := models.Host{Name: spec, Port: 22, AuthType: "key", Username: config.CurrentUsername()}
+
+	address := spec
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		host.Username = address[:at]
+		address = address[at+1:]
+	}
+	if colon := strings.LastIndex(address, ":"); colon >= 0 && !strings.Contains(address[colon+1:], "]") {
+		if port, err := strconv.Atoi(address[colon+1:]); err == nil {
+			host.Port = port
+			address = address[:colon]
+		}
+	}
+	host.IP = strings.Trim(address, "[]")
+	return host
+}
+
+// 显示导入命令帮助
+func (c *CLI) showImportHelp() {
+	fmt.Printf("📥 导入命令用法:\n")
+	fmt.Printf("   hostmanager import ssh-config [路径] [选项]\n\n")
+	fmt.Printf("选项:\n")
+	fmt.Printf("   --group, -g <分组>   导入到指定分组 (默认: SSH Config)\n")
+	fmt.Printf("   --dry-run, -n       只显示将要导入的主机，不修改配置\n\n")
+	fmt.Printf("示例:\n")
+	fmt.Printf("   hostmanager import ssh-config --dry-run\n")
+	fmt.Printf("   hostmanager import ssh-config ~/.ssh/config.d/work --group 工作\n")
+}
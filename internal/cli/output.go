@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/daihao4371/hostmanager/internal/export"
+	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/output"
+)
+
+// 主机状态（status 命令的结构化输出）
+type statusRecord struct {
+	export.HostRecord `yaml:",inline"`
+	Status            string `json:"status" yaml:"status"` // online / offline / unknown
+}
+
+// 分组（groups 命令的结构化输出）
+type groupRecord struct {
+	Name  string              `json:"name" yaml:"name"`
+	Jump  []string            `json:"jump,omitempty" yaml:"jump,omitempty"`
+	Hosts []export.HostRecord `json:"hosts" yaml:"hosts"`
+}
+
+// 从参数中取出全局的 --output/-o（-- 之后的参数原样保留）
+func (c *CLI) parseOutputFlag(args []string) ([]string, error) {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		value, ok := strings.CutPrefix(arg, "--output=")
+		if !ok && (arg == "--output" || arg == "-o") {
+			if i+1 >= len(args) {
+				return nil, usageError("%s 需要指定格式 (%s)", arg, strings.Join(output.Formats, ", "))
+			}
+			i++
+			value, ok = args[i], true
+		}
+		if !ok {
+			rest = append(rest, arg)
+			continue
+		}
+
+		format, err := output.ParseFormat(value)
+		if err != nil {
+			return nil, withExitCode(ExitUsage, err)
+		}
+		c.output = format
+	}
+	return rest, nil
+}
+
+// 从参数中取出全局的 --config（-- 之后的参数原样保留）
+func ParseConfigFlag(args []string) (string, []string, error) {
+	var path string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--config="); ok {
+			path = value
+			continue
+		}
+		if arg == "--config" {
+			if i+1 >= len(args) {
+				return "", nil, usageError("--config 需要指定配置文件路径")
+			}
+			i++
+			path = args[i]
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return path, rest, nil
+}
+
+// 不依赖已有配置文件的命令
+func WorksWithoutConfig(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "init", "config", "completion", "help", "--help", "-h", "version", "--version", "-v":
+		return true
+	case "tunnel":
+		// 管理后台隧道不需要读取主机配置
+		return len(args) > 1 && (args[1] == "ls" || args[1] == "list" || args[1] == "stop" || args[1] == "logs")
+	}
+	return false
+}
+
+// 输出主机列表
+func (c *CLI) writeHosts(records []export.HostRecord) error {
+	rows := output.Rows{Header: hostHeader}
+	for _, r := range records {
+		rows.Rows = append(rows.Rows, hostRow(r))
+	}
+	return output.Write(os.Stdout, c.output, records, rows)
+}
+
+// 输出主机状态
+func (c *CLI) writeStatuses(groups []models.Group) error {
+	records := []statusRecord{}
+	rows := output.Rows{Header: append(append([]string{}, hostHeader...), "status")}
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			record := statusRecord{HostRecord: export.NewHostRecord(group.Name, host), Status: statusName(host.Status)}
+			records = append(records, record)
+			rows.Rows = append(rows.Rows, append(hostRow(record.HostRecord), record.Status))
+		}
+	}
+	return output.Write(os.Stdout, c.output, records, rows)
+}
+
+// 输出分组
+func (c *CLI) writeGroups(groups []models.Group) error {
+	records := []groupRecord{}
+	rows := output.Rows{Header: []string{"name", "hosts", "jump"}}
+	for _, group := range groups {
+		record := groupRecord{Name: group.Name, Jump: group.Jump, Hosts: hostRecords([]models.Group{group})}
+		records = append(records, record)
+		rows.Rows = append(rows.Rows, []string{group.Name, strconv.Itoa(len(group.Hosts)), strings.Join(group.Jump, ",")})
+	}
+	return output.Write(os.Stdout, c.output, records, rows)
+}
+
+// 主机表格的列（与 JSON 字段名一致）
+var hostHeader = []string{"group", "name", "ip", "port", "username", "auth_type", "tags", "favorite", "description", "jump"}
+
+func hostRow(r export.HostRecord) []string {
+	return []string{
+		r.Group, r.Name, r.IP, strconv.Itoa(r.Port), r.Username, r.AuthType,
+		strings.Join(r.Tags, ","), strconv.FormatBool(r.Favorite), r.Description, strings.Join(r.Jump, ","),
+	}
+}
+
+// 分组中所有主机的记录
+func hostRecords(groups []models.Group) []export.HostRecord {
+	records := []export.HostRecord{}
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			records = append(records, export.NewHostRecord(group.Name, host))
+		}
+	}
+	return records
+}
+
+// 状态的稳定名称
+func statusName(status string) string {
+	if status == "" {
+		return "unknown"
+	}
+	return status
+}
+
+// 只保留收藏的主机
+func favoriteGroups(groups []models.Group) []models.Group {
+	result := []models.Group{}
+	for _, group := range groups {
+		filtered := group
+		filtered.Hosts = nil
+		for _, host := range group.Hosts {
+			if host.Favorite {
+				filtered.Hosts = append(filtered.Hosts, host)
+			}
+		}
+		if len(filtered.Hosts) > 0 {
+			result = append(result, filtered)
+		}
+	}
+	return result
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/daihao4371/hostmanager/internal/output"
+)
+
+// 测试全局 --output 参数解析
+func TestParseOutputFlag(t *testing.T) {
+	c := &CLI{}
+	rest, err := c.parseOutputFlag([]string{"list", "-o", "json", "tag:prod"})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if c.output != output.JSON || len(rest) != 2 || rest[1] != "tag:prod" {
+		t.Errorf("解析结果错误: %v %v", c.output, rest)
+	}
+
+	// -- 之后的参数属于远程命令，不应被解析
+	c = &CLI{}
+	rest, err = c.parseOutputFlag([]string{"exec", "--output=tsv", "--", "ls", "-o", "x"})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if c.output != output.TSV || len(rest) != 5 {
+		t.Errorf("解析结果错误: %v %v", c.output, rest)
+	}
+
+	if _, err := c.parseOutputFlag([]string{"list", "-o", "xml"}); ExitCode(err) != ExitUsage {
+		t.Errorf("未知格式应返回参数错误, 得到 %v", err)
+	}
+	if _, err := c.parseOutputFlag([]string{"list", "--output"}); ExitCode(err) != ExitUsage {
+		t.Errorf("缺少格式应返回参数错误, 得到 %v", err)
+	}
+}
+
+// 测试退出码
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected int
+	}{
+		{nil, ExitOK},
+		{errors.New("失败"), ExitFailure},
+		{usageError("参数错误"), ExitUsage},
+		{notFoundError("web"), ExitNotFound},
+		{fmt.Errorf("包装: %w", withExitCode(ExitOffline, errors.New("离线"))), ExitOffline},
+	}
+	for _, tc := range cases {
+		if got := ExitCode(tc.err); got != tc.expected {
+			t.Errorf("ExitCode(%v) = %d, 期望 %d", tc.err, got, tc.expected)
+		}
+	}
+}
+
+// 测试全局 --config 参数解析
+func TestParseConfigFlag(t *testing.T) {
+	path, rest, err := ParseConfigFlag([]string{"--config", "/tmp/a.yaml", "list", "--config=/tmp/b.yaml"})
+	if err != nil || path != "/tmp/b.yaml" || len(rest) != 1 || rest[0] != "list" {
+		t.Errorf("解析结果错误: %s %v %v", path, rest, err)
+	}
+	if _, rest, _ := ParseConfigFlag([]string{"exec", "--", "cmd", "--config", "x"}); len(rest) != 5 {
+		t.Errorf("-- 之后的参数不应被解析: %v", rest)
+	}
+	if _, _, err := ParseConfigFlag([]string{"--config"}); ExitCode(err) != ExitUsage {
+		t.Errorf("缺少路径应返回参数错误, 得到 %v", err)
+	}
+}
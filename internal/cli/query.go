@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"sync"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/query"
+	"github.com/daihao4371/hostmanager/internal/ssh"
+)
+
+// 状态检查的并发数
+const statusCheckParallel = 20
+
+// 按查询语句筛选主机，包含 status: 条件时先检查所有主机状态
+func (c *CLI) queryGroups(expr string) ([]models.Group, error) {
+	q, err := query.Parse(expr)
+	if err != nil {
+		return nil, withExitCode(ExitUsage, err)
+	}
+	if q.IsEmpty() {
+		return c.config.Groups, nil
+	}
+
+	groups := c.config.Groups
+	if q.NeedsStatus() {
+		groups = checkStatuses(groups)
+	}
+	return q.FilterGroups(groups), nil
+}
+
+// 并发检查所有主机状态，返回带状态的分组副本
+func checkStatuses(groups []models.Group) []models.Group {
+	result := make([]models.Group, len(groups))
+	semaphore := make(chan struct{}, statusCheckParallel)
+	var wg sync.WaitGroup
+
+	for i, group := range groups {
+		result[i] = group
+		result[i].Hosts = append([]models.Host(nil), group.Hosts...)
+		for j := range result[i].Hosts {
+			wg.Add(1)
+			go func(host *models.Host) {
+				defer wg.Done()
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+				host.Status = ssh.CheckHostStatus(*host)
+			}(&result[i].Hosts[j])
+		}
+	}
+
+	wg.Wait()
+	return result
+}
+
+// 是否所有主机都已检查过状态
+func statusesChecked(groups []models.Group) bool {
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			if host.Status == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
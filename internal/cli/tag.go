@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/daihao4371/hostmanager/internal/config"
+	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/output"
+	"github.com/daihao4371/hostmanager/internal/query"
+)
+
+// 标签（tag ls 的结构化输出）
+type tagRecord struct {
+	Tag   string   `json:"tag" yaml:"tag"`
+	Count int      `json:"count" yaml:"count"`
+	Hosts []string `json:"hosts" yaml:"hosts"`
+}
+
+// 标签操作的目标主机
+type tagTargets struct {
+	hosts   []string
+	queries []string
+	all     bool
+	yes     bool
+}
+
+// 处理标签管理命令
+func (c *CLI) handleTag(args []string) error {
+	if len(args) == 0 {
+		c.showTagHelp()
+		return nil
+	}
+
+	switch args[0] {
+	case "ls", "list":
+		return c.tagList(args[1:])
+	case "add":
+		return c.tagModify(args[1:], true)
+	case "rm", "remove":
+		return c.tagModify(args[1:], false)
+	case "rename":
+		return c.tagRename(args[1:])
+	default:
+		return usageError("未知的 tag 子命令: %s", args[0])
+	}
+}
+
+// 列出所有标签及主机数量，可按条件筛选主机
+func (c *CLI) tagList(args []string) error {
+	groups, err := c.queryGroups(query.Join(args))
+	if err != nil {
+		return err
+	}
+
+	records := []tagRecord{}
+	rows := output.Rows{Header: []string{"tag", "count", "hosts"}}
+	for _, group := range models.GroupByTag(groups) {
+		record := tagRecord{Tag: group.Name, Count: len(group.Hosts)}
+		for _, host := range group.Hosts {
+			record.Hosts = append(record.Hosts, host.Name)
+		}
+		records = append(records, record)
+		rows.Rows = append(rows.Rows, []string{record.Tag, strconv.Itoa(record.Count), strings.Join(record.Hosts, ",")})
+	}
+	if c.output.Structured() {
+		return output.Write(os.Stdout, c.output, records, rows)
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("📭 没有找到标签\n")
+		return nil
+	}
+	fmt.Printf("🏷️  标签列表:\n\n")
+	for _, record := range records {
+		fmt.Printf("  #%s (%d台主机): %s\n", record.Tag, record.Count, strings.Join(record.Hosts, ", "))
+	}
+	return nil
+}
+
+// 为主机添加或删除标签：tag add|rm <标签[,标签]> [主机...] [--query 条件] [--all]
+func (c *CLI) tagModify(args []string, add bool) error {
+	action := "add"
+	if !add {
+		action = "rm"
+	}
+	if len(args) == 0 {
+		return usageError("用法: hostmanager tag %s <标签[,标签]> [主机...] [--query 条件] [--all]", action)
+	}
+	tags := splitList(args[0])
+	if len(tags) == 0 {
+		return usageError("请指定标签")
+	}
+	for _, tag := range tags {
+		if strings.ContainsAny(tag, " \t") {
+			return usageError("标签不能包含空白: %q", tag)
+		}
+	}
+	targets, err := parseTagTargets(args[1:])
+	if err != nil {
+		return err
+	}
+
+	names, err := c.selectTagHosts(targets)
+	if err != nil {
+		return err
+	}
+	if len(names) > 1 && len(targets.hosts) == 0 && !targets.yes &&
+		!confirm(fmt.Sprintf("⚠️  将修改 %d 台主机的标签，确认? (y/N): ", len(names))) {
+		fmt.Printf("操作已取消\n")
+		return nil
+	}
+
+	changed := c.updateHosts(names, func(host *models.Host) bool {
+		if add {
+			return host.AddTags(tags...)
+		}
+		return host.RemoveTags(tags...)
+	})
+	if changed == 0 {
+		fmt.Printf("ℹ️  没有主机需要修改\n")
+		return nil
+	}
+	if err := config.SaveConfig(c.config.Path(), c.config); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+	if add {
+		fmt.Printf("✅ 已为 %d 台主机添加标签 %s\n", changed, strings.Join(tags, ","))
+	} else {
+		fmt.Printf("✅ 已从 %d 台主机删除标签 %s\n", changed, strings.Join(tags, ","))
+	}
+	return nil
+}
+
+// 重命名标签（作用于所有主机）
+func (c *CLI) tagRename(args []string) error {
+	if len(args) != 2 {
+		return usageError("用法: hostmanager tag rename <原标签> <新标签>")
+	}
+	oldTag, newTag := args[0], strings.TrimSpace(args[1])
+	if newTag == "" || strings.ContainsAny(newTag, " \t,") {
+		return usageError("无效的标签: %q", newTag)
+	}
+
+	changed := 0
+	for i := range c.config.Groups {
+		for j := range c.config.Groups[i].Hosts {
+			if c.config.Groups[i].Hosts[j].RenameTag(oldTag, newTag) {
+				changed++
+			}
+		}
+	}
+	if changed == 0 {
+		return withExitCode(ExitNotFound, fmt.Errorf("没有主机带有标签: %s", oldTag))
+	}
+	if err := config.SaveConfig(c.config.Path(), c.config); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+	fmt.Printf("✅ 标签 %s 已重命名为 %s (%d台主机)\n", oldTag, newTag, changed)
+	return nil
+}
+
+// 解析主机参数和筛选条件
+func parseTagTargets(args []string) (tagTargets, error) {
+	var targets tagTargets
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--query", "-q":
+			if i+1 >= len(args) {
+				return targets, usageError("%s 需要参数值", arg)
+			}
+			i++
+			targets.queries = append(targets.queries, args[i])
+		case "--all", "-a":
+			targets.all = true
+		case "--yes", "-y":
+			targets.yes = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return targets, usageError("未知参数: %s", arg)
+			}
+			targets.hosts = append(targets.hosts, arg)
+		}
+	}
+	if len(targets.hosts) == 0 && len(targets.queries) == 0 && !targets.all {
+		return targets, usageError("请指定主机、--query 或 --all")
+	}
+	return targets, nil
+}
+
+// 选择要修改的主机名称：指定的主机按名称精确匹配，再按筛选条件过滤
+func (c *CLI) selectTagHosts(targets tagTargets) ([]string, error) {
+	wanted := map[string]bool{}
+	for _, name := range targets.hosts {
+		if groupIndex, _ := c.findHostLocation(name); groupIndex == -1 {
+			return nil, notFoundError(name)
+		}
+		wanted[strings.ToLower(name)] = true
+	}
+
+	groups, err := c.queryGroups(strings.Join(targets.queries, " "))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			if len(targets.hosts) > 0 && !wanted[strings.ToLower(host.Name)] {
+				continue
+			}
+			names = append(names, host.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, withExitCode(ExitNotFound, fmt.Errorf("没有匹配的主机"))
+	}
+	return names, nil
+}
+
+// 按名称修改配置中的主机，返回实际发生变化的主机数
+func (c *CLI) updateHosts(names []string, update func(host *models.Host) bool) int {
+	selected := map[string]bool{}
+	for _, name := range names {
+		selected[strings.ToLower(name)] = true
+	}
+	changed := 0
+	for i := range c.config.Groups {
+		for j := range c.config.Groups[i].Hosts {
+			host := &c.config.Groups[i].Hosts[j]
+			if selected[strings.ToLower(host.Name)] && update(host) {
+				changed++
+			}
+		}
+	}
+	return changed
+}
+
+// 显示 tag 命令帮助
+func (c *CLI) showTagHelp() {
+	fmt.Printf("🏷️  标签管理用法:\n")
+	fmt.Printf("   hostmanager tag ls [条件]                         列出标签及主机\n")
+	fmt.Printf("   hostmanager tag add <标签[,标签]> <主机...>          为主机添加标签\n")
+	fmt.Printf("   hostmanager tag add <标签> --query <条件> [--yes]    为所有匹配的主机添加标签\n")
+	fmt.Printf("   hostmanager tag rm <标签[,标签]> <主机...|--query 条件|--all>  删除标签\n")
+	fmt.Printf("   hostmanager tag rename <原标签> <新标签>             重命名标签\n\n")
+	fmt.Printf("示例:\n")
+	fmt.Printf("   hostmanager tag add web --query \"group:生产环境 name:web*\"\n")
+	fmt.Printf("   hostmanager tag rm legacy --all --yes\n")
+}
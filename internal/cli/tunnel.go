@@ -0,0 +1,649 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/output"
+	"github.com/daihao4371/hostmanager/internal/ssh"
+	"github.com/daihao4371/hostmanager/internal/tunnel"
+)
+
+// 启动后台隧道后等待该时间，确认进程没有立即退出
+const tunnelStartupWait = time.Second
+
+// 停止后台隧道时等待进程退出的时间，超时后强制结束
+const tunnelStopTimeout = 5 * time.Second
+
+// tunnel logs 默认显示的行数
+const defaultTunnelLogLines = 50
+
+// tunnel 命令的参数
+type tunnelOptions struct {
+	host    string
+	names   []string // 只建立指定名称的转发
+	as      string   // 后台隧道名称（tunnel start --as）
+	managed string   // 作为后台隧道运行，由 tunnel start 启动时传入
+	help    bool
+}
+
+// 隧道（tunnel ls 的结构化输出）
+type tunnelRecord struct {
+	Name      string                `json:"name" yaml:"name"`
+	Host      string                `json:"host" yaml:"host"`
+	PID       int                   `json:"pid" yaml:"pid"`
+	Status    string                `json:"status" yaml:"status"` // connected / reconnecting / starting / exited
+	Restarts  int                   `json:"restarts" yaml:"restarts"`
+	Started   *time.Time            `json:"started,omitempty" yaml:"started,omitempty"`
+	Forwards  []tunnelForwardRecord `json:"forwards" yaml:"forwards"`
+	LogFile   string                `json:"log_file,omitempty" yaml:"log_file,omitempty"`
+	Unhealthy bool                  `json:"unhealthy" yaml:"unhealthy"`
+}
+
+type tunnelForwardRecord struct {
+	Spec   string `json:"spec" yaml:"spec"`
+	Active int64  `json:"active" yaml:"active"`
+	Total  int64  `json:"total" yaml:"total"`
+	Health string `json:"health,omitempty" yaml:"health,omitempty"`
+}
+
+// 处理 tunnel 命令：前台建立端口转发，或管理后台隧道（start/stop/ls/logs）
+func (c *CLI) handleTunnel(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "start":
+			return c.startTunnel(args[1:])
+		case "stop":
+			return c.stopTunnels(args[1:])
+		case "ls", "list":
+			return c.listTunnels(args[1:])
+		case "logs":
+			return c.tunnelLogs(args[1:])
+		}
+	}
+
+	opts, err := parseTunnelOptions(args)
+	if err != nil {
+		return err
+	}
+	if opts.help {
+		c.showTunnelHelp()
+		return nil
+	}
+	if opts.as != "" {
+		return usageError("--as 只能用于 tunnel start")
+	}
+	host, forwards, err := c.tunnelTarget(opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔀 建立到 %s (%s@%s:%d) 的隧道:\n", host.Name, host.Username, host.IP, host.Port)
+	if len(host.Via) > 0 {
+		fmt.Printf("🛡️  经由跳板机: %s\n", host.ViaDescription())
+	}
+	for _, forward := range forwards {
+		fmt.Printf("   %s\n", forward)
+	}
+	if opts.managed == "" {
+		fmt.Printf("💡 按 Ctrl+C 停止，连接断开后会自动重连\n\n")
+	}
+
+	return runTunnel(*host, opts.names, forwards, opts.managed)
+}
+
+// 解析主机名、--name 和 --as
+func parseTunnelOptions(args []string) (tunnelOptions, error) {
+	var opts tunnelOptions
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--help" || arg == "-h":
+			opts.help = true
+		case arg == "--name" || arg == "-n" || arg == "--as":
+			if i+1 >= len(args) {
+				return opts, usageError("%s 需要参数值", arg)
+			}
+			i++
+			if arg == "--as" {
+				opts.as = args[i]
+			} else {
+				opts.names = append(opts.names, splitList(args[i])...)
+			}
+		case strings.HasPrefix(arg, "--name="):
+			opts.names = append(opts.names, splitList(strings.TrimPrefix(arg, "--name="))...)
+		case strings.HasPrefix(arg, "--as="):
+			opts.as = strings.TrimPrefix(arg, "--as=")
+		case strings.HasPrefix(arg, "--managed="):
+			opts.managed = strings.TrimPrefix(arg, "--managed=")
+		case strings.HasPrefix(arg, "-"):
+			return opts, usageError("未知参数: %s", arg)
+		case opts.host != "":
+			return opts, usageError("一次只能为一台主机建立隧道")
+		default:
+			opts.host = arg
+		}
+	}
+	return opts, nil
+}
+
+// 查找主机并选出要建立的转发
+func (c *CLI) tunnelTarget(opts tunnelOptions) (*models.Host, []models.Forward, error) {
+	if opts.host == "" {
+		c.showTunnelHelp()
+		return nil, nil, usageError("请指定主机名称")
+	}
+	host := c.findHostByName(opts.host)
+	if host == nil {
+		return nil, nil, notFoundError(opts.host)
+	}
+	forwards, err := host.SelectForwards(opts.names)
+	if err != nil {
+		return nil, nil, usageError("%v", err)
+	}
+	if len(forwards) == 0 {
+		return nil, nil, usageError("主机 %s 未配置端口转发 (forwards)", host.Name)
+	}
+	for _, forward := range forwards {
+		if err := forward.Validate(); err != nil {
+			label := forward.Name
+			if label == "" {
+				label = forward.Listen
+			}
+			return nil, nil, usageError("端口转发 %s 配置无效: %v", label, err)
+		}
+	}
+	return host, forwards, nil
+}
+
+// 运行隧道：前台运行时在终端显示实时连接数，managed 非空时作为后台隧道运行
+func runTunnel(host models.Host, names []string, forwards []models.Forward, managed string) error {
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	t := ssh.NewTunnel(host, forwards)
+	live := managed == "" && term.IsTerminal(int(os.Stdout.Fd()))
+	if managed != "" {
+		t.Background()
+	}
+	var mu sync.Mutex
+	statusShown := false
+	timeFormat := "15:04:05"
+	if managed != "" {
+		timeFormat = "2006-01-02 15:04:05" // 写入日志文件，带上日期
+	}
+
+	// 状态变化单独成行，实时连接数在同一行刷新
+	notify := func(message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if statusShown {
+			fmt.Print("\r\033[K")
+			statusShown = false
+		}
+		fmt.Printf("[%s] %s\n", time.Now().Format(timeFormat), message)
+	}
+
+	state := tunnel.State{Name: managed, Host: host.Name, Names: names, PID: os.Getpid(), Started: time.Now()}
+	dir := tunnel.Dir()
+	defer tunnel.Remove(dir, state.PID)
+	if managed != "" {
+		// 正常退出时删除 pid 文件；被强制结束时保留，tunnel ls 显示为已退出
+		defer func() {
+			if pid, err := tunnel.ReadPID(dir, managed); err == nil && pid == state.PID {
+				tunnel.RemovePID(dir, managed)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			stats := t.Stats()
+			state.Connected = t.Connected()
+			state.Restarts = t.Reconnects()
+			state.Forwards = state.Forwards[:0]
+			for _, s := range stats {
+				state.Forwards = append(state.Forwards, tunnel.Forward{Spec: s.Forward.String(), Active: s.Active, Total: s.Total, Health: healthText(s)})
+			}
+			tunnel.Save(dir, state)
+
+			if live {
+				mu.Lock()
+				fmt.Printf("\r\033[K%s", tunnelStatusLine(state.Connected, stats))
+				statusShown = true
+				mu.Unlock()
+			}
+		}
+	}()
+
+	err := t.Run(stop, notify)
+	if live {
+		fmt.Println()
+	}
+	if err != nil {
+		return err
+	}
+	if managed != "" {
+		notify("隧道已停止")
+	} else {
+		fmt.Printf("👋 隧道已停止\n")
+	}
+	return nil
+}
+
+// 健康检查结果写入状态文件的文本
+func healthText(s ssh.ForwardStats) string {
+	switch {
+	case !s.Checked:
+		return ""
+	case s.Health != nil:
+		return s.Health.Error()
+	}
+	return tunnel.HealthOK
+}
+
+// 实时状态行，例如 "🟢 已连接 | pg: 2 个连接 (共 15) | D 127.0.0.1:1080: 0 个连接 (共 3)"
+func tunnelStatusLine(connected bool, stats []ssh.ForwardStats) string {
+	parts := []string{"🔴 重连中"}
+	if connected {
+		parts[0] = "🟢 已连接"
+	}
+	for _, s := range stats {
+		label := s.Forward.Name
+		if label == "" {
+			label = strings.TrimPrefix(s.Forward.Flag(), "-") + " " + s.Forward.ListenAddress()
+		}
+		part := fmt.Sprintf("%s: %d 个连接 (共 %d)", label, s.Active, s.Total)
+		if s.Health != nil {
+			part += " ⚠️"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// 在后台启动隧道：重新执行当前程序，输出写入日志文件
+func (c *CLI) startTunnel(args []string) error {
+	opts, err := parseTunnelOptions(args)
+	if err != nil {
+		return err
+	}
+	if opts.help {
+		c.showTunnelHelp()
+		return nil
+	}
+	if opts.managed != "" {
+		return usageError("未知参数: --managed")
+	}
+	host, forwards, err := c.tunnelTarget(opts)
+	if err != nil {
+		return err
+	}
+
+	name := opts.as
+	if name == "" {
+		name = tunnel.DefaultName(host.Name, opts.names)
+	}
+	if err := tunnel.CheckName(name); err != nil {
+		return usageError("%v", err)
+	}
+	dir := tunnel.Dir()
+	if entry, ok, err := tunnel.Find(dir, name); err != nil {
+		return err
+	} else if ok && entry.Running() {
+		return fmt.Errorf("隧道 %s 已在运行 (PID %d)，如需重启请先运行 hostmanager tunnel stop %s", name, entry.PID, name)
+	}
+	warnBackgroundAuth(*host)
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("无法确定程序路径: %v", err)
+	}
+	configPath, err := filepath.Abs(c.config.Path())
+	if err != nil {
+		return err
+	}
+	logFile, err := tunnel.OpenLog(dir, name)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %v", err)
+	}
+	defer logFile.Close()
+	offset, _ := logFile.Seek(0, io.SeekEnd)
+
+	cmdArgs := []string{"--config", configPath, "tunnel", host.Name, "--managed=" + name}
+	for _, n := range opts.names {
+		cmdArgs = append(cmdArgs, "--name", n)
+	}
+	cmd := exec.Command(exe, cmdArgs...)
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	tunnel.Detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("启动后台隧道失败: %v", err)
+	}
+	if err := tunnel.WritePID(dir, name, cmd.Process.Pid); err != nil {
+		cmd.Process.Kill()
+		return fmt.Errorf("写入 pid 文件失败: %v", err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+		tunnel.RemovePID(dir, name)
+		return fmt.Errorf("后台隧道 %s 启动失败:\n%s", name, strings.TrimRight(readLogFrom(tunnel.LogPath(dir, name), offset), "\n"))
+	case <-time.After(tunnelStartupWait):
+	}
+
+	fmt.Printf("✅ 后台隧道 %s 已启动 (PID %d)\n", name, cmd.Process.Pid)
+	for _, forward := range forwards {
+		fmt.Printf("   %s\n", forward)
+	}
+	fmt.Printf("📄 日志: %s\n", tunnel.LogPath(dir, name))
+	fmt.Printf("💡 查看状态: hostmanager tunnel ls，停止: hostmanager tunnel stop %s\n", name)
+	return nil
+}
+
+// 后台隧道无法在终端提示输入，提前提醒需要交互的认证方式
+func warnBackgroundAuth(host models.Host) {
+	hosts := append(append([]models.Host{}, host.Via...), host)
+	for _, h := range hosts {
+		switch {
+		case h.PasswordRef != "" && os.Getenv("HOSTMANAGER_VAULT_PASSPHRASE") == "":
+			fmt.Printf("⚠️  %s 的密码保存在保险库中，后台隧道需要设置 HOSTMANAGER_VAULT_PASSPHRASE 才能读取\n", h.Name)
+		case h.AuthType == "password" && !h.HasStoredPassword():
+			fmt.Printf("⚠️  %s 未保存密码，后台隧道无法提示输入，请使用密钥、ssh-agent 或保存密码\n", h.Name)
+		}
+	}
+}
+
+// 读取日志文件 offset 之后的内容
+func readLogFrom(path string, offset int64) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	file.Seek(offset, io.SeekStart)
+	data, _ := io.ReadAll(file)
+	return string(data)
+}
+
+// 停止后台隧道
+func (c *CLI) stopTunnels(args []string) error {
+	all := false
+	var names []string
+	for _, arg := range args {
+		switch {
+		case arg == "--all" || arg == "-a":
+			all = true
+		case strings.HasPrefix(arg, "-"):
+			return usageError("未知参数: %s", arg)
+		default:
+			names = append(names, arg)
+		}
+	}
+
+	dir := tunnel.Dir()
+	if all {
+		entries, err := tunnel.List(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.Name != "" {
+				names = append(names, entry.Name)
+			}
+		}
+		if len(names) == 0 {
+			fmt.Printf("📭 没有后台隧道\n")
+			return nil
+		}
+	} else if len(names) == 0 {
+		return usageError("请指定要停止的隧道名称，或使用 --all")
+	}
+
+	var missing []string
+	for _, name := range names {
+		running, err := tunnel.Stop(dir, name, tunnelStopTimeout)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			missing = append(missing, name)
+		case err != nil:
+			return err
+		case running:
+			fmt.Printf("🛑 已停止隧道 %s\n", name)
+		default:
+			fmt.Printf("🧹 隧道 %s 已退出，已清理记录\n", name)
+		}
+	}
+	if len(missing) > 0 {
+		return withExitCode(ExitNotFound, fmt.Errorf("未找到后台隧道: %s", strings.Join(missing, ", ")))
+	}
+	return nil
+}
+
+// 列出后台隧道和前台运行的隧道
+func (c *CLI) listTunnels(args []string) error {
+	if len(args) > 0 {
+		return usageError("未知参数: %s", args[0])
+	}
+	dir := tunnel.Dir()
+	entries, err := tunnel.List(dir)
+	if err != nil {
+		return err
+	}
+
+	records := []tunnelRecord{}
+	for _, entry := range entries {
+		records = append(records, newTunnelRecord(dir, entry))
+	}
+	if c.output.Structured() {
+		rows := output.Rows{Header: []string{"name", "host", "pid", "status", "active", "restarts", "unhealthy"}}
+		for _, r := range records {
+			var active int64
+			for _, f := range r.Forwards {
+				active += f.Active
+			}
+			rows.Rows = append(rows.Rows, []string{
+				r.Name, r.Host, strconv.Itoa(r.PID), r.Status, strconv.FormatInt(active, 10),
+				strconv.Itoa(r.Restarts), strconv.FormatBool(r.Unhealthy),
+			})
+		}
+		return output.Write(os.Stdout, c.output, records, rows)
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("📭 没有运行中的隧道，使用 hostmanager tunnel start <主机> 在后台启动\n")
+		return nil
+	}
+	fmt.Printf("🔀 隧道列表:\n")
+	for i, r := range records {
+		name := r.Name
+		if name == "" {
+			name = "(前台)"
+		}
+		line := fmt.Sprintf("   %s %s", entries[i].Icon(), name)
+		if r.Host != "" {
+			line += "  主机: " + r.Host
+		}
+		line += fmt.Sprintf("  PID %d  %s", r.PID, tunnel.StatusName(r.Status))
+		if r.Started != nil {
+			line += fmt.Sprintf("  已运行 %s", time.Since(*r.Started).Round(time.Second))
+		}
+		if r.Restarts > 0 {
+			line += fmt.Sprintf("  重连 %d 次", r.Restarts)
+		}
+		fmt.Println(line)
+		for _, f := range r.Forwards {
+			health := ""
+			if f.Health != "" && f.Health != tunnel.HealthOK {
+				health = "  ⚠️ " + f.Health
+			}
+			fmt.Printf("      %s  %d 个连接 (共 %d)%s\n", f.Spec, f.Active, f.Total, health)
+		}
+		if r.Status == tunnel.StatusExited {
+			fmt.Printf("      查看日志: hostmanager tunnel logs %s\n", r.Name)
+		}
+	}
+	return nil
+}
+
+func newTunnelRecord(dir string, entry tunnel.Entry) tunnelRecord {
+	record := tunnelRecord{Name: entry.Name, PID: entry.PID, Status: entry.Status, Forwards: []tunnelForwardRecord{}}
+	if entry.Name != "" {
+		record.LogFile = tunnel.LogPath(dir, entry.Name)
+	}
+	if state := entry.State; state != nil {
+		started := state.Started
+		record.Host, record.Restarts, record.Started = state.Host, state.Restarts, &started
+		record.Unhealthy = state.Unhealthy()
+		for _, f := range state.Forwards {
+			record.Forwards = append(record.Forwards, tunnelForwardRecord{Spec: f.Spec, Active: f.Active, Total: f.Total, Health: f.Health})
+		}
+	}
+	return record
+}
+
+// 显示后台隧道日志，-f 持续输出新内容
+func (c *CLI) tunnelLogs(args []string) error {
+	var name string
+	lines := defaultTunnelLogLines
+	follow := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--follow" || arg == "-f":
+			follow = true
+		case arg == "--lines" || arg == "-n":
+			if i+1 >= len(args) {
+				return usageError("%s 需要参数值", arg)
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 0 {
+				return usageError("无效的行数: %s", args[i])
+			}
+			lines = n
+		case strings.HasPrefix(arg, "-"):
+			return usageError("未知参数: %s", arg)
+		case name != "":
+			return usageError("一次只能查看一个隧道的日志")
+		default:
+			name = arg
+		}
+	}
+	if name == "" {
+		return usageError("请指定隧道名称")
+	}
+
+	path := tunnel.LogPath(tunnel.Dir(), name)
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return withExitCode(ExitNotFound, fmt.Errorf("未找到隧道 %s 的日志", name))
+	}
+	if err != nil {
+		return err
+	}
+	// 跟踪时文件会被重新打开，关闭最后打开的那个
+	defer func() { file.Close() }()
+
+	tail, err := lastLines(file, lines)
+	if err != nil {
+		return err
+	}
+	for _, line := range tail {
+		fmt.Println(line)
+	}
+	if !follow {
+		return nil
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	offset, _ := file.Seek(0, io.SeekEnd)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C:
+		}
+		// 重新启动时日志可能被轮转，文件变小后从头读取
+		if info, err := os.Stat(path); err == nil && info.Size() < offset {
+			file.Close()
+			if file, err = os.Open(path); err != nil {
+				return err
+			}
+			offset = 0
+		}
+		file.Seek(offset, io.SeekStart)
+		n, _ := io.Copy(os.Stdout, file)
+		offset += n
+	}
+}
+
+// 读取文件的最后 n 行
+func lastLines(r io.Reader, n int) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// 显示 tunnel 命令帮助
+func (c *CLI) showTunnelHelp() {
+	fmt.Printf("🔀 端口转发隧道用法:\n")
+	fmt.Printf("   hostmanager tunnel <主机名> [--name <转发名称>]...             前台运行\n")
+	fmt.Printf("   hostmanager tunnel start <主机名> [--name <转发名称>]... [--as <隧道名称>]   在后台启动\n")
+	fmt.Printf("   hostmanager tunnel stop <隧道名称>... | --all                 停止后台隧道\n")
+	fmt.Printf("   hostmanager tunnel ls [-o json]                              列出隧道及健康状态\n")
+	fmt.Printf("   hostmanager tunnel logs <隧道名称> [-n 行数] [-f]              查看后台隧道日志\n\n")
+	fmt.Printf("只建立主机配置中的端口转发 (forwards)，不启动 Shell；连接断开或健康检查连续失败后\n")
+	fmt.Printf("自动重连。--name 可重复或用逗号分隔，只建立指定的转发。后台隧道默认以主机名命名，\n")
+	fmt.Printf("无法在终端输入密码，请使用密钥、ssh-agent 或已保存的密码。\n\n")
+	fmt.Printf("配置示例:\n")
+	fmt.Printf("   forwards:\n")
+	fmt.Printf("     - {name: pg, type: local, listen: 5432, target: db.internal:5432}    # ssh -L\n")
+	fmt.Printf("     - {name: web, type: remote, listen: 8080, target: localhost:3000}    # ssh -R\n")
+	fmt.Printf("     - {name: socks, type: dynamic, listen: 1080}                         # ssh -D\n")
+}
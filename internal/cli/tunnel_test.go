@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+// 测试 tunnel 参数解析
+func TestParseTunnelOptions(t *testing.T) {
+	opts, err := parseTunnelOptions([]string{"db", "--name", "pg,web", "-n", "socks", "--as=db-all"})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if opts.host != "db" || strings.Join(opts.names, ",") != "pg,web,socks" || opts.as != "db-all" {
+		t.Errorf("解析结果错误: %+v", opts)
+	}
+
+	if _, err := parseTunnelOptions([]string{"db", "web"}); err == nil {
+		t.Error("多个主机应返回错误")
+	}
+	if _, err := parseTunnelOptions([]string{"db", "--name"}); err == nil {
+		t.Error("缺少参数值应返回错误")
+	}
+	if _, err := parseTunnelOptions([]string{"db", "--bogus"}); err == nil {
+		t.Error("未知参数应返回错误")
+	}
+}
+
+// 测试读取日志最后几行
+func TestLastLines(t *testing.T) {
+	lines, err := lastLines(strings.NewReader("a\nb\nc\nd\n"), 2)
+	if err != nil || strings.Join(lines, ",") != "c,d" {
+		t.Errorf("读取结果错误: %v %v", lines, err)
+	}
+	if lines, _ := lastLines(strings.NewReader("a\n"), 0); len(lines) != 0 {
+		t.Errorf("0 行时不应输出: %v", lines)
+	}
+}
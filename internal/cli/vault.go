@@ -0,0 +1,301 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/daihao4371/hostmanager/internal/config"
+	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/vault"
+)
+
+// 处理密码保险库命令
+func (c *CLI) handleVault(args []string) error {
+	if len(args) == 0 {
+		c.showVaultHelp()
+		return nil
+	}
+
+	switch args[0] {
+	case "init":
+		return c.vaultInit()
+	case "unlock":
+		return c.vaultUnlock()
+	case "set":
+		return c.vaultSet(args[1:])
+	case "rm", "remove":
+		return c.vaultRemove(args[1:])
+	case "rekey":
+		return c.vaultRekey()
+	case "migrate":
+		return c.vaultMigrate()
+	default:
+		return fmt.Errorf("未知的 vault 子命令: %s", args[0])
+	}
+}
+
+// 初始化保险库
+func (c *CLI) vaultInit() error {
+	path := vault.DefaultPath()
+	if vault.Exists(path) {
+		return fmt.Errorf("保险库已存在: %s", path)
+	}
+
+	passphrase, err := promptNewPassphrase()
+	if err != nil {
+		return err
+	}
+
+	v, err := vault.Create(path, passphrase)
+	if err != nil {
+		return fmt.Errorf("创建保险库失败: %v", err)
+	}
+	vault.Remember(v)
+
+	fmt.Printf("✅ 保险库已创建: %s\n", path)
+	if c.countPlaintextPasswords() > 0 {
+		fmt.Printf("💡 配置中还有明文密码，运行 'hostmanager vault migrate' 迁移到保险库\n")
+	}
+	return nil
+}
+
+// 验证口令并显示保险库概况
+func (c *CLI) vaultUnlock() error {
+	v, err := vault.Unlock()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔓 保险库已解锁: %s\n", v.Path())
+	fmt.Printf("   共 %d 个条目\n", len(v.IDs()))
+	for _, group := range c.config.Groups {
+		for _, host := range group.Hosts {
+			if host.PasswordRef != "" {
+				fmt.Printf("   🔐 %s -> %s\n", host.Name, host.PasswordRef)
+			}
+		}
+	}
+	return nil
+}
+
+// 为主机设置保险库密码
+func (c *CLI) vaultSet(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("请指定主机名称")
+	}
+
+	groupIndex, hostIndex := c.findHostLocation(args[0])
+	if groupIndex == -1 {
+		return notFoundError(args[0])
+	}
+	host := &c.config.Groups[groupIndex].Hosts[hostIndex]
+
+	password, err := vault.PromptPassphrase(fmt.Sprintf("%s 的密码: ", host.Name))
+	if err != nil {
+		return err
+	}
+	if password == "" {
+		return fmt.Errorf("密码不能为空")
+	}
+
+	if err := storePasswordInVault(host, password); err != nil {
+		return err
+	}
+
+	err = config.SaveConfig(c.config.Path(), c.config)
+	if err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+
+	fmt.Printf("✅ 主机 '%s' 的密码已保存到保险库\n", host.Name)
+	return nil
+}
+
+// 删除主机的保险库密码
+func (c *CLI) vaultRemove(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("请指定主机名称")
+	}
+
+	groupIndex, hostIndex := c.findHostLocation(args[0])
+	if groupIndex == -1 {
+		return notFoundError(args[0])
+	}
+	host := &c.config.Groups[groupIndex].Hosts[hostIndex]
+	if host.PasswordRef == "" {
+		return fmt.Errorf("主机 '%s' 没有保存在保险库中的密码", host.Name)
+	}
+
+	v, err := vault.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := v.Delete(host.PasswordRef); err != nil && err != vault.ErrNotFound {
+		return err
+	}
+	if err := v.Save(); err != nil {
+		return fmt.Errorf("保存保险库失败: %v", err)
+	}
+
+	host.PasswordRef = ""
+	err = config.SaveConfig(c.config.Path(), c.config)
+	if err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+
+	fmt.Printf("✅ 已删除主机 '%s' 的保险库密码\n", host.Name)
+	return nil
+}
+
+// 更换保险库口令
+func (c *CLI) vaultRekey() error {
+	v, err := vault.Unlock()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("请设置新的保险库口令\n")
+	passphrase, err := promptNewPassphrase()
+	if err != nil {
+		return err
+	}
+
+	if err := v.Rekey(passphrase); err != nil {
+		return fmt.Errorf("更换口令失败: %v", err)
+	}
+	fmt.Printf("✅ 保险库口令已更换\n")
+	return nil
+}
+
+// 将配置中的明文密码迁移到保险库
+func (c *CLI) vaultMigrate() error {
+	if c.countPlaintextPasswords() == 0 {
+		fmt.Printf("配置中没有需要迁移的明文密码\n")
+		return nil
+	}
+
+	migrated := 0
+	for i := range c.config.Groups {
+		for j := range c.config.Groups[i].Hosts {
+			host := &c.config.Groups[i].Hosts[j]
+			if host.Password == "" {
+				continue
+			}
+			if err := storePasswordInVault(host, host.Password); err != nil {
+				return err
+			}
+			fmt.Printf("   🔐 %s\n", host.Name)
+			migrated++
+		}
+	}
+
+	err := config.SaveConfig(c.config.Path(), c.config)
+	if err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+
+	fmt.Printf("✅ 已迁移 %d 个明文密码到保险库\n", migrated)
+	return nil
+}
+
+// 统计配置中的明文密码数量
+func (c *CLI) countPlaintextPasswords() int {
+	count := 0
+	for _, group := range c.config.Groups {
+		for _, host := range group.Hosts {
+			if host.Password != "" {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// 主机被删除或不再使用密码后，在配置保存成功后删除其保险库条目；
+// 仍被其他主机引用的条目保留，清理失败只给出提示
+func (c *CLI) removeVaultEntries(refs ...string) {
+	var unused []string
+	for _, ref := range refs {
+		if ref != "" && !c.passwordRefInUse(ref) {
+			unused = append(unused, ref)
+		}
+	}
+	if err := vault.Remove(unused...); err != nil {
+		fmt.Printf("⚠️  清理保险库密码失败: %v\n", err)
+	}
+}
+
+// 配置中是否还有主机引用该保险库条目
+func (c *CLI) passwordRefInUse(ref string) bool {
+	for _, group := range c.config.Groups {
+		for _, host := range group.Hosts {
+			if host.PasswordRef == ref {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 把密码写入保险库并在主机上记录引用，清除明文密码
+func storePasswordInVault(host *models.Host, password string) error {
+	v, err := vault.Unlock()
+	if err != nil {
+		return err
+	}
+
+	id := host.PasswordRef
+	if id == "" {
+		id = vault.NewID()
+	}
+	if err := v.Set(id, password); err != nil {
+		return err
+	}
+	if err := v.Save(); err != nil {
+		return fmt.Errorf("保存保险库失败: %v", err)
+	}
+
+	host.PasswordRef = id
+	host.Password = ""
+	return nil
+}
+
+// 保存主机密码：保险库已初始化时写入保险库，否则保留明文
+func storeHostPassword(host *models.Host, password string) error {
+	if !vault.Exists(vault.DefaultPath()) {
+		host.Password = password
+		return nil
+	}
+	return storePasswordInVault(host, password)
+}
+
+// 提示输入新口令并确认
+func promptNewPassphrase() (string, error) {
+	passphrase, err := vault.PromptPassphrase("新口令: ")
+	if err != nil {
+		return "", err
+	}
+	confirm, err := vault.PromptPassphrase("确认口令: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != confirm {
+		return "", fmt.Errorf("两次输入的口令不一致")
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("口令不能为空")
+	}
+	return passphrase, nil
+}
+
+// 显示保险库命令帮助
+func (c *CLI) showVaultHelp() {
+	fmt.Printf("🔒 密码保险库命令:\n")
+	fmt.Printf("   hostmanager vault init           创建保险库\n")
+	fmt.Printf("   hostmanager vault unlock         验证口令并查看条目\n")
+	fmt.Printf("   hostmanager vault set <主机>      设置主机密码\n")
+	fmt.Printf("   hostmanager vault rm <主机>       删除主机密码\n")
+	fmt.Printf("   hostmanager vault rekey          更换保险库口令\n")
+	fmt.Printf("   hostmanager vault migrate        迁移配置中的明文密码\n")
+	fmt.Printf("\n保险库位置: %s (可通过 HOSTMANAGER_VAULT 修改)\n", vault.DefaultPath())
+	fmt.Printf("设置 HOSTMANAGER_VAULT_PASSPHRASE 可跳过口令输入\n")
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+)
+
+// 每个配置文件保留的备份数量
+const maxBackups = 20
+
+// 备份文件名中的时间格式
+const backupTimeFormat = "20060102-150405.000"
+
+// 配置文件备份
+type Backup struct {
+	Path string
+	Time time.Time
+	Size int64
+}
+
+// 备份目录，默认 ~/.hostmanager/backups，可通过 HOSTMANAGER_BACKUP_DIR 覆盖
+func BackupDir() string {
+	if dir := os.Getenv("HOSTMANAGER_BACKUP_DIR"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".hostmanager", "backups")
+	}
+	return filepath.Join(home, ".hostmanager", "backups")
+}
+
+// 备份文件名前缀，包含文件名和绝对路径的哈希，例如 config.yaml -> config-1a2b3c4d-，
+// 不同目录下的同名文件（如多个 include）的备份互不影响
+func backupPrefix(configPath string) string {
+	if abs, err := filepath.Abs(configPath); err == nil {
+		configPath = abs
+	}
+	sum := sha256.Sum256([]byte(configPath))
+	base := filepath.Base(configPath)
+	return fmt.Sprintf("%s-%x-", strings.TrimSuffix(base, filepath.Ext(base)), sum[:4])
+}
+
+// 保存一份带时间戳的备份，并删除超出数量的旧备份
+func backup(configPath string, data []byte) error {
+	dir := BackupDir()
+	name := backupPrefix(configPath) + time.Now().Format(backupTimeFormat) + ".yaml"
+	if err := fsutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+		return err
+	}
+
+	backups, err := ListBackups(configPath)
+	if err != nil {
+		return err
+	}
+	for _, old := range backups[min(len(backups), maxBackups):] {
+		os.Remove(old.Path)
+	}
+	return nil
+}
+
+// 列出配置文件的备份（最新的在前）
+func ListBackups(configPath string) ([]Backup, error) {
+	dir := BackupDir()
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := backupPrefix(configPath)
+	var backups []Backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".yaml")
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{Path: filepath.Join(dir, name), Time: t, Size: info.Size()})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+// 用备份覆盖配置文件（当前内容会先备份）
+func Restore(configPath, backupPath string) error {
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return err
+	}
+	var check Config
+	if err := yaml.Unmarshal(data, &check); err != nil {
+		return fmt.Errorf("备份文件无效: %v", err)
+	}
+	return Replace(configPath, data)
+}
+
+// 直接替换配置文件内容（当前内容会先备份）
+func Replace(configPath string, data []byte) error {
+	lock, err := fsutil.LockFile(configPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	current, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(current) > 0 {
+		if err := backup(configPath, current); err != nil {
+			return fmt.Errorf("备份配置文件失败: %v", err)
+		}
+	}
+	return fsutil.WriteFile(configPath, data, 0600)
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// 测试保存时备份旧文件并检测其他进程的修改
+func TestSaveBackupAndConflict(t *testing.T) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	path := writeTestConfig(t, "groups:\n  - name: a\n    hosts: []\n")
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Groups[0].Name = "b"
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	backups, err := ListBackups(path)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("应生成一份备份: %v %v", backups, err)
+	}
+
+	// 其他进程修改后再保存应报错，而不是覆盖
+	if err := os.WriteFile(path, []byte("groups: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(path); !errors.Is(err, ErrModified) {
+		t.Errorf("应检测到并发修改, 得到 %v", err)
+	}
+
+	// 从备份恢复
+	if err := Restore(path, backups[0].Path); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	restored, err := LoadConfig(path)
+	if err != nil || len(restored.Groups) != 1 || restored.Groups[0].Name != "a" {
+		t.Errorf("恢复结果错误: %v %+v", err, restored)
+	}
+}
+
+// 测试在锁内基于最新内容修改
+func TestUpdate(t *testing.T) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	path := writeTestConfig(t, "groups:\n  - name: a\n    hosts: []\n")
+
+	stale, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("groups:\n  - name: a\n    hosts: []\n  - name: added\n    hosts: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := Update(stale.Path(), func(cfg *Config) error {
+		cfg.UIConfig.Theme = "light"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	if len(updated.Groups) != 2 || updated.UIConfig.Theme != "light" {
+		t.Errorf("应保留外部修改并应用新修改: %+v", updated)
+	}
+}
+
+// 测试备份数量上限
+func TestBackupRotation(t *testing.T) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	for i := 0; i < maxBackups+5; i++ {
+		name := filepath.Join(BackupDir(), fmt.Sprintf("%s20260101-0000%02d.000.yaml", backupPrefix(path), i))
+		if err := os.WriteFile(name, []byte("groups: []\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := backup(path, []byte("groups: []\n")); err != nil {
+		t.Fatal(err)
+	}
+	backups, _ := ListBackups(path)
+	if len(backups) != maxBackups {
+		t.Errorf("应只保留 %d 份备份, 实际 %d", maxBackups, len(backups))
+	}
+}
+
+// 测试不同目录下的同名配置文件的备份互不影响
+func TestBackupSameName(t *testing.T) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	first := filepath.Join(t.TempDir(), "hosts.yaml")
+	second := filepath.Join(t.TempDir(), "hosts.yaml")
+	if err := backup(first, []byte("groups: []\n")); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxBackups; i++ {
+		name := filepath.Join(BackupDir(), fmt.Sprintf("%s20260101-0000%02d.000.yaml", backupPrefix(second), i))
+		if err := os.WriteFile(name, []byte("groups: []\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := backup(second, []byte("groups: []\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if backups, _ := ListBackups(first); len(backups) != 1 {
+		t.Errorf("其他文件的备份轮换不应删除此文件的备份: %v", backups)
+	}
+	if backups, _ := ListBackups(second); len(backups) != maxBackups {
+		t.Errorf("备份数量错误: %d", len(backups))
+	}
+}
@@ -1,11 +1,15 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
-	"path/filepath"
 
 	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 
+	"github.com/daihao4371/hostmanager/internal/fsutil"
 	"github.com/daihao4371/hostmanager/internal/models"
 	"github.com/daihao4371/hostmanager/internal/theme"
 )
@@ -40,100 +44,174 @@ type UIConfig struct {
 
 // 主配置结构
 type Config struct {
-	Groups   []models.Group `yaml:"groups"`
-	UIConfig UIConfig       `yaml:"ui_config"`
-}
-
-// 查找配置文件的可能位置
-func findConfigFile(filename string) (string, error) {
-	// 配置文件查找优先级
-	searchPaths := []string{
-		filename,                                    // 当前目录
-		filepath.Join(".", filename),               // 当前目录（显式）
-		filepath.Join(os.Getenv("HOME"), filename), // 用户家目录
-		"/etc/hostmanager/" + filename,             // 系统配置目录
-	}
+	Include   Includes                `yaml:"include,omitempty"`   // 引入的其他配置文件（支持通配符）
+	Defaults  models.HostDefaults     `yaml:"defaults,omitempty"`  // 全局主机默认值
+	Groups    []models.Group          `yaml:"groups"`
+	Overrides map[string]HostOverride `yaml:"overrides,omitempty"` // 引入文件中主机的个人设置（收藏、密码）
+	UIConfig  UIConfig                `yaml:"ui_config"`
 
-	// 如果是相对路径，尝试找到程序所在目录
-	if execPath, err := os.Executable(); err == nil {
-		execDir := filepath.Dir(execPath)
-		searchPaths = append(searchPaths, filepath.Join(execDir, filename))
-	}
+	path     string          // 加载时的配置文件路径，保存时写回该文件
+	original []byte          // 加载时的文件内容，用于检测其他进程的修改
+	base     *yamlv3.Node    // 加载时的配置，保存时只改写与其不同的字段
+	includes []*includedFile // 通过 include 加载的文件
 
-	// 按优先级查找配置文件
-	for _, path := range searchPaths {
-		if _, err := os.Stat(path); err == nil {
-			return path, nil
-		}
-	}
+	reformatted []string // 上次保存时无法保留注释和格式、整体重新生成的文件
+}
 
-	return "", os.ErrNotExist
+// 创建空配置（配置文件尚不存在时使用）
+func NewConfig(filePath string) *Config {
+	config := &Config{path: absPath(filePath)}
+	setUIDefaults(&config.UIConfig)
+	return config
 }
 
-// 加载配置文件
+// 加载配置文件（路径由 FindConfig 确定）
 func LoadConfig(filePath string) (*Config, error) {
-	// 查找配置文件的实际位置
-	actualPath, err := findConfigFile(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := os.ReadFile(actualPath)
+	var config Config
+	err = yaml.Unmarshal(data, &config)
 	if err != nil {
 		return nil, err
 	}
+	config.path = absPath(filePath)
+	config.original = data
+	if err := config.loadIncludes(); err != nil {
+		return nil, err
+	}
+	config.syncSources()
+	applyDefaults(&config)
 
-	var config Config
-	err = yaml.Unmarshal(data, &config)
-	if err != nil {
+	// 解析跳板机链，出错的主机在使用时才报告，不影响加载其他主机（config lint 会列出）
+	config.ResolveJumps()
+
+	if config.base, err = encodeNode(config.mainView()); err != nil {
 		return nil, err
 	}
+	for _, file := range config.includes {
+		if file.base, err = encodeNode(config.includeView(file)); err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
 
-	// 设置主机默认值
+// 设置主机和UI配置的默认值
+func applyDefaults(config *Config) {
 	for i := range config.Groups {
 		for j := range config.Groups[i].Hosts {
-			setHostDefaults(&config.Groups[i].Hosts[j])
+			config.ApplyHostDefaults(config.Groups[i], &config.Groups[i].Hosts[j])
 		}
 	}
-
-	// 设置UI配置默认值
 	setUIDefaults(&config.UIConfig)
+}
 
-	return &config, nil
+// 配置文件路径
+func (c *Config) Path() string {
+	if c.path == "" {
+		return DefaultPath()
+	}
+	return c.path
 }
 
-// 保存配置到文件（全局函数）
+// 配置文件在加载后被其他进程修改
+var ErrModified = errors.New("配置文件已被其他进程修改，请重新执行操作")
+
+// 保存配置到文件（全局函数），配置文件被整体重新生成时在终端给出警告
 func SaveConfig(filePath string, config *Config) error {
-	data, err := yaml.Marshal(config)
-	if err != nil {
+	if err := config.Save(filePath); err != nil {
 		return err
 	}
-	return os.WriteFile(filePath, data, 0644)
+	for _, path := range config.Reformatted() {
+		fmt.Fprintf(os.Stderr, "⚠️  %s\n", ReformatWarning(path))
+	}
+	return nil
+}
+
+// 上次保存时无法增量修改、整体重新生成（注释和键顺序丢失）的文件，修改前的内容已自动备份
+func (c *Config) Reformatted() []string {
+	return c.reformatted
+}
+
+// 配置文件被整体重新生成时的提示
+func ReformatWarning(path string) string {
+	return fmt.Sprintf("无法在保留注释和格式的情况下修改 %s，已重新生成该文件（原内容已备份，可使用 hostmanager config restore 恢复）", path)
 }
 
-// 保存配置文件
+// 保存配置文件（可能包含密码，仅当前用户可读写），只改写修改过的字段
 func (c *Config) Save(filePath string) error {
-	data, err := yaml.Marshal(c)
+	lock, err := fsutil.LockFile(filePath)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filePath, data, 0644)
+	defer lock.Unlock()
+	return c.writeLocked(filePath)
+}
+
+// 在文件锁内重新读取配置、应用修改并保存，返回修改后的配置
+func Update(filePath string, modify func(*Config) error) (*Config, error) {
+	lock, err := fsutil.LockFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	config, err := LoadConfig(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := modify(config); err != nil {
+		return nil, err
+	}
+	if err := config.writeLocked(filePath); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
 
-// 设置主机默认值
-func setHostDefaults(host *models.Host) {
-	if host.Port == 0 {
-		host.Port = 22
+// 写入配置（调用方需持有文件锁）：检查并发修改，备份旧文件后原子替换
+func (c *Config) writeLocked(filePath string) error {
+	current, err := os.ReadFile(filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	ownFile := absPath(filePath) == c.Path()
+	if ownFile && !bytes.Equal(current, c.original) {
+		return ErrModified
+	}
+
+	// 引入文件中的主机随分组写回各自的文件，个人设置写入主配置文件
+	c.reformatted = nil
+	c.syncSources()
+	if ownFile {
+		c.updateOverrides()
+		if err := c.writeIncludes(); err != nil {
+			return err
+		}
 	}
-	if host.Username == "" {
-		host.Username = "app"
+	data, node, reformatted, err := c.encode(filePath)
+	if err != nil {
+		return err
 	}
-	if host.AuthType == "" {
-		host.AuthType = "key"
+	if reformatted {
+		c.reformatted = append(c.reformatted, absPath(filePath))
+	}
+
+	if len(current) > 0 && !bytes.Equal(current, data) {
+		if err := backup(filePath, current); err != nil {
+			return fmt.Errorf("备份配置文件失败: %v", err)
+		}
+	}
+	if err := fsutil.WriteFile(filePath, data, 0600); err != nil {
+		return err
 	}
-	if host.KeyPath == "" && host.AuthType == "key" {
-		host.KeyPath = "/Users/daihao/.ssh/id_rsa"
+	if ownFile {
+		c.original, c.base = data, node
 	}
+	return nil
 }
 
 // 设置UI配置默认值
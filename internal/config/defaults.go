@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"os/user"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 内置默认值（优先级最低）
+func builtinDefaults() models.HostDefaults {
+	return models.HostDefaults{
+		Port:     22,
+		Username: CurrentUsername(),
+		AuthType: "key",
+		KeyPath:  "~/.ssh/id_rsa",
+	}
+}
+
+// 当前系统用户名（与 ssh 命令的默认用户一致）
+func CurrentUsername() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// 新主机使用的默认值：全局 defaults 与内置默认值合并
+func (c *Config) HostDefaults(group models.Group) models.HostDefaults {
+	var host models.Host
+	c.ApplyHostDefaults(group, &host)
+	return host.Inherited
+}
+
+// 补齐主机未设置的字段：主机 > 分组 > 全局 defaults > 内置默认值
+// 补齐的值记录在 Host.Inherited，保存时不写入主机；再次调用时按新的分组重新继承
+// 跳板机不在此展开，由 effectiveJump 在解析时按同样的优先级处理
+func (c *Config) ApplyHostDefaults(group models.Group, host *models.Host) {
+	explicit := host.Explicit()
+	resolved := explicit
+	levels := []models.HostDefaults{group.HostDefaults, c.Defaults, builtinDefaults()}
+	for _, d := range levels {
+		if resolved.Port == 0 {
+			resolved.Port = d.Port
+		}
+		if resolved.Username == "" {
+			resolved.Username = d.Username
+		}
+		if resolved.AuthType == "" {
+			resolved.AuthType = d.AuthType
+		}
+		if resolved.ZmodemEnable == nil {
+			resolved.ZmodemEnable = d.ZmodemEnable
+		}
+		if resolved.ConnectMode == "" {
+			resolved.ConnectMode = d.ConnectMode
+		}
+		if resolved.ConnectTimeout == 0 {
+			resolved.ConnectTimeout = d.ConnectTimeout
+		}
+	}
+
+	// 私钥路径只对密钥认证生效
+	if resolved.AuthType == "key" {
+		for _, d := range levels {
+			if resolved.KeyPath == "" {
+				resolved.KeyPath = d.KeyPath
+			}
+		}
+	}
+
+	inherited := &resolved.Inherited
+	if explicit.Port == 0 {
+		inherited.Port = resolved.Port
+	}
+	if explicit.Username == "" {
+		inherited.Username = resolved.Username
+	}
+	if explicit.AuthType == "" {
+		inherited.AuthType = resolved.AuthType
+	}
+	if explicit.KeyPath == "" {
+		inherited.KeyPath = resolved.KeyPath
+	}
+	if explicit.ZmodemEnable == nil {
+		inherited.ZmodemEnable = resolved.ZmodemEnable
+	}
+	if explicit.ConnectMode == "" {
+		inherited.ConnectMode = resolved.ConnectMode
+	}
+	if explicit.ConnectTimeout == 0 {
+		inherited.ConnectTimeout = resolved.ConnectTimeout
+	}
+	*host = resolved
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+const defaultsTestConfig = `defaults:
+  username: deploy
+  key_path: ~/.ssh/id_ed25519
+  connect_timeout: 5
+  jump: bastion
+groups:
+- name: 生产环境
+  port: 2222
+  hosts:
+  - name: bastion
+    ip: 10.0.0.1
+    port: 22
+  - name: web
+    ip: 10.0.1.1
+  - name: db
+    ip: 10.0.1.2
+    username: dba
+    auth_type: password
+- name: 公网
+  jump: none
+  auth_type: key
+  key_path: $HOME/.ssh/public
+  hosts:
+  - name: public
+    ip: 1.2.3.4
+`
+
+// 测试默认值的继承顺序：主机 > 分组 > 全局 > 内置
+func TestApplyHostDefaults(t *testing.T) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(defaultsTestConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+
+	expected := map[string]struct {
+		port     int
+		username string
+		authType string
+		keyPath  string
+		via      string
+	}{
+		"bastion": {22, "deploy", "key", "~/.ssh/id_ed25519", ""}, // 全局跳板机不作用于自身
+		"web":     {2222, "deploy", "key", "~/.ssh/id_ed25519", "bastion"},
+		"db":      {2222, "dba", "password", "", "bastion"}, // 密码认证不继承私钥
+		"public":  {22, "deploy", "key", "$HOME/.ssh/public", ""},
+	}
+	for _, group := range cfg.Groups {
+		for _, host := range group.Hosts {
+			e := expected[host.Name]
+			var via []string
+			for _, hop := range host.Via {
+				via = append(via, hop.Name)
+			}
+			if host.Port != e.port || host.Username != e.username || host.AuthType != e.authType ||
+				host.KeyPath != e.keyPath || strings.Join(via, " → ") != e.via {
+				t.Errorf("主机 %s 默认值错误: %+v via=%v", host.Name, host, via)
+			}
+			if host.ConnectTimeout != 5 {
+				t.Errorf("主机 %s 连接超时错误: %d", host.Name, host.ConnectTimeout)
+			}
+		}
+	}
+
+	// 新主机使用分组与全局默认值
+	defaults := cfg.HostDefaults(cfg.Groups[0])
+	if defaults.Port != 2222 || defaults.Username != "deploy" || defaults.KeyPath != "~/.ssh/id_ed25519" {
+		t.Errorf("新主机默认值错误: %+v", defaults)
+	}
+
+	// 继承得到的值不写回文件
+	cfg.Groups[0].Hosts[1].Favorite = true
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(defaultsTestConfig, "    ip: 10.0.1.1\n", "    ip: 10.0.1.1\n    favorite: true\n", 1)
+	if string(data) != want {
+		t.Errorf("保存结果不符合预期:\n%s", data)
+	}
+}
+
+// 测试默认值的检查
+func TestValidateDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `defaults:
+  port: 0
+  auth_type: token
+groups:
+- name: 测试
+  connect_mode: telnet
+  hosts:
+  - name: web
+    ip: 10.0.0.1
+`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+
+	// 继承的错误只在 defaults 和分组处报告，不在每台主机上重复
+	var paths []string
+	for _, d := range cfg.Validate() {
+		paths = append(paths, d.Path)
+	}
+	if got := strings.Join(paths, " "); got != "defaults.auth_type groups[0].connect_mode" {
+		t.Errorf("诊断信息错误: %s", got)
+	}
+}
+
+// 测试重写主机时不写入继承的字段：主机重新插入、整体序列化时也只保留主机自己的配置
+func TestSaveKeepsInheritance(t *testing.T) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(defaultsTestConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+
+	// 把 web 移到分组末尾，原文中没有对应的节点
+	hosts := cfg.Groups[0].Hosts
+	web := hosts[1]
+	web.Description = "前端"
+	cfg.Groups[0].Hosts = append([]models.Host{hosts[0], hosts[2]}, web)
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	other := filepath.Join(dir, "copy.yaml")
+	if err := cfg.Save(other); err != nil {
+		t.Fatalf("另存失败: %v", err)
+	}
+
+	for _, file := range []string{path, other} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var raw struct {
+			Groups []struct {
+				Hosts []map[string]interface{} `yaml:"hosts"`
+			} `yaml:"groups"`
+		}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			t.Fatal(err)
+		}
+		for _, host := range raw.Groups[0].Hosts {
+			if host["name"] != "web" {
+				continue
+			}
+			if len(host) != 3 || host["description"] != "前端" {
+				t.Errorf("%s 中 web 不应包含继承的字段: %v", filepath.Base(file), host)
+			}
+		}
+		if cfg, err := LoadConfig(file); err != nil || cfg.Groups[0].Hosts[2].Port != 2222 {
+			t.Errorf("%s 重新加载后应继承分组端口: %v", filepath.Base(file), err)
+		}
+	}
+
+	// 修改过的字段成为主机自己的配置
+	cfg.Groups[0].Hosts[2].Port = 2200
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "port: 2200") {
+		t.Errorf("修改的端口应写入文件:\n%s", data)
+	}
+}
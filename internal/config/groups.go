@@ -0,0 +1,205 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 按名称查找分组索引（忽略大小写），不存在时返回 -1
+func (c *Config) GroupIndex(name string) int {
+	for i, group := range c.Groups {
+		if strings.EqualFold(group.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// 添加空分组
+func (c *Config) AddGroup(name string, jump models.JumpChain) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("分组名称不能为空")
+	}
+	if c.GroupIndex(name) >= 0 {
+		return fmt.Errorf("分组 %s 已存在", name)
+	}
+	c.Groups = append(c.Groups, models.Group{Name: name, HostDefaults: models.HostDefaults{Jump: jump}, Hosts: []models.Host{}})
+	return nil
+}
+
+// 重命名分组
+func (c *Config) RenameGroup(oldName, newName string) error {
+	index := c.GroupIndex(oldName)
+	if index < 0 {
+		return fmt.Errorf("分组 %s 不存在", oldName)
+	}
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("分组名称不能为空")
+	}
+	if other := c.GroupIndex(newName); other >= 0 && other != index {
+		return fmt.Errorf("分组 %s 已存在", newName)
+	}
+	c.Groups[index].Name = newName
+	return nil
+}
+
+// 删除分组及其中的主机
+func (c *Config) RemoveGroup(name string) error {
+	index := c.GroupIndex(name)
+	if index < 0 {
+		return fmt.Errorf("分组 %s 不存在", name)
+	}
+	c.Groups = append(c.Groups[:index], c.Groups[index+1:]...)
+	return nil
+}
+
+// 将主机移动到另一个分组，主机的收藏、标签等配置原样保留；
+// 生效的连接参数和跳板机保持不变，其余未在主机上配置的字段改为继承新分组的默认值
+func (c *Config) MoveHost(hostName, groupName string) error {
+	to := c.GroupIndex(groupName)
+	if to < 0 {
+		return fmt.Errorf("分组 %s 不存在", groupName)
+	}
+	for from := range c.Groups {
+		for i, host := range c.Groups[from].Hosts {
+			if !strings.EqualFold(host.Name, hostName) {
+				continue
+			}
+			if from == to {
+				return nil
+			}
+			host.Jump = c.keepJump(c.Groups[from], c.Groups[to], host)
+			c.keepEndpoint(c.Groups[from], c.Groups[to], &host)
+			c.Groups[from].Hosts = append(c.Groups[from].Hosts[:i], c.Groups[from].Hosts[i+1:]...)
+			c.Groups[to].Hosts = append(c.Groups[to].Hosts, host)
+			return nil
+		}
+	}
+	return fmt.Errorf("主机 %s 不存在", hostName)
+}
+
+// 将 src 分组的主机全部并入 dst 并删除 src（主机按 MoveHost 的规则继承新分组的默认值）
+func (c *Config) MergeGroups(src, dst string) error {
+	from, to := c.GroupIndex(src), c.GroupIndex(dst)
+	if from < 0 {
+		return fmt.Errorf("分组 %s 不存在", src)
+	}
+	if to < 0 {
+		return fmt.Errorf("分组 %s 不存在", dst)
+	}
+	if from == to {
+		return fmt.Errorf("不能将分组合并到自身")
+	}
+	for _, host := range c.Groups[from].Hosts {
+		host.Jump = c.keepJump(c.Groups[from], c.Groups[to], host)
+		c.keepEndpoint(c.Groups[from], c.Groups[to], &host)
+		c.Groups[to].Hosts = append(c.Groups[to].Hosts, host)
+	}
+	c.Groups = append(c.Groups[:from], c.Groups[from+1:]...)
+	return nil
+}
+
+// 调整分组顺序：names 中的分组按给定顺序排在最前，其余保持原有相对顺序
+func (c *Config) ReorderGroups(names []string) error {
+	used := make([]bool, len(c.Groups))
+	ordered := make([]models.Group, 0, len(c.Groups))
+	for _, name := range names {
+		index := c.GroupIndex(name)
+		if index < 0 {
+			return fmt.Errorf("分组 %s 不存在", name)
+		}
+		if used[index] {
+			return fmt.Errorf("分组 %s 重复出现", name)
+		}
+		used[index] = true
+		ordered = append(ordered, c.Groups[index])
+	}
+	for i, group := range c.Groups {
+		if !used[i] {
+			ordered = append(ordered, group)
+		}
+	}
+	c.Groups = ordered
+	return nil
+}
+
+// 将分组移动到指定位置（从 1 开始）
+func (c *Config) MoveGroup(name string, position int) error {
+	index := c.GroupIndex(name)
+	if index < 0 {
+		return fmt.Errorf("分组 %s 不存在", name)
+	}
+	if position < 1 || position > len(c.Groups) {
+		return fmt.Errorf("位置应在 1-%d 之间", len(c.Groups))
+	}
+	group := c.Groups[index]
+	rest := append(append([]models.Group{}, c.Groups[:index]...), c.Groups[index+1:]...)
+	c.Groups = append(rest[:position-1], append([]models.Group{group}, rest[position-1:]...)...)
+	return nil
+}
+
+// 主机换组后保持原来生效的跳板机设置
+func (c *Config) keepJump(from, to models.Group, host models.Host) models.JumpChain {
+	if len(host.Jump) > 0 {
+		return host.Jump
+	}
+	before := c.effectiveJump(from, host)
+	if sameJump(before, c.effectiveJump(to, host)) {
+		return nil
+	}
+	if len(before) == 0 {
+		return models.JumpChain{"none"}
+	}
+	return append(models.JumpChain{}, before...)
+}
+
+// 主机换组后重新继承默认值，但保持原来生效的连接参数（端口、用户名、认证方式、私钥、
+// Zmodem、连接方式和超时）：
+// 新分组会改变的字段显式写入主机，避免连接地址和历史记录（按 user@ip:port 记录）改变
+func (c *Config) keepEndpoint(from, to models.Group, host *models.Host) {
+	before := *host
+	c.ApplyHostDefaults(from, &before)
+	c.ApplyHostDefaults(to, host)
+	if host.Port != before.Port {
+		host.Port, host.Inherited.Port = before.Port, 0
+	}
+	if host.Username != before.Username {
+		host.Username, host.Inherited.Username = before.Username, ""
+	}
+	if host.AuthType != before.AuthType {
+		host.AuthType, host.Inherited.AuthType = before.AuthType, ""
+	}
+	if host.KeyPath != before.KeyPath {
+		host.KeyPath, host.Inherited.KeyPath = before.KeyPath, ""
+	}
+	if host.IsZmodemEnabled() != before.IsZmodemEnabled() {
+		enabled := before.IsZmodemEnabled()
+		host.ZmodemEnable, host.Inherited.ZmodemEnable = &enabled, nil
+	}
+	if host.UsesExternalSSH() != before.UsesExternalSSH() {
+		mode := "native"
+		if before.UsesExternalSSH() {
+			mode = "external"
+		}
+		host.ConnectMode, host.Inherited.ConnectMode = mode, ""
+	}
+	if host.ConnectTimeout != before.ConnectTimeout {
+		host.ConnectTimeout, host.Inherited.ConnectTimeout = before.ConnectTimeout, 0
+	}
+}
+
+func sameJump(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
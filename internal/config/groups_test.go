@@ -0,0 +1,177 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/daihao4371/hostmanager/internal/history"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+func loadGroupTestConfig(t *testing.T) *Config {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(jumpTestConfig), &cfg); err != nil {
+		t.Fatalf("解析配置失败: %v", err)
+	}
+	return &cfg
+}
+
+// 测试移动主机时保留收藏、标签和生效的跳板机
+func TestMoveHost(t *testing.T) {
+	cfg := loadGroupTestConfig(t)
+	cfg.Groups[0].Hosts[1].Favorite = true
+	cfg.Groups[0].Hosts[1].Tags = []string{"web"}
+
+	if err := cfg.MoveHost("WEB", "内网"); err != nil {
+		t.Fatalf("移动失败: %v", err)
+	}
+	if len(cfg.Groups[0].Hosts) != 3 || len(cfg.Groups[1].Hosts) != 2 {
+		t.Fatalf("主机数量错误: %d %d", len(cfg.Groups[0].Hosts), len(cfg.Groups[1].Hosts))
+	}
+	moved := cfg.Groups[1].Hosts[1]
+	if !moved.Favorite || len(moved.Tags) != 1 {
+		t.Errorf("收藏或标签丢失: %+v", moved)
+	}
+	// 原分组的默认跳板机写入主机
+	if len(moved.Jump) != 1 || moved.Jump[0] != "bastion" {
+		t.Errorf("跳板机应保留为 bastion, 得到 %v", moved.Jump)
+	}
+
+	// 显式配置了跳板机的主机保持不变
+	if err := cfg.MoveHost("inner", "生产环境"); err != nil {
+		t.Fatalf("移动失败: %v", err)
+	}
+	if inner := cfg.Groups[0].Hosts[3]; len(inner.Jump) != 1 || inner.Jump[0] != "db" {
+		t.Errorf("跳板机应保持为 db, 得到 %v", inner.Jump)
+	}
+	if err := cfg.ResolveJumps(); err != nil {
+		t.Fatalf("解析跳板机失败: %v", err)
+	}
+
+	if err := cfg.MoveHost("missing", "内网"); err == nil {
+		t.Error("移动不存在的主机应返回错误")
+	}
+	if err := cfg.MoveHost("db", "不存在"); err == nil {
+		t.Error("移动到不存在的分组应返回错误")
+	}
+}
+
+// 测试分组的添加、重命名、合并和排序
+func TestGroupOperations(t *testing.T) {
+	cfg := loadGroupTestConfig(t)
+
+	if err := cfg.AddGroup("测试", nil); err != nil {
+		t.Fatalf("添加失败: %v", err)
+	}
+	if err := cfg.AddGroup("测试", nil); err == nil {
+		t.Error("重复分组应返回错误")
+	}
+	if err := cfg.RenameGroup("测试", "内网"); err == nil {
+		t.Error("重命名为已有分组应返回错误")
+	}
+	if err := cfg.RenameGroup("测试", "预发"); err != nil || cfg.Groups[2].Name != "预发" {
+		t.Errorf("重命名失败: %v", err)
+	}
+
+	if err := cfg.MoveGroup("预发", 1); err != nil {
+		t.Fatalf("移动分组失败: %v", err)
+	}
+	if cfg.Groups[0].Name != "预发" || cfg.Groups[1].Name != "生产环境" {
+		t.Errorf("分组顺序错误: %s %s", cfg.Groups[0].Name, cfg.Groups[1].Name)
+	}
+	if err := cfg.ReorderGroups([]string{"内网", "生产环境"}); err != nil {
+		t.Fatalf("排序失败: %v", err)
+	}
+	if cfg.Groups[0].Name != "内网" || cfg.Groups[2].Name != "预发" {
+		t.Errorf("分组顺序错误: %v", cfg.Groups)
+	}
+
+	if err := cfg.MergeGroups("内网", "生产环境"); err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+	if len(cfg.Groups) != 2 || len(cfg.Groups[0].Hosts) != 5 {
+		t.Errorf("合并结果错误: %+v", cfg.Groups)
+	}
+	if err := cfg.ResolveJumps(); err != nil {
+		t.Errorf("合并后跳板机无效: %v", err)
+	}
+	if err := cfg.RemoveGroup("预发"); err != nil || len(cfg.Groups) != 1 {
+		t.Errorf("删除分组失败: %v", err)
+	}
+}
+
+// 测试未使用跳板机的主机移入有默认跳板机的分组
+func TestMoveHostDisablesInheritedJump(t *testing.T) {
+	cfg := loadGroupTestConfig(t)
+	if err := cfg.AddGroup("直连", nil); err != nil {
+		t.Fatalf("添加失败: %v", err)
+	}
+	if err := cfg.MoveHost("web", "直连"); err != nil {
+		t.Fatalf("移动失败: %v", err)
+	}
+	cfg.Groups[2].Hosts[0].Jump = nil
+	if err := cfg.MoveHost("web", "生产环境"); err != nil {
+		t.Fatalf("移动失败: %v", err)
+	}
+	if web := cfg.Groups[0].Hosts[3]; !web.Jump.IsNone() {
+		t.Errorf("应显式禁用跳板机, 得到 %v", web.Jump)
+	}
+}
+
+// 测试移动和合并后主机生效的连接参数不变，连接历史的记录键保持一致
+func TestMoveHostKeepsEndpoint(t *testing.T) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(defaultsTestConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+	before := map[string]models.Host{}
+	for _, host := range cfg.Groups[0].Hosts {
+		before[host.Name] = host
+	}
+	// 新分组的 Zmodem、连接方式和超时与原分组不同
+	disabled := false
+	cfg.Groups[1].ZmodemEnable = &disabled
+	cfg.Groups[1].ConnectMode = "external"
+	cfg.Groups[1].ConnectTimeout = 30
+
+	if err := cfg.MoveHost("web", "公网"); err != nil {
+		t.Fatalf("移动失败: %v", err)
+	}
+	if err := cfg.MergeGroups("生产环境", "公网"); err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	cfg, err = LoadConfig(path)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+
+	for _, host := range cfg.Groups[0].Hosts {
+		old, ok := before[host.Name]
+		if !ok {
+			continue
+		}
+		if history.Key(host) != history.Key(old) {
+			t.Errorf("%s 的历史记录键改变: %s -> %s", host.Name, history.Key(old), history.Key(host))
+		}
+		if host.AuthType != old.AuthType || host.KeyPath != old.KeyPath {
+			t.Errorf("%s 的认证方式改变: %s %s -> %s %s", host.Name, old.AuthType, old.KeyPath, host.AuthType, host.KeyPath)
+		}
+		if host.IsZmodemEnabled() != old.IsZmodemEnabled() || host.UsesExternalSSH() != old.UsesExternalSSH() || host.ConnectTimeout != old.ConnectTimeout {
+			t.Errorf("%s 的连接设置改变: %+v -> %+v", host.Name, old, host)
+		}
+	}
+	if len(before) != 3 || len(cfg.Groups[0].Hosts) != 4 {
+		t.Errorf("主机数量错误: %d", len(cfg.Groups[0].Hosts))
+	}
+}
@@ -0,0 +1,346 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 引入的配置文件，可写成单个字符串或列表，支持通配符，相对路径相对于主配置文件所在目录
+type Includes []string
+
+// 解析单个字符串或字符串列表
+func (in *Includes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*in = Includes{single}
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*in = list
+	return nil
+}
+
+// 引入文件中主机的个人设置，保存在主配置文件的 overrides 段，不写入共享的引入文件
+type HostOverride struct {
+	Favorite    *bool  `yaml:"favorite,omitempty"`
+	Password    string `yaml:"password,omitempty"`
+	PasswordRef string `yaml:"password_ref,omitempty"`
+}
+
+// 引入文件的内容：只读取分组
+type includeDoc struct {
+	Groups []models.Group `yaml:"groups"`
+}
+
+// 已加载的引入文件
+type includedFile struct {
+	path     string
+	original []byte
+	base     *yamlv3.Node
+	personal map[string]personalState // 文件中原有的个人设置（按主机名小写），保存时原样写回
+}
+
+// 主机的个人设置
+type personalState struct {
+	favorite    bool
+	password    string
+	passwordRef string
+}
+
+func personalOf(host models.Host) personalState {
+	return personalState{favorite: host.Favorite, password: host.Password, passwordRef: host.PasswordRef}
+}
+
+// 按 include 加载其他配置文件，分组追加到主配置之后
+func (c *Config) loadIncludes() error {
+	seen := map[string]bool{c.path: true}
+	for _, pattern := range c.Include {
+		paths, err := c.expandInclude(pattern)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			if err := c.loadInclude(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 展开 include 中的路径：不含通配符的路径必须存在，通配符未匹配到文件时忽略
+func (c *Config) expandInclude(pattern string) ([]string, error) {
+	pattern = fsutil.ExpandPath(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return nil, nil
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(c.Path()), pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("include 路径无效 %s: %v", pattern, err)
+	}
+	if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+		return nil, fmt.Errorf("引入的配置文件不存在: %s", pattern)
+	}
+	for i := range matches {
+		matches[i] = absPath(matches[i])
+	}
+	return matches, nil
+}
+
+// 加载单个引入文件，并用主配置中的 overrides 覆盖个人设置
+func (c *Config) loadInclude(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc includeDoc
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("解析 %s 失败: %v", path, err)
+	}
+
+	file := &includedFile{path: path, original: data, personal: map[string]personalState{}}
+	for i := range doc.Groups {
+		group := &doc.Groups[i]
+		group.Source = path
+		for j := range group.Hosts {
+			host := &group.Hosts[j]
+			file.personal[strings.ToLower(host.Name)] = personalOf(*host)
+			if override, ok := c.override(host.Name); ok {
+				if override.Favorite != nil {
+					host.Favorite = *override.Favorite
+				}
+				if override.Password != "" {
+					host.Password = override.Password
+				}
+				if override.PasswordRef != "" {
+					host.PasswordRef = override.PasswordRef
+				}
+			}
+		}
+	}
+	c.Groups = append(c.Groups, doc.Groups...)
+	c.includes = append(c.includes, file)
+	return nil
+}
+
+// 查找主机的个人设置（主机名忽略大小写）
+func (c *Config) override(name string) (HostOverride, bool) {
+	for key, override := range c.Overrides {
+		if strings.EqualFold(key, name) {
+			return override, true
+		}
+	}
+	return HostOverride{}, false
+}
+
+// 所有配置文件：主配置文件在前，其后是按 include 顺序加载的文件
+func (c *Config) Files() []string {
+	files := []string{c.Path()}
+	for _, file := range c.includes {
+		files = append(files, file.path)
+	}
+	return files
+}
+
+// 分组所在的配置文件，新建的分组保存在主配置文件中
+func (c *Config) sourceOf(group models.Group) string {
+	if group.Source == "" {
+		return c.Path()
+	}
+	return group.Source
+}
+
+// 同步主机的来源文件（主机随分组保存）
+func (c *Config) syncSources() {
+	for i := range c.Groups {
+		source := c.sourceOf(c.Groups[i])
+		c.Groups[i].Source = source
+		for j := range c.Groups[i].Hosts {
+			c.Groups[i].Hosts[j].Source = source
+		}
+	}
+}
+
+// 保存在指定文件中的分组
+func (c *Config) groupsIn(path string) []models.Group {
+	groups := []models.Group{}
+	for _, group := range c.Groups {
+		if c.sourceOf(group) == path {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// 主配置文件的内容
+func (c *Config) mainView() *Config {
+	view := *c
+	view.Groups = explicitGroups(c.groupsIn(c.Path()))
+	return &view
+}
+
+// 复制分组，主机只保留自己配置的字段（继承的默认值不写入文件）
+func explicitGroups(groups []models.Group) []models.Group {
+	for i := range groups {
+		hosts := make([]models.Host, len(groups[i].Hosts))
+		for j, host := range groups[i].Hosts {
+			hosts[j] = host.Explicit()
+		}
+		groups[i].Hosts = hosts
+	}
+	return groups
+}
+
+// 引入文件的内容：个人设置保持文件中原有的值
+func (c *Config) includeView(file *includedFile) *includeDoc {
+	doc := &includeDoc{Groups: explicitGroups(c.groupsIn(file.path))}
+	for i := range doc.Groups {
+		hosts := doc.Groups[i].Hosts
+		for j, host := range hosts {
+			state := file.personal[strings.ToLower(host.Name)]
+			host.Favorite, host.Password, host.PasswordRef = state.favorite, state.password, state.passwordRef
+			hosts[j] = host
+		}
+	}
+	return doc
+}
+
+// 主机改名后调用：引入文件中原名称下的个人设置改用新名称，保存时 overrides 随之改名
+func (c *Config) HostRenamed(oldName, newName string) {
+	old := strings.ToLower(oldName)
+	for _, file := range c.includes {
+		if state, ok := file.personal[old]; ok {
+			delete(file.personal, old)
+			file.personal[strings.ToLower(newName)] = state
+		}
+	}
+	if override, ok := c.override(oldName); ok {
+		c.setOverride(oldName, HostOverride{})
+		c.setOverride(newName, override)
+	}
+}
+
+// 将引入文件中主机的个人设置更新到 overrides，已删除或移出引入文件的主机不再保留
+func (c *Config) updateOverrides() {
+	for _, file := range c.includes {
+		present := map[string]bool{}
+		for _, group := range c.groupsIn(file.path) {
+			for _, host := range group.Hosts {
+				present[strings.ToLower(host.Name)] = true
+			}
+		}
+		for name := range file.personal {
+			if !present[name] {
+				c.setOverride(name, HostOverride{})
+			}
+		}
+	}
+
+	for _, file := range c.includes {
+		for _, group := range c.groupsIn(file.path) {
+			for _, host := range group.Hosts {
+				state := file.personal[strings.ToLower(host.Name)]
+				var override HostOverride
+				if host.Favorite != state.favorite {
+					favorite := host.Favorite
+					override.Favorite = &favorite
+				}
+				if host.Password != state.password {
+					override.Password = host.Password
+				}
+				if host.PasswordRef != state.passwordRef {
+					override.PasswordRef = host.PasswordRef
+				}
+				c.setOverride(host.Name, override)
+			}
+		}
+	}
+}
+
+func (c *Config) setOverride(name string, override HostOverride) {
+	for key := range c.Overrides {
+		if strings.EqualFold(key, name) {
+			delete(c.Overrides, key)
+		}
+	}
+	if override == (HostOverride{}) {
+		return
+	}
+	if c.Overrides == nil {
+		c.Overrides = map[string]HostOverride{}
+	}
+	c.Overrides[name] = override
+}
+
+// 写入有修改的引入文件，每个文件单独加锁
+func (c *Config) writeIncludes() error {
+	for _, file := range c.includes {
+		if err := c.writeInclude(file); err != nil {
+			return fmt.Errorf("保存 %s 失败: %w", file.path, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) writeInclude(file *includedFile) error {
+	view := c.includeView(file)
+	cur, err := encodeNode(view)
+	if err != nil {
+		return err
+	}
+	if file.base != nil && sameNode(file.base, cur) {
+		return nil
+	}
+
+	lock, err := fsutil.LockFile(file.path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	current, err := os.ReadFile(file.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if !bytes.Equal(current, file.original) {
+		return ErrModified
+	}
+	data, reformatted, err := encodeFile(view, cur, file.original, file.base, &c.Defaults)
+	if err != nil {
+		return err
+	}
+	if reformatted {
+		c.reformatted = append(c.reformatted, file.path)
+	}
+	if len(current) > 0 && !bytes.Equal(current, data) {
+		if err := backup(file.path, current); err != nil {
+			return fmt.Errorf("备份配置文件失败: %v", err)
+		}
+	}
+	if err := fsutil.WriteFile(file.path, data, 0600); err != nil {
+		return err
+	}
+	file.original, file.base = data, cur
+	return nil
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+const personalConfig = `include: conf.d/*.yaml
+defaults:
+  username: me
+groups:
+- name: 个人
+  hosts:
+  - name: lab
+    ip: 192.168.0.2
+overrides:
+  db:
+    favorite: true
+`
+
+const sharedConfig = `# 团队共享的主机清单
+groups:
+- name: 生产环境
+  port: 2222
+  hosts:
+  - name: web
+    ip: 10.0.0.1
+    tags: [prod]
+  - name: db
+    ip: 10.0.0.2
+  - name: cache
+    ip: 10.0.0.3  # 即将下线
+`
+
+// 写入主配置和引入的文件并加载
+func loadIncludeConfig(t *testing.T) (*Config, string, string) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config.yaml")
+	sharedPath := filepath.Join(dir, "conf.d", "team.yaml")
+	if err := os.MkdirAll(filepath.Dir(sharedPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(mainPath, []byte(personalConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sharedPath, []byte(sharedConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(mainPath)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+	return cfg, mainPath, sharedPath
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// 测试引入文件的加载：主机记录来源文件，个人设置来自主配置
+func TestLoadIncludes(t *testing.T) {
+	cfg, mainPath, sharedPath := loadIncludeConfig(t)
+
+	if files := cfg.Files(); len(files) != 2 || files[0] != mainPath || files[1] != sharedPath {
+		t.Fatalf("配置文件列表错误: %v", files)
+	}
+	if len(cfg.Groups) != 2 || cfg.Groups[0].Source != mainPath || cfg.Groups[1].Source != sharedPath {
+		t.Fatalf("分组来源错误: %+v", cfg.Groups)
+	}
+	db := cfg.Groups[1].Hosts[1]
+	if db.Source != sharedPath || !db.Favorite {
+		t.Errorf("主机来源或收藏状态错误: %+v", db)
+	}
+	// 引入的主机同样使用主配置的 defaults 和所在分组的默认值
+	if db.Username != "me" || db.Port != 2222 {
+		t.Errorf("默认值错误: %+v", db)
+	}
+}
+
+// 测试修改写回各自的文件，个人设置不写入共享文件
+func TestSaveIncludes(t *testing.T) {
+	cfg, mainPath, sharedPath := loadIncludeConfig(t)
+	shared := &cfg.Groups[1]
+	shared.Hosts[0].Favorite = true
+	shared.Hosts[0].PasswordRef = "pw-1"
+	shared.Hosts[0].IP = "10.0.0.10"
+	shared.Hosts[1].Favorite = false
+	shared.Hosts = shared.Hosts[:2]
+	cfg.Groups[0].Hosts = append(cfg.Groups[0].Hosts, models.Host{Name: "nas", IP: "192.168.0.3"})
+
+	if err := cfg.Save(mainPath); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+
+	wantShared := strings.Replace(sharedConfig, "ip: 10.0.0.1\n", "ip: 10.0.0.10\n", 1)
+	wantShared = strings.Replace(wantShared, "  - name: cache\n    ip: 10.0.0.3  # 即将下线\n", "", 1)
+	if got := readFile(t, sharedPath); got != wantShared {
+		t.Errorf("共享文件保存结果不符合预期:\n%s", got)
+	}
+
+	got := readFile(t, mainPath)
+	for _, want := range []string{
+		"include: conf.d/*.yaml\n",
+		"  - name: nas\n    ip: 192.168.0.3\n",
+		"  web:\n    favorite: true\n    password_ref: pw-1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("主配置缺少 %q:\n%s", want, got)
+		}
+	}
+	// 与共享文件一致的个人设置不再保留
+	if strings.Contains(got, "10.0.0.10") || strings.Contains(got, "db:") {
+		t.Errorf("共享主机不应写入主配置:\n%s", got)
+	}
+
+	reloaded, err := LoadConfig(mainPath)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	web := reloaded.Groups[1].Hosts[0]
+	if !web.Favorite || web.PasswordRef != "pw-1" || reloaded.Groups[1].Hosts[1].Favorite {
+		t.Errorf("个人设置未生效: %+v", reloaded.Groups[1].Hosts)
+	}
+
+	// 移入主配置分组的主机随分组写入主配置文件
+	if err := reloaded.MoveHost("web", "个人"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloaded.Save(mainPath); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if strings.Contains(readFile(t, sharedPath), "name: web") || !strings.Contains(readFile(t, mainPath), "ip: 10.0.0.10") {
+		t.Errorf("移动的主机未写入主配置")
+	}
+}
+
+// 测试删除或改名引入文件中的主机时同步 overrides
+func TestSaveIncludesRenameAndRemove(t *testing.T) {
+	cfg, mainPath, sharedPath := loadIncludeConfig(t)
+	shared := &cfg.Groups[1]
+	shared.Hosts[0].Favorite = true
+	if err := cfg.Save(mainPath); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+
+	cfg, err := LoadConfig(mainPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared = &cfg.Groups[1]
+	cfg.HostRenamed("web", "web-01")
+	shared.Hosts[0].Name = "web-01"
+	shared.Hosts = append(shared.Hosts[:1], shared.Hosts[2:]...) // 删除 db
+	if err := cfg.Save(mainPath); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+
+	got := readFile(t, mainPath)
+	if !strings.Contains(got, "  web-01:\n    favorite: true\n") || strings.Contains(got, "  web:") || strings.Contains(got, "  db:") {
+		t.Errorf("overrides 未随主机改名或删除:\n%s", got)
+	}
+	if !strings.Contains(readFile(t, sharedPath), "  - name: web-01\n    ip: 10.0.0.1\n    tags: [prod]\n") {
+		t.Errorf("共享文件中的主机未改名:\n%s", readFile(t, sharedPath))
+	}
+	reloaded, err := LoadConfig(mainPath)
+	if err != nil || !reloaded.Groups[1].Hosts[0].Favorite {
+		t.Errorf("改名后收藏应保留: %v", err)
+	}
+}
+
+// 测试跨文件的重复名称和引入文件中被忽略的内容
+func TestValidateIncludes(t *testing.T) {
+	_, mainPath, sharedPath := loadIncludeConfig(t)
+	data := sharedConfig + "ui_config:\n  theme: light\n"
+	data = strings.Replace(data, "name: cache", "name: LAB", 1)
+	if err := os.WriteFile(sharedPath, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(mainPath)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+
+	diagnostics := cfg.Validate()
+	if len(diagnostics) != 2 {
+		t.Fatalf("诊断数量错误: %v", diagnostics)
+	}
+	if d := diagnostics[0]; d.File != sharedPath || d.Line != 11 || !strings.Contains(d.Message, mainPath+":7") {
+		t.Errorf("重复主机诊断错误: %s", d)
+	}
+	if d := diagnostics[1]; d.File != sharedPath || d.Path != "ui_config" || d.Severity != SeverityWarning {
+		t.Errorf("忽略内容诊断错误: %s", d)
+	}
+}
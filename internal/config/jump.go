@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 解析所有主机的跳板机链，结果保存在 Host.Via；
+// 无法解析的主机把原因记录在 Host.JumpError，其他主机不受影响，返回遇到的第一个错误
+func (c *Config) ResolveJumps() error {
+	var first error
+	for i := range c.Groups {
+		for j := range c.Groups[i].Hosts {
+			host := &c.Groups[i].Hosts[j]
+			host.Via, host.JumpError = c.jumpChain(c.Groups[i], *host, nil)
+			if host.JumpError != nil && first == nil {
+				first = host.JumpError
+			}
+		}
+	}
+	return first
+}
+
+// 计算主机的跳板机链：第一跳自身的跳板机会被展开到链的最前面
+func (c *Config) jumpChain(group models.Group, host models.Host, visiting []string) ([]models.Host, error) {
+	for _, name := range visiting {
+		if strings.EqualFold(name, host.Name) {
+			return nil, fmt.Errorf("跳板机循环引用: %s → %s", strings.Join(visiting, " → "), host.Name)
+		}
+	}
+	visiting = append(visiting, host.Name)
+
+	names := c.effectiveJump(group, host)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var chain []models.Host
+	for i, name := range names {
+		if strings.EqualFold(name, host.Name) {
+			return nil, fmt.Errorf("主机 %s 不能把自己作为跳板机", host.Name)
+		}
+
+		hopGroup, hop, ok := c.lookupHost(name)
+		if !ok {
+			return nil, fmt.Errorf("主机 %s 的跳板机 %s 不存在", host.Name, name)
+		}
+
+		// 只有第一跳需要直连，它自己的跳板机链要先建立
+		if i == 0 {
+			prefix, err := c.jumpChain(hopGroup, hop, visiting)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, prefix...)
+		}
+
+		hop.Via, hop.JumpError = nil, nil
+		chain = append(chain, hop)
+	}
+	return chain, nil
+}
+
+// 主机配置优先，其次使用分组默认跳板机，最后使用全局默认跳板机
+func (c *Config) effectiveJump(group models.Group, host models.Host) []string {
+	if host.Jump.IsNone() {
+		return nil
+	}
+	if len(host.Jump) > 0 {
+		return host.Jump
+	}
+
+	inherited := group.Jump
+	if len(inherited) == 0 {
+		inherited = c.Defaults.Jump
+	}
+	if inherited.IsNone() {
+		return nil
+	}
+
+	// 跳板机本身也继承了该链时，只使用链中它前面的部分
+	for i, name := range inherited {
+		if strings.EqualFold(name, host.Name) {
+			return inherited[:i]
+		}
+	}
+	return inherited
+}
+
+// 按名称查找主机及其所在分组
+func (c *Config) lookupHost(name string) (models.Group, models.Host, bool) {
+	for _, group := range c.Groups {
+		for _, host := range group.Hosts {
+			if strings.EqualFold(host.Name, name) {
+				return group, host, true
+			}
+		}
+	}
+	return models.Group{}, models.Host{}, false
+}
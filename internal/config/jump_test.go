@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const jumpTestConfig = `
+groups:
+  - name: 生产环境
+    jump: bastion
+    hosts:
+      - name: bastion
+        ip: 10.0.0.1
+      - name: web
+        ip: 10.0.1.1
+      - name: db
+        ip: 10.0.1.2
+        jump: bastion, web
+      - name: public
+        ip: 1.2.3.4
+        jump: none
+  - name: 内网
+    hosts:
+      - name: inner
+        ip: 10.1.0.1
+        jump: [db]
+`
+
+// 测试跳板机链的解析
+func TestResolveJumps(t *testing.T) {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(jumpTestConfig), &cfg); err != nil {
+		t.Fatalf("解析配置失败: %v", err)
+	}
+	if err := cfg.ResolveJumps(); err != nil {
+		t.Fatalf("解析跳板机失败: %v", err)
+	}
+
+	expected := map[string]string{
+		"bastion": "",
+		"web":     "bastion",
+		"db":      "bastion → web",
+		"public":  "",
+		"inner":   "bastion → web → db", // 第一跳 db 自身的跳板机链会展开
+	}
+	for _, group := range cfg.Groups {
+		for _, host := range group.Hosts {
+			if got := host.ViaDescription(); got != expected[host.Name] {
+				t.Errorf("主机 %s 的跳板机链错误: 期望 %q，实际 %q", host.Name, expected[host.Name], got)
+			}
+		}
+	}
+}
+
+// 测试跳板机循环引用和不存在的跳板机：错误记录在对应主机上，不影响其他主机和加载
+func TestResolveJumpsErrors(t *testing.T) {
+	cases := map[string]string{
+		"循环引用": `
+groups:
+  - name: g
+    hosts:
+      - {name: a, ip: 1.1.1.1, jump: b}
+      - {name: b, ip: 1.1.1.2, jump: a}
+      - {name: ok, ip: 1.1.1.3}
+`,
+		"不存在": `
+groups:
+  - name: g
+    hosts:
+      - {name: a, ip: 1.1.1.1, jump: missing}
+      - {name: ok, ip: 1.1.1.3}
+`,
+	}
+
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	for name, content := range cases {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+			t.Fatalf("%s: 解析配置失败: %v", name, err)
+		}
+		err := cfg.ResolveJumps()
+		if err == nil || !strings.Contains(err.Error(), "跳板机") {
+			t.Errorf("%s: 期望返回跳板机错误，实际: %v", name, err)
+		}
+		hosts := cfg.Groups[0].Hosts
+		if hosts[0].JumpError == nil || hosts[len(hosts)-1].JumpError != nil {
+			t.Errorf("%s: 错误应只记录在出错的主机上: %v / %v", name, hosts[0].JumpError, hosts[len(hosts)-1].JumpError)
+		}
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadConfig(path)
+		if err != nil {
+			t.Errorf("%s: 跳板机错误不应导致加载失败: %v", name, err)
+			continue
+		}
+		if loaded.Groups[0].Hosts[0].JumpError == nil {
+			t.Errorf("%s: 加载后应记录主机的跳板机错误", name)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// 配置文件名
+const configFileName = "config.yaml"
+
+// 环境变量：指定配置文件路径
+const configEnv = "HOSTMANAGER_CONFIG"
+
+// 确定配置文件路径：--config > HOSTMANAGER_CONFIG > 已存在的候选文件 > XDG 默认路径
+func FindConfig(explicit string) string {
+	if explicit != "" {
+		return absPath(explicit)
+	}
+	if path := os.Getenv(configEnv); path != "" {
+		return absPath(path)
+	}
+	for _, path := range searchPaths() {
+		if _, err := os.Stat(path); err == nil {
+			return absPath(path)
+		}
+	}
+	return DefaultPath()
+}
+
+// 新建配置文件时使用的默认路径：$XDG_CONFIG_HOME/hostmanager/config.yaml
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return configFileName
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "hostmanager", configFileName)
+}
+
+// 配置文件查找优先级
+func searchPaths() []string {
+	paths := []string{
+		configFileName, // 当前目录
+		DefaultPath(),  // XDG 配置目录
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, configFileName)) // 用户家目录（兼容旧版本）
+	}
+	paths = append(paths, filepath.Join("/etc/hostmanager", configFileName)) // 系统配置目录
+
+	// 程序所在目录
+	if execPath, err := os.Executable(); err == nil {
+		paths = append(paths, filepath.Join(filepath.Dir(execPath), configFileName))
+	}
+	return paths
+}
+
+// 转为绝对路径，避免工作目录变化后写到别处
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
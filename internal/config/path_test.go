@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 测试配置文件路径的优先级
+func TestFindConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+	t.Setenv(configEnv, "")
+	t.Chdir(dir)
+
+	xdgPath := filepath.Join(dir, "xdg", "hostmanager", "config.yaml")
+	if path := FindConfig(""); path != xdgPath {
+		t.Errorf("没有配置文件时应使用 XDG 路径, 得到 %s", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(xdgPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(xdgPath, []byte("groups: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if path := FindConfig(""); path != xdgPath {
+		t.Errorf("应找到 XDG 配置文件, 得到 %s", path)
+	}
+
+	// 当前目录优先于 XDG
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("groups: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if path := FindConfig(""); path != filepath.Join(dir, "config.yaml") {
+		t.Errorf("应优先使用当前目录的配置文件, 得到 %s", path)
+	}
+
+	t.Setenv(configEnv, "env.yaml")
+	if path := FindConfig(""); path != filepath.Join(dir, "env.yaml") {
+		t.Errorf("应使用环境变量指定的路径, 得到 %s", path)
+	}
+	if path := FindConfig("/tmp/flag.yaml"); path != "/tmp/flag.yaml" {
+		t.Errorf("--config 优先级最高, 得到 %s", path)
+	}
+}
+
+// 测试保存时写回加载的文件
+func TestConfigPathRoundTrip(t *testing.T) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "hm.yaml")
+	if err := os.WriteFile(path, []byte("groups:\n  - name: a\n    hosts: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+	if cfg.Path() != path {
+		t.Errorf("路径错误: %s", cfg.Path())
+	}
+	cfg.Groups[0].Name = "b"
+	if err := SaveConfig(cfg.Path(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadConfig(path)
+	if err != nil || reloaded.Groups[0].Name != "b" {
+		t.Errorf("保存后重新加载错误: %v %+v", err, reloaded)
+	}
+}
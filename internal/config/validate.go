@@ -0,0 +1,379 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 诊断级别
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // 配置错误，config lint 返回非零退出码
+	SeverityWarning Severity = "warning" // 可能导致连接失败，但不影响使用
+)
+
+// 配置检查结果
+type Diagnostic struct {
+	Severity Severity `json:"severity" yaml:"severity"`
+	File     string   `json:"file" yaml:"file"`
+	Line     int      `json:"line" yaml:"line"` // 0 表示无法确定位置
+	Path     string   `json:"path" yaml:"path"` // 配置项路径，如 groups[0].hosts[1].port
+	Message  string   `json:"message" yaml:"message"`
+}
+
+// 格式化为 文件:行号: 级别: 信息
+func (d Diagnostic) String() string {
+	level := "警告"
+	if d.Severity == SeverityError {
+		level = "错误"
+	}
+	location := d.File
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d", d.File, d.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", location, level, d.Message, d.Path)
+}
+
+// 是否包含错误级别的诊断
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// 支持的取值
+var (
+	validAuthTypes    = []string{"key", "password"}
+	validConnectModes = []string{"", "native", "external"}
+	validThemes       = []string{"dark", "light", "high-contrast"}
+	validLanguages    = []string{"zh", "en"}
+	validLayouts      = []string{"single", "columns"}
+)
+
+// 检查配置，返回按文件和行号排序的诊断信息
+func (c *Config) Validate() []Diagnostic {
+	v := &validator{locators: map[string]locator{c.Path(): newLocator(c.original)}}
+	for _, file := range c.includes {
+		v.locators[file.path] = newLocator(file.original)
+	}
+	v.use(c.Path())
+	v.checkDefaults(c.Defaults, []interface{}{"defaults"})
+	v.checkIncludes(c)
+
+	groupLines := map[string]position{}
+	hostLines := map[string]position{}
+	groupIndex := map[string]int{} // 分组在所在文件中的序号
+	for _, group := range c.Groups {
+		source := c.sourceOf(group)
+		v.use(source)
+		groupPath := []interface{}{"groups", groupIndex[source]}
+		groupIndex[source]++
+		if strings.TrimSpace(group.Name) == "" {
+			v.add(SeverityError, append(groupPath, "name"), "分组名称为空")
+		} else if pos, ok := groupLines[strings.ToLower(group.Name)]; ok {
+			v.add(SeverityError, append(groupPath, "name"), "分组名称重复: %s%s", group.Name, v.definedAt(pos))
+		} else {
+			groupLines[strings.ToLower(group.Name)] = v.position(append(groupPath, "name"))
+		}
+		v.checkDefaults(group.HostDefaults, groupPath)
+
+		for j, host := range group.Hosts {
+			hostPath := append(groupPath, "hosts", j)
+			field := func(name string) []interface{} {
+				return append(append([]interface{}{}, hostPath...), name)
+			}
+
+			if strings.TrimSpace(host.Name) == "" {
+				v.add(SeverityError, field("name"), "主机名称为空")
+			} else if pos, ok := hostLines[strings.ToLower(host.Name)]; ok {
+				v.add(SeverityError, field("name"), "主机名称重复: %s%s", host.Name, v.definedAt(pos))
+			} else {
+				hostLines[strings.ToLower(host.Name)] = v.position(field("name"))
+			}
+			if strings.TrimSpace(host.IP) == "" {
+				v.add(SeverityError, field("ip"), "主机 %s 未配置地址", host.Name)
+			}
+			// 继承自默认值的字段在 defaults 处检查，不在每台主机上重复报告
+			if v.explicit(field("port")) && (host.Port < 1 || host.Port > 65535) {
+				v.add(SeverityError, field("port"), "主机 %s 的端口无效: %d（应为 1-65535）", host.Name, host.Port)
+			}
+			if v.explicit(field("connect_mode")) && !contains(validConnectModes, host.ConnectMode) {
+				v.add(SeverityError, field("connect_mode"), "主机 %s 的连接方式无效: %s（可选 native、external）", host.Name, host.ConnectMode)
+			}
+
+			forwardNames := map[string]bool{}
+			for k, forward := range host.Forwards {
+				forwardPath := append(append([]interface{}{}, hostPath...), "forwards", k)
+				if err := forward.Validate(); err != nil {
+					v.add(SeverityError, forwardPath, "主机 %s 的端口转发无效: %v", host.Name, err)
+				}
+				if name := strings.ToLower(forward.Name); name != "" {
+					if forwardNames[name] {
+						v.add(SeverityError, append(forwardPath, "name"), "主机 %s 的端口转发名称重复: %s", host.Name, forward.Name)
+					}
+					forwardNames[name] = true
+				}
+			}
+
+			if _, err := c.jumpChain(group, host, nil); err != nil {
+				v.checkJump(c, source, err, field("jump"), append(groupPath, "jump"), host.Jump, group.Jump)
+			}
+
+			switch host.AuthType {
+			case "key":
+				if v.explicit(field("key_path")) && host.KeyPath != "" && !fileExists(host.KeyPath) {
+					v.add(SeverityWarning, field("key_path"), "主机 %s 的私钥文件不存在: %s", host.Name, host.KeyPath)
+				}
+			case "password":
+				if host.Password == "" && host.PasswordRef == "" {
+					v.add(SeverityWarning, field("password"), "主机 %s 使用密码认证但未配置密码，连接时需要手动输入", host.Name)
+				}
+			default:
+				if v.explicit(field("auth_type")) {
+					v.add(SeverityError, field("auth_type"), "主机 %s 的认证方式无效: %s（可选 %s）", host.Name, host.AuthType, strings.Join(validAuthTypes, "、"))
+				}
+			}
+		}
+	}
+
+	v.use(c.Path())
+	ui := c.UIConfig
+	if !contains(validThemes, ui.Theme) {
+		v.add(SeverityError, []interface{}{"ui_config", "theme"}, "未知的主题: %s（可选 %s）", ui.Theme, strings.Join(validThemes, "、"))
+	}
+	if !contains(validLanguages, ui.Language) {
+		v.add(SeverityWarning, []interface{}{"ui_config", "language"}, "未知的语言: %s（可选 %s），将使用中文", ui.Language, strings.Join(validLanguages, "、"))
+	}
+	if !contains(validLayouts, ui.Layout.Type) {
+		v.add(SeverityWarning, []interface{}{"ui_config", "layout", "type"}, "未知的布局: %s（可选 %s）", ui.Layout.Type, strings.Join(validLayouts, "、"))
+	}
+
+	rank := map[string]int{}
+	for i, file := range c.Files() {
+		rank[file] = i
+	}
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		a, b := v.diagnostics[i], v.diagnostics[j]
+		if a.File != b.File {
+			return rank[a.File] < rank[b.File]
+		}
+		return a.Line < b.Line
+	})
+	return v.diagnostics
+}
+
+// 收集诊断信息
+type validator struct {
+	file        string // 当前检查的文件
+	locator     locator
+	locators    map[string]locator // 各配置文件的行号定位
+	diagnostics []Diagnostic
+}
+
+// 配置项在文件中的位置
+type position struct {
+	file string
+	line int
+}
+
+// 切换到指定文件，之后的诊断信息都属于该文件
+func (v *validator) use(file string) {
+	v.file, v.locator = file, v.locators[file]
+}
+
+func (v *validator) position(path []interface{}) position {
+	return position{file: v.file, line: v.line(path)}
+}
+
+// 已定义位置的说明，位于其他文件时带上文件名
+func (v *validator) definedAt(pos position) string {
+	switch {
+	case pos.file != v.file && pos.line > 0:
+		return fmt.Sprintf("（%s:%d 已定义）", pos.file, pos.line)
+	case pos.file != v.file:
+		return fmt.Sprintf("（%s 中已定义）", pos.file)
+	case pos.line > 0:
+		return fmt.Sprintf("（第 %d 行已定义）", pos.line)
+	}
+	return ""
+}
+
+// 检查引入文件：只读取 groups，其他内容会被忽略；overrides 中的主机应存在于引入文件中
+func (v *validator) checkIncludes(c *Config) {
+	for _, file := range c.includes {
+		root := v.locators[file.path].root
+		if root == nil || root.Kind != yaml.MappingNode {
+			continue
+		}
+		v.use(file.path)
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if key := root.Content[i].Value; key != "groups" {
+				v.add(SeverityWarning, []interface{}{key}, "引入的文件只读取 groups，%s 将被忽略", key)
+			}
+		}
+	}
+
+	v.use(c.Path())
+	for name := range c.Overrides {
+		found := false
+		for _, file := range c.includes {
+			for _, group := range c.groupsIn(file.path) {
+				for _, host := range group.Hosts {
+					found = found || strings.EqualFold(host.Name, name)
+				}
+			}
+		}
+		if !found {
+			v.add(SeverityWarning, []interface{}{"overrides", name}, "overrides 中的主机 %s 不在引入的文件中", name)
+		}
+	}
+}
+
+func (v *validator) line(path []interface{}) int {
+	return v.locator.line(path...)
+}
+
+// 字段是否直接写在该位置（而不是继承的默认值）；没有原文件时视为直接设置
+func (v *validator) explicit(path []interface{}) bool {
+	return v.locator.root == nil || v.locator.has(path...)
+}
+
+// 检查全局或分组的默认值
+func (v *validator) checkDefaults(d models.HostDefaults, base []interface{}) {
+	field := func(name string) []interface{} {
+		return append(append([]interface{}{}, base...), name)
+	}
+	if d.Port != 0 && (d.Port < 1 || d.Port > 65535) {
+		v.add(SeverityError, field("port"), "默认端口无效: %d（应为 1-65535）", d.Port)
+	}
+	if d.AuthType != "" && !contains(validAuthTypes, d.AuthType) {
+		v.add(SeverityError, field("auth_type"), "默认认证方式无效: %s（可选 %s）", d.AuthType, strings.Join(validAuthTypes, "、"))
+	}
+	if !contains(validConnectModes, d.ConnectMode) {
+		v.add(SeverityError, field("connect_mode"), "默认连接方式无效: %s（可选 native、external）", d.ConnectMode)
+	}
+	if d.ConnectTimeout < 0 {
+		v.add(SeverityError, field("connect_timeout"), "连接超时无效: %d", d.ConnectTimeout)
+	}
+	if d.KeyPath != "" && !fileExists(d.KeyPath) {
+		v.add(SeverityWarning, field("key_path"), "默认私钥文件不存在: %s", d.KeyPath)
+	}
+}
+
+// 报告跳板机错误：定位到主机自己的 jump，未配置时定位到继承的分组或全局 jump
+func (v *validator) checkJump(c *Config, source string, err error, hostPath, groupPath []interface{}, hostJump, groupJump models.JumpChain) {
+	switch {
+	case len(hostJump) > 0 || len(groupJump) == 0 && len(c.Defaults.Jump) == 0:
+		v.add(SeverityError, hostPath, "%v", err)
+	case len(groupJump) > 0:
+		v.add(SeverityError, groupPath, "%v", err)
+	default:
+		v.use(c.Path())
+		v.add(SeverityError, []interface{}{"defaults", "jump"}, "%v", err)
+		v.use(source)
+	}
+}
+
+func (v *validator) add(severity Severity, path []interface{}, format string, args ...interface{}) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		Severity: severity,
+		File:     v.file,
+		Line:     v.line(path),
+		Path:     formatPath(path),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// 配置项路径，如 groups[0].hosts[1].port
+func formatPath(path []interface{}) string {
+	var b strings.Builder
+	for _, p := range path {
+		switch p := p.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", p)
+		default:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			fmt.Fprint(&b, p)
+		}
+	}
+	return b.String()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// 文件是否存在（路径支持 ~ 和环境变量）
+func fileExists(path string) bool {
+	_, err := os.Stat(fsutil.ExpandPath(path))
+	return err == nil
+}
+
+// 根据配置项路径查找在文件中的行号
+type locator struct {
+	root *yaml.Node
+}
+
+func newLocator(data []byte) locator {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return locator{}
+	}
+	return locator{root: doc.Content[0]}
+}
+
+// 路径存在时返回键所在行，否则返回最近的上级节点所在行
+func (l locator) line(path ...interface{}) int {
+	line, _ := l.find(path)
+	return line
+}
+
+// 路径是否存在于文件中
+func (l locator) has(path ...interface{}) bool {
+	_, found := l.find(path)
+	return found
+}
+
+func (l locator) find(path []interface{}) (int, bool) {
+	node := l.root
+	if node == nil {
+		return 0, false
+	}
+	line := node.Line
+	for _, p := range path {
+		node = resolveAlias(node)
+		switch p := p.(type) {
+		case string:
+			i := mappingIndex(node, p)
+			if node.Kind != yaml.MappingNode || i < 0 {
+				return line, false
+			}
+			line, node = node.Content[i].Line, node.Content[i+1]
+		case int:
+			if node.Kind != yaml.SequenceNode || p >= len(node.Content) {
+				return line, false
+			}
+			node = node.Content[p]
+			line = node.Line
+		}
+	}
+	return line, true
+}
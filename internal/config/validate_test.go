@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const invalidConfig = `groups:
+- name: 生产环境
+  hosts:
+  - name: web
+    ip: 10.0.0.1
+    port: 70000
+    username: admin
+    auth_type: token
+  - name: WEB
+    ip: 10.0.0.2
+    username: admin
+    auth_type: password
+  - name: db
+    ip: 10.0.0.3
+    username: admin
+    auth_type: key
+    key_path: /nonexistent/id_rsa
+    connect_mode: telnet
+ui_config:
+  theme: solarized
+`
+
+// 测试配置检查的诊断信息和行号
+func TestValidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(invalidConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+
+	expected := []struct {
+		severity Severity
+		line     int
+		path     string
+	}{
+		{SeverityError, 6, "groups[0].hosts[0].port"},
+		{SeverityError, 8, "groups[0].hosts[0].auth_type"},
+		{SeverityError, 9, "groups[0].hosts[1].name"},
+		{SeverityWarning, 9, "groups[0].hosts[1].password"}, // 缺少的字段定位到主机所在行
+		{SeverityWarning, 17, "groups[0].hosts[2].key_path"},
+		{SeverityError, 18, "groups[0].hosts[2].connect_mode"},
+		{SeverityError, 20, "ui_config.theme"},
+	}
+	diagnostics := cfg.Validate()
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("诊断数量错误: %d\n%v", len(diagnostics), diagnostics)
+	}
+	for i, e := range expected {
+		d := diagnostics[i]
+		if d.Severity != e.severity || d.Line != e.line || d.Path != e.path || d.File != path {
+			t.Errorf("第 %d 条诊断错误: %s", i, d)
+		}
+	}
+	if !HasErrors(diagnostics) {
+		t.Error("应包含错误")
+	}
+
+	// 合法配置没有错误
+	cfg.Groups[0].Hosts = cfg.Groups[0].Hosts[:1]
+	cfg.Groups[0].Hosts[0].Port = 22
+	cfg.Groups[0].Hosts[0].AuthType = "password"
+	cfg.Groups[0].Hosts[0].PasswordRef = "pw-1"
+	cfg.UIConfig.Theme = "light"
+	if diagnostics := cfg.Validate(); len(diagnostics) != 0 {
+		t.Errorf("不应有诊断信息: %v", diagnostics)
+	}
+}
+
+const jumpErrorConfig = `defaults:
+  jump: gone
+groups:
+- name: 生产环境
+  jump: missing
+  hosts:
+  - name: web
+    ip: 10.0.0.1
+    jump: none
+  - name: db
+    ip: 10.0.0.2
+- name: 测试
+  hosts:
+  - name: a
+    ip: 10.0.1.1
+    jump: b
+  - name: b
+    ip: 10.0.1.2
+    jump: a
+  - name: c
+    ip: 10.0.1.3
+`
+
+// 测试跳板机不存在和循环引用的诊断定位
+func TestValidateJumps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(jumpErrorConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("跳板机错误不应导致加载失败: %v", err)
+	}
+
+	expected := []struct {
+		line int
+		path string
+	}{
+		{2, "defaults.jump"}, // c 继承全局跳板机
+		{5, "groups[0].jump"},
+		{16, "groups[1].hosts[0].jump"},
+		{19, "groups[1].hosts[1].jump"},
+	}
+	diagnostics := cfg.Validate()
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("诊断数量错误: %d\n%v", len(diagnostics), diagnostics)
+	}
+	for i, e := range expected {
+		d := diagnostics[i]
+		if d.Severity != SeverityError || d.Line != e.line || d.Path != e.path || !strings.Contains(d.Message, "跳板机") {
+			t.Errorf("第 %d 条诊断错误: %s", i, d)
+		}
+	}
+}
@@ -0,0 +1,857 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 配置文件的增量修改：比较加载时与保存时的配置，只改写发生变化的字段，
+// 保留注释、键顺序和锚点。优先直接修改原文中受影响的行，
+// 无法直接修改时（流式写法、别名等）改写 yaml.v3 节点树后重新输出。
+
+// 原文件无法按节点修改
+var errNotPatchable = errors.New("配置文件结构无法增量修改")
+
+// 将配置编码为节点树
+func encodeNode(v interface{}) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// 生成主配置文件的内容：写回原文件时只改写变化的字段，其余情况整体序列化；
+// reformatted 表示原文件无法增量修改，注释和键顺序没有保留
+func (c *Config) encode(filePath string) (data []byte, cur *yaml.Node, reformatted bool, err error) {
+	view := c.mainView()
+	cur, err = encodeNode(view)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if absPath(filePath) != c.Path() {
+		data, err := yamlv2.Marshal(view)
+		return data, cur, false, err
+	}
+	data, reformatted, err = encodeFile(view, cur, c.original, c.base, nil)
+	return data, cur, reformatted, err
+}
+
+// 生成单个配置文件的内容；inherit 为引入文件继承的全局默认值，主配置文件为 nil。
+// 已有的文件无法增量修改时整体序列化，reformatted 为 true
+func encodeFile(view interface{}, cur *yaml.Node, original []byte, base *yaml.Node, inherit *models.HostDefaults) (data []byte, reformatted bool, err error) {
+	if base != nil && len(original) > 0 {
+		if sameNode(base, cur) {
+			return original, false, nil
+		}
+		if data := patchFile(view, cur, original, base, inherit); data != nil {
+			return data, false, nil
+		}
+		reformatted = true
+	}
+	data, err = yamlv2.Marshal(view)
+	return data, reformatted, err
+}
+
+// 在原文件上应用修改，结果解析后必须与当前配置一致，否则返回 nil
+func patchFile(view interface{}, cur *yaml.Node, original []byte, base *yaml.Node, inherit *models.HostDefaults) []byte {
+	candidates, err := patchYAML(original, base, cur)
+	if err != nil {
+		return nil
+	}
+	current, err := yamlv2.Marshal(view)
+	if err != nil {
+		return nil
+	}
+	want, err := normalize(current, inherit)
+	if err != nil {
+		return nil
+	}
+	for _, data := range candidates {
+		if got, err := normalize(data, inherit); err == nil && bytes.Equal(got, want) {
+			return data
+		}
+	}
+	return nil
+}
+
+// 解析配置并补齐默认值后重新序列化，用于比较两份配置是否等价
+// 引入文件只比较分组，主机默认值使用主配置文件中的 defaults
+func normalize(data []byte, inherit *models.HostDefaults) ([]byte, error) {
+	var config Config
+	if err := yamlv2.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	if inherit != nil {
+		config = Config{Defaults: *inherit, Groups: config.Groups}
+	}
+	applyDefaults(&config)
+	return yamlv2.Marshal(&config)
+}
+
+// 将 base→cur 的变化应用到原文，返回候选结果（直接修改原文的结果在前）
+func patchYAML(data []byte, base, cur *yaml.Node) ([][]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode ||
+		base.Kind != yaml.MappingNode || cur.Kind != yaml.MappingNode {
+		return nil, errNotPatchable
+	}
+
+	e := newYAMLEditor(data, doc.Content[0])
+	e.mergeMapping(doc.Content[0], base, cur)
+
+	var candidates [][]byte
+	if !e.reformat {
+		candidates = append(candidates, e.apply())
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(e.indent)
+	if err := encoder.Encode(&doc); err != nil {
+		return candidates, nil
+	}
+	encoder.Close()
+	return append(candidates, buf.Bytes()), nil
+}
+
+// 两个节点的值是否相同（别名按其指向的节点比较）
+func sameNode(a, b *yaml.Node) bool {
+	a, b = resolveAlias(a), resolveAlias(b)
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+	if a.Kind == yaml.ScalarNode {
+		return a.ShortTag() == b.ShortTag() && a.Value == b.Value
+	}
+	for i := range a.Content {
+		if !sameNode(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+// 映射中键的位置，不存在返回 -1
+func mappingIndex(mapping *yaml.Node, key string) int {
+	if mapping == nil {
+		return -1
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// 只保留 cur 中与 base 不同的字段
+func diffMapping(base, cur *yaml.Node) *yaml.Node {
+	diff := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(cur.Content); i += 2 {
+		key, value := cur.Content[i], cur.Content[i+1]
+		if bi := mappingIndex(base, key.Value); bi >= 0 {
+			baseValue := base.Content[bi+1]
+			if sameNode(baseValue, value) {
+				continue
+			}
+			if baseValue.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+				value = diffMapping(baseValue, value)
+			}
+		}
+		diff.Content = append(diff.Content, key, value)
+	}
+	return diff
+}
+
+// 空值（null、{}、[]）
+func isEmptyNode(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	case yaml.ScalarNode:
+		return node.ShortTag() == "!!null"
+	}
+	return false
+}
+
+// 原文修改
+type patch struct {
+	start, end int
+	text       string
+	seq        int
+}
+
+// 同时修改节点树和原文：原文无法表达的修改只作用于节点树，并标记需要重新输出
+type yamlEditor struct {
+	data       []byte
+	starts     []int  // 每行起始偏移，最后一项为文件末尾
+	deleted    []bool // 要删除的行
+	patches    []patch
+	reformat   bool // 原文无法直接修改，需要重新输出节点树
+	noEOL      bool // 原文末尾没有换行
+	indent     int  // 映射缩进
+	compactSeq bool // 列表项与上级键对齐（"- " 不额外缩进）
+}
+
+func newYAMLEditor(data []byte, root *yaml.Node) *yamlEditor {
+	noEOL := !bytes.HasSuffix(data, []byte("\n"))
+	if noEOL {
+		data = append(append([]byte{}, data...), '\n')
+	}
+	e := &yamlEditor{data: data, starts: []int{0}, noEOL: noEOL, indent: 2, compactSeq: true}
+	for i, b := range data {
+		if b == '\n' {
+			e.starts = append(e.starts, i+1)
+		}
+	}
+	e.deleted = make([]bool, len(e.starts))
+	e.detectStyle(root)
+	return e
+}
+
+// 沿用原文件的缩进风格
+func (e *yamlEditor) detectStyle(root *yaml.Node) {
+	foundIndent, foundSeq := false, false
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode && node.Style&yaml.FlowStyle == 0 {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if value.Style&yaml.FlowStyle == 0 && len(value.Content) > 0 {
+					switch {
+					case value.Kind == yaml.MappingNode && !foundIndent && value.Column > key.Column:
+						e.indent, foundIndent = value.Column-key.Column, true
+					case value.Kind == yaml.SequenceNode && !foundSeq:
+						e.compactSeq, foundSeq = value.Column == key.Column, true
+					}
+				}
+			}
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(root)
+}
+
+func (e *yamlEditor) lineCount() int {
+	return len(e.starts) - 1
+}
+
+// 第 i 行（从 0 开始）的内容，不含换行
+func (e *yamlEditor) line(i int) string {
+	return strings.TrimRight(string(e.data[e.starts[i]:e.starts[i+1]]), "\r\n")
+}
+
+// 节点位置对应的字节偏移（列号按字符计）
+func (e *yamlEditor) offset(node *yaml.Node) int {
+	start := e.starts[node.Line-1]
+	offset := start
+	for column := 1; column < node.Column && offset < e.starts[node.Line]; column++ {
+		_, size := utf8.DecodeRune(e.data[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// 节点是否来自原文（新插入的节点没有位置）
+func (e *yamlEditor) located(node *yaml.Node) bool {
+	return node.Line > 0 && node.Line <= e.lineCount()
+}
+
+// 从 start 行开始、缩进为 col 的块的最后一行（不含末尾的空行和注释）
+func (e *yamlEditor) blockEnd(start, col int, dashes bool) int {
+	last := start
+	for i := start + 1; i < e.lineCount(); i++ {
+		line := e.line(i)
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		switch {
+		case trimmed == "", strings.HasPrefix(trimmed, "#"):
+			continue
+		case indent > col, dashes && indent == col && (trimmed == "-" || strings.HasPrefix(trimmed, "- ")):
+			last = i
+		default:
+			return last
+		}
+	}
+	return last
+}
+
+// 映射项所在的行范围
+func (e *yamlEditor) entryLines(mapping *yaml.Node, index int) (int, int) {
+	key, value := mapping.Content[index], mapping.Content[index+1]
+	col := key.Column - 1
+	dashes := value.Kind == yaml.SequenceNode && value.Style&yaml.FlowStyle == 0 && value.Column == key.Column
+	return key.Line - 1, e.blockEnd(key.Line-1, col, dashes)
+}
+
+// 列表项所在的行范围；"-" 与内容不在同一行时无法确定
+func (e *yamlEditor) itemLines(seq, item *yaml.Node) (int, int, bool) {
+	start, col := item.Line-1, seq.Column-1
+	line := e.line(start)
+	if col >= len(line) || line[col] != '-' {
+		return 0, 0, false
+	}
+	return start, e.blockEnd(start, col, false), true
+}
+
+func (e *yamlEditor) replace(start, end int, text string) {
+	e.patches = append(e.patches, patch{start: start, end: end, text: text, seq: len(e.patches)})
+}
+
+func (e *yamlEditor) insertLines(line int, lines []string) {
+	offset := e.starts[line]
+	e.replace(offset, offset, strings.Join(lines, "\n")+"\n")
+}
+
+func (e *yamlEditor) deleteLines(from, to int) {
+	for i := from; i <= to; i++ {
+		e.deleted[i] = true
+	}
+}
+
+// 生成修改后的原文
+func (e *yamlEditor) apply() []byte {
+	patches := append([]patch{}, e.patches...)
+	for i := 0; i < e.lineCount(); i++ {
+		if !e.deleted[i] {
+			continue
+		}
+		j := i
+		for j+1 < e.lineCount() && e.deleted[j+1] {
+			j++
+		}
+		patches = append(patches, patch{start: e.starts[i], end: e.starts[j+1], seq: len(patches)})
+		i = j
+	}
+	sort.SliceStable(patches, func(i, j int) bool {
+		if patches[i].start != patches[j].start {
+			return patches[i].start < patches[j].start
+		}
+		return patches[i].seq < patches[j].seq
+	})
+
+	var buf bytes.Buffer
+	cursor := 0
+	for _, p := range patches {
+		if p.start > cursor {
+			buf.Write(e.data[cursor:p.start])
+		}
+		buf.WriteString(p.text)
+		if p.end > cursor {
+			cursor = p.end
+		}
+	}
+	buf.Write(e.data[cursor:])
+	if e.noEOL {
+		return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	}
+	return buf.Bytes()
+}
+
+// 合并映射：修改已有的键，插入新增的键，删除被清空的键
+func (e *yamlEditor) mergeMapping(file, base, cur *yaml.Node) {
+	block := file.Style&yaml.FlowStyle == 0 && len(file.Content) > 0 && e.located(file.Content[0])
+	if !block {
+		e.reformat = true
+	}
+
+	insertAt, line := 0, 0 // 新增的键插在上一个已有键之后
+	if block {
+		line = file.Content[0].Line - 1
+	}
+	for i := 0; i+1 < len(cur.Content); i += 2 {
+		key, value := cur.Content[i], cur.Content[i+1]
+		var baseValue *yaml.Node
+		if bi := mappingIndex(base, key.Value); bi >= 0 {
+			baseValue = base.Content[bi+1]
+		}
+
+		if fi := mappingIndex(file, key.Value); fi >= 0 {
+			if block && e.located(file.Content[fi]) {
+				_, end := e.entryLines(file, fi)
+				line = end + 1
+			}
+			e.mergeEntry(file, fi, baseValue, value)
+			insertAt = fi + 2
+			continue
+		}
+		if baseValue != nil && sameNode(baseValue, value) {
+			continue // 默认值或来自合并键的值，未修改
+		}
+		if baseValue != nil && baseValue.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			value = diffMapping(baseValue, value)
+		}
+		if block && !e.reformat {
+			e.insertLines(line, e.renderEntry(key, value, file.Column-1))
+		}
+		file.Content = append(file.Content[:insertAt], append([]*yaml.Node{key, value}, file.Content[insertAt:]...)...)
+		insertAt += 2
+	}
+
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		name := base.Content[i].Value
+		if mappingIndex(cur, name) >= 0 {
+			continue
+		}
+		if fi := mappingIndex(file, name); fi >= 0 {
+			if block && !e.reformat && e.located(file.Content[fi]) {
+				e.deleteLines(e.entryLines(file, fi))
+			}
+			file.Content = append(file.Content[:fi], file.Content[fi+2:]...)
+		}
+	}
+}
+
+// 合并映射中已有的键，无法原地修改时整项替换
+func (e *yamlEditor) mergeEntry(file *yaml.Node, index int, base, cur *yaml.Node) {
+	value := file.Content[index+1]
+	if base != nil && sameNode(base, cur) {
+		return
+	}
+	if e.mergeValue(value, base, cur) {
+		return
+	}
+
+	if isEmptyNode(value) && base != nil && base.Kind == yaml.MappingNode && cur.Kind == yaml.MappingNode {
+		cur = diffMapping(base, cur)
+	}
+	if !e.reformat && e.located(file.Content[index]) {
+		start, end := e.entryLines(file, index)
+		e.deleteLines(start, end)
+		e.insertLines(start, e.renderEntry(file.Content[index], cur, file.Content[index].Column-1))
+	}
+	cur.HeadComment, cur.LineComment, cur.FootComment = value.HeadComment, value.LineComment, value.FootComment
+	file.Content[index+1] = cur
+}
+
+// 原地合并节点，类型不同或无法修改时返回 false
+func (e *yamlEditor) mergeValue(file, base, cur *yaml.Node) bool {
+	if base != nil && sameNode(base, cur) {
+		return true
+	}
+	if file.Kind == yaml.AliasNode || file.Kind != cur.Kind || isEmptyNode(cur) || isEmptyNode(file) {
+		return false
+	}
+	if base != nil && base.Kind != cur.Kind {
+		base = nil
+	}
+
+	switch cur.Kind {
+	case yaml.ScalarNode:
+		return e.replaceScalar(file, cur)
+	case yaml.MappingNode:
+		if file.Style&yaml.FlowStyle != 0 {
+			return false
+		}
+		if base == nil {
+			base = &yaml.Node{Kind: yaml.MappingNode}
+		}
+		e.mergeMapping(file, base, cur)
+		return true
+	case yaml.SequenceNode:
+		if file.Style&yaml.FlowStyle != 0 {
+			return e.replaceFlow(file, cur)
+		}
+		if base == nil || len(base.Content) != len(file.Content) {
+			return false
+		}
+		e.mergeSequence(file, base, cur)
+		return true
+	}
+	return false
+}
+
+// 原地替换单行标量，保留行尾注释和原有引号风格
+func (e *yamlEditor) replaceScalar(file, cur *yaml.Node) bool {
+	style := cur.Style
+	if style == 0 && cur.ShortTag() == "!!str" && !strings.Contains(cur.Value, "\n") {
+		style = file.Style & (yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle)
+	}
+	if !e.reformat {
+		start, end, ok := e.scalarSpan(file)
+		if !ok {
+			return false
+		}
+		text := renderNode(&yaml.Node{Kind: yaml.ScalarNode, Tag: cur.Tag, Value: cur.Value, Style: style})
+		if strings.Contains(text, "\n") {
+			return false
+		}
+		e.replace(start, end, text)
+	}
+	file.Tag, file.Value, file.Style = cur.Tag, cur.Value, style
+	return true
+}
+
+// 单行标量在原文中的范围
+func (e *yamlEditor) scalarSpan(node *yaml.Node) (int, int, bool) {
+	if !e.located(node) {
+		return 0, 0, false
+	}
+	start := e.offset(node)
+	text := e.data[start:e.starts[node.Line]]
+	switch node.Style {
+	case 0:
+		if node.Value == "" || !bytes.HasPrefix(text, []byte(node.Value)) {
+			return 0, 0, false
+		}
+		rest := text[len(node.Value):]
+		if len(rest) > 0 && rest[0] != ' ' && rest[0] != '\t' && rest[0] != '\r' && rest[0] != '\n' {
+			return 0, 0, false
+		}
+		return start, start + len(node.Value), true
+	case yaml.DoubleQuotedStyle:
+		for i := 1; i < len(text) && len(text) > 0 && text[0] == '"'; i++ {
+			switch text[i] {
+			case '\\':
+				i++
+			case '"':
+				return start, start + i + 1, true
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := 1; i < len(text) && len(text) > 0 && text[0] == '\''; i++ {
+			if text[i] == '\'' {
+				if i+1 < len(text) && text[i+1] == '\'' {
+					i++
+					continue
+				}
+				return start, start + i + 1, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// 替换流式列表（如 tags: [a, b]），只支持标量元素
+func (e *yamlEditor) replaceFlow(file, cur *yaml.Node) bool {
+	var items []string
+	for _, item := range cur.Content {
+		if item.Kind != yaml.ScalarNode {
+			return false
+		}
+		items = append(items, renderNode(item))
+	}
+	if !e.reformat {
+		if !e.located(file) {
+			return false
+		}
+		start := e.offset(file)
+		end := flowEnd(e.data, start)
+		if end < 0 {
+			return false
+		}
+		e.replace(start, end, "["+strings.Join(items, ", ")+"]")
+	}
+	file.Content = cur.Content
+	return true
+}
+
+// 流式集合的结束位置（匹配括号，跳过引号内的内容）
+func flowEnd(data []byte, start int) int {
+	if start >= len(data) || data[start] != '[' {
+		return -1
+	}
+	depth := 0
+	for i := start; i < len(data); i++ {
+		switch data[i] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '"':
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+		case '\'':
+			for i++; i < len(data) && data[i] != '\''; i++ {
+			}
+		case '#':
+			if i > 0 && (data[i-1] == ' ' || data[i-1] == '\t') {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+// 列表项的标识：有 name 字段的按名称匹配，标量按值匹配
+func itemID(node *yaml.Node) string {
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.MappingNode:
+		if i := mappingIndex(node, "name"); i >= 0 {
+			return "name:" + node.Content[i+1].Value
+		}
+	case yaml.ScalarNode:
+		return "value:" + node.Value
+	}
+	return ""
+}
+
+// 合并块列表：按标识匹配原有的项，删除、新增或调整顺序
+func (e *yamlEditor) mergeSequence(file, base, cur *yaml.Node) {
+	match := make([]int, len(cur.Content))
+	used := make([]bool, len(base.Content))
+	curIDs := map[string]bool{}
+	for j, item := range cur.Content {
+		match[j] = -1
+		curIDs[itemID(item)] = true
+	}
+	for j, item := range cur.Content {
+		id := itemID(item)
+		if id == "" {
+			continue
+		}
+		for i, baseItem := range base.Content {
+			if !used[i] && itemID(baseItem) == id {
+				match[j], used[i] = i, true
+				break
+			}
+		}
+	}
+	// 未匹配的项按位置对应（例如重命名）
+	for j := range cur.Content {
+		if match[j] == -1 && j < len(base.Content) && !used[j] && (itemID(base.Content[j]) == "" || !curIDs[itemID(base.Content[j])]) {
+			match[j], used[j] = j, true
+		}
+	}
+
+	ordered := true
+	for j, last := 0, -1; j < len(cur.Content); j++ {
+		if match[j] >= 0 {
+			if match[j] < last {
+				ordered = false
+			}
+			last = match[j]
+		}
+	}
+
+	if !e.reformat {
+		e.patchSequence(file, base, cur, match, used, ordered)
+	}
+
+	content := make([]*yaml.Node, len(cur.Content))
+	for j, item := range cur.Content {
+		if i := match[j]; i >= 0 {
+			if !e.mergeValue(file.Content[i], base.Content[i], item) {
+				content[j] = item
+				continue
+			}
+			content[j] = file.Content[i]
+		} else {
+			content[j] = item
+		}
+	}
+	file.Content = content
+}
+
+// 在原文中修改块列表
+func (e *yamlEditor) patchSequence(file, base, cur *yaml.Node, match []int, used []bool, ordered bool) {
+	n := len(file.Content)
+	starts, ends, heads := make([]int, n), make([]int, n), make([]int, n)
+	for i, item := range file.Content {
+		if !e.located(item) {
+			e.reformat = true
+			return
+		}
+		start, end, ok := e.itemLines(file, item)
+		if !ok {
+			e.reformat = true
+			return
+		}
+		starts[i], ends[i], heads[i] = start, end, start
+		if i > 0 {
+			// 项前的注释属于该项，空行属于分隔
+			heads[i] = ends[i-1] + 1
+			for heads[i] < start && strings.TrimSpace(e.line(heads[i])) == "" {
+				heads[i]++
+			}
+		}
+	}
+	col := file.Column - 1
+	separator := 0
+	if n > 1 {
+		separator = heads[1] - ends[0] - 1
+	}
+	blank := make([]string, separator)
+
+	if !ordered {
+		// 调整顺序：只移动未修改的项，按新顺序重新拼接
+		var chunks []string
+		for j, item := range cur.Content {
+			i := match[j]
+			if i >= 0 && !sameNode(base.Content[i], item) {
+				e.reformat = true
+				return
+			}
+			var lines []string
+			if i >= 0 {
+				for l := heads[i]; l <= ends[i]; l++ {
+					lines = append(lines, e.line(l))
+				}
+			} else {
+				lines = e.renderItem(item, col)
+			}
+			if len(chunks) > 0 {
+				chunks = append(chunks, blank...)
+			}
+			chunks = append(chunks, lines...)
+		}
+		e.deleteLines(starts[0], ends[n-1])
+		e.insertLines(starts[0], chunks)
+		return
+	}
+
+	// 删除未保留的项
+	for i := range file.Content {
+		if used[i] {
+			continue
+		}
+		switch {
+		case i > 0:
+			e.deleteLines(ends[i-1]+1, ends[i])
+		case n > 1:
+			e.deleteLines(starts[0], heads[1]-1)
+		default:
+			e.deleteLines(starts[0], ends[0])
+		}
+	}
+
+	// 修改或替换保留的项，新增的项插在前一项之后
+	line, after := starts[0], false
+	for j, item := range cur.Content {
+		i := match[j]
+		if i < 0 {
+			lines := e.renderItem(item, col)
+			if after {
+				lines = append(append([]string{}, blank...), lines...)
+			} else {
+				lines = append(lines, blank...)
+			}
+			e.insertLines(line, lines)
+			continue
+		}
+		if !sameNode(base.Content[i], item) && !canMergeItem(file.Content[i], base.Content[i], item) {
+			e.deleteLines(starts[i], ends[i])
+			e.insertLines(starts[i], e.renderItem(item, col))
+		}
+		line, after = ends[i]+1, true
+	}
+}
+
+// 列表项能否原地合并（与 mergeValue 的判断保持一致）
+func canMergeItem(file, base, cur *yaml.Node) bool {
+	if file.Kind == yaml.AliasNode || file.Kind != cur.Kind || isEmptyNode(cur) || isEmptyNode(file) {
+		return false
+	}
+	switch cur.Kind {
+	case yaml.ScalarNode:
+		return file.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0
+	case yaml.MappingNode:
+		return file.Style&yaml.FlowStyle == 0
+	case yaml.SequenceNode:
+		return file.Style&yaml.FlowStyle != 0 || (base.Kind == cur.Kind && len(base.Content) == len(file.Content))
+	}
+	return false
+}
+
+// 输出单个节点（标量或空集合），多行时后续行相对缩进
+func renderNode(node *yaml.Node) string {
+	bare := *node
+	bare.HeadComment, bare.LineComment, bare.FootComment = "", "", ""
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&bare); err != nil {
+		return "null"
+	}
+	encoder.Close()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// 输出映射项（块写法）
+func (e *yamlEditor) renderEntry(key, value *yaml.Node, col int) []string {
+	prefix := strings.Repeat(" ", col) + renderNode(key) + ":"
+	value = resolveAlias(value)
+	if value.Kind == yaml.MappingNode && len(value.Content) > 0 {
+		return append([]string{prefix}, e.renderMapping(value, col+e.indent)...)
+	}
+	if value.Kind == yaml.SequenceNode && len(value.Content) > 0 {
+		itemCol := col + e.indent
+		if e.compactSeq {
+			itemCol = col
+		}
+		var lines []string
+		for _, item := range value.Content {
+			lines = append(lines, e.renderItem(item, itemCol)...)
+		}
+		return append([]string{prefix}, lines...)
+	}
+	return renderInline(prefix+" ", value, col)
+}
+
+func (e *yamlEditor) renderMapping(mapping *yaml.Node, col int) []string {
+	var lines []string
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		lines = append(lines, e.renderEntry(mapping.Content[i], mapping.Content[i+1], col)...)
+	}
+	return lines
+}
+
+// 输出列表项，"-" 位于 col 列
+func (e *yamlEditor) renderItem(item *yaml.Node, col int) []string {
+	item = resolveAlias(item)
+	var lines []string
+	switch {
+	case item.Kind == yaml.MappingNode && len(item.Content) > 0:
+		lines = e.renderMapping(item, col+2)
+	case item.Kind == yaml.SequenceNode && len(item.Content) > 0:
+		for _, child := range item.Content {
+			lines = append(lines, e.renderItem(child, col+2)...)
+		}
+	default:
+		lines = renderInline("", item, col+2)
+	}
+	lines[0] = strings.Repeat(" ", col) + "- " + strings.TrimLeft(lines[0], " ")
+	return lines
+}
+
+// 标量或空集合跟在 prefix 之后，多行内容缩进到 col 之后
+func renderInline(prefix string, node *yaml.Node, col int) []string {
+	lines := strings.Split(renderNode(node), "\n")
+	lines[0] = prefix + lines[0]
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = strings.Repeat(" ", col) + lines[i]
+		}
+	}
+	return lines
+}
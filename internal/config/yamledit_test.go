@@ -0,0 +1,206 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+const commentedConfig = `# 主机配置
+groups:
+- name: 生产环境
+  # 分组说明
+  hosts:
+  - name: web
+    ip: 10.0.0.1
+    port: 22
+    username: admin
+    auth_type: key
+    key_path: &key ~/.ssh/id_rsa
+    tags: [prod, web]  # 流式标签
+    favorite: false
+  - name: db
+    ip: 10.0.0.2
+    port: 22
+    username: admin
+    auth_type: key
+    key_path: *key
+    # zmodem_enable: false  # 取消注释以禁用
+    description: "主数据库"
+
+- name: 开发环境
+  hosts:
+  - name: dev
+    ip: 10.0.1.1
+    port: 22
+    username: dev
+    auth_type: key
+    key_path: ~/.ssh/id_rsa
+
+ui_config:
+  theme: dark  # 可选: dark, light
+`
+
+// 写入测试配置并加载
+func loadCommentedConfig(t *testing.T) (*Config, string) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(commentedConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+	return cfg, path
+}
+
+// 保存并返回文件内容，同时检查重新加载后与内存中的配置一致
+func saveAndRead(t *testing.T, cfg *Config, path string) string {
+	t.Helper()
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v\n%s", err, data)
+	}
+	if !sameNode(reloaded.base, cfg.base) {
+		t.Errorf("重新加载后的配置不一致:\n%s", data)
+	}
+	return string(data)
+}
+
+// 测试修改单个字段只改写对应的行
+func TestSavePreservesComments(t *testing.T) {
+	cfg, path := loadCommentedConfig(t)
+	cfg.Groups[0].Hosts[0].Favorite = true
+	cfg.UIConfig.Theme = "light"
+
+	got := saveAndRead(t, cfg, path)
+	want := strings.Replace(commentedConfig, "    favorite: false", "    favorite: true", 1)
+	want = strings.Replace(want, "theme: dark  #", "theme: light  #", 1)
+	if got != want {
+		t.Errorf("保存结果不符合预期:\n%s", got)
+	}
+
+	// 未修改时文件保持不变
+	if again := saveAndRead(t, cfg, path); again != want {
+		t.Errorf("未修改时不应改写文件:\n%s", again)
+	}
+}
+
+// 测试新增、删除字段和主机时保留注释与锚点
+func TestSaveStructuralChanges(t *testing.T) {
+	cfg, path := loadCommentedConfig(t)
+	db := &cfg.Groups[0].Hosts[1]
+	db.Favorite = true
+	db.Description = ""
+	db.AddTags("db")
+	cfg.Groups[0].Hosts[0].RemoveTags("web")
+	cfg.Groups[1].Hosts = append(cfg.Groups[1].Hosts, models.Host{
+		Name: "dev2", IP: "10.0.1.2", Port: 22, Username: "dev", AuthType: "password",
+	})
+	cfg.UIConfig.Layout.Type = "columns"
+
+	got := saveAndRead(t, cfg, path)
+	for _, keep := range []string{
+		"# 主机配置", "  # 分组说明", "    # zmodem_enable: false  # 取消注释以禁用",
+		"key_path: &key ~/.ssh/id_rsa", "key_path: *key", "  theme: dark  # 可选: dark, light",
+		"    tags: [prod]  # 流式标签",
+	} {
+		if !strings.Contains(got, keep) {
+			t.Errorf("缺少 %q:\n%s", keep, got)
+		}
+	}
+	if strings.Contains(got, "主数据库") {
+		t.Errorf("清空的字段应删除:\n%s", got)
+	}
+	if !strings.Contains(got, "  - name: dev2\n    ip: 10.0.1.2\n") {
+		t.Errorf("新主机应沿用原有缩进:\n%s", got)
+	}
+	// 只写入修改过的界面配置，不展开默认值
+	if !strings.Contains(got, "  layout:\n    type: columns\n") || strings.Contains(got, "key_bindings") {
+		t.Errorf("界面配置写入错误:\n%s", got)
+	}
+}
+
+// 测试删除主机和调整分组顺序
+func TestSaveRemoveAndReorder(t *testing.T) {
+	cfg, path := loadCommentedConfig(t)
+	cfg.Groups[0].Hosts = cfg.Groups[0].Hosts[:1]
+	if err := cfg.ReorderGroups([]string{"开发环境"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := saveAndRead(t, cfg, path)
+	if strings.Contains(got, "name: db") {
+		t.Errorf("主机未删除:\n%s", got)
+	}
+	if strings.Index(got, "开发环境") > strings.Index(got, "生产环境") {
+		t.Errorf("分组顺序未调整:\n%s", got)
+	}
+	if !strings.Contains(got, "  # 分组说明") || !strings.HasPrefix(got, "# 主机配置\n") {
+		t.Errorf("注释丢失:\n%s", got)
+	}
+}
+
+// 测试无法增量修改时整体重新生成文件，并报告该文件且保留备份
+func TestSaveReportsReformat(t *testing.T) {
+	t.Setenv("HOSTMANAGER_BACKUP_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := `# 公共设置
+common: &common
+  username: admin
+groups:
+- name: 生产环境
+  hosts:
+  - <<: *common
+    name: web
+    ip: 10.0.0.1
+`
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+
+	// 增量修改时不报告
+	cfg.Groups[0].Hosts[0].Favorite = true
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if len(cfg.Reformatted()) != 0 {
+		t.Errorf("增量修改不应报告重新生成: %v", cfg.Reformatted())
+	}
+
+	// 合并键提供的字段无法在原文中删除，只能重新生成
+	web := &cfg.Groups[0].Hosts[0]
+	web.Username, web.Inherited.Username = "", ""
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if got := cfg.Reformatted(); len(got) != 1 || got[0] != absPath(path) {
+		t.Errorf("应报告重新生成的文件, 得到 %v", got)
+	}
+	backups, err := ListBackups(path)
+	if err != nil || len(backups) != 2 {
+		t.Fatalf("重新生成前应已备份: %d %v", len(backups), err)
+	}
+	data, err := os.ReadFile(backups[0].Path)
+	if err != nil || !strings.Contains(string(data), "# 公共设置") {
+		t.Errorf("备份应包含原有注释: %s %v", data, err)
+	}
+	if !strings.Contains(ReformatWarning(path), "config restore") {
+		t.Error("警告中应提示如何恢复")
+	}
+}
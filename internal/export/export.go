@@ -0,0 +1,293 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 支持的导出格式
+var Formats = []string{"ssh-config", "ansible-ini", "ansible-yaml", "csv", "json"}
+
+// 导出记录（JSON/CSV 使用的稳定结构，不包含密码）
+type HostRecord struct {
+	Group       string   `json:"group" yaml:"group"`
+	Name        string   `json:"name" yaml:"name"`
+	IP          string   `json:"ip" yaml:"ip"`
+	Port        int      `json:"port" yaml:"port"`
+	Username    string   `json:"username" yaml:"username"`
+	AuthType    string   `json:"auth_type" yaml:"auth_type"`
+	KeyPath     string   `json:"key_path,omitempty" yaml:"key_path,omitempty"`
+	Tags        []string `json:"tags" yaml:"tags"`
+	Favorite    bool     `json:"favorite" yaml:"favorite"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Jump        []string `json:"jump,omitempty" yaml:"jump,omitempty"` // 解析后的跳板机链
+}
+
+// 按指定格式导出所有分组
+func Write(w io.Writer, format string, groups []models.Group) error {
+	switch format {
+	case "ssh-config":
+		return writeSSHConfig(w, groups)
+	case "ansible-ini":
+		return writeAnsibleINI(w, groups)
+	case "ansible-yaml":
+		return writeAnsibleYAML(w, groups)
+	case "csv":
+		return writeCSV(w, groups)
+	case "json":
+		return writeJSON(w, groups)
+	default:
+		return fmt.Errorf("不支持的导出格式: %s (支持: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// 生成导出记录
+func NewHostRecord(group string, host models.Host) HostRecord {
+	record := HostRecord{
+		Group:       group,
+		Name:        host.Name,
+		IP:          host.IP,
+		Port:        host.Port,
+		Username:    host.Username,
+		AuthType:    host.AuthType,
+		Tags:        host.Tags,
+		Favorite:    host.Favorite,
+		Description: host.Description,
+	}
+	if host.AuthType == "key" {
+		record.KeyPath = host.KeyPath
+	}
+	if record.Tags == nil {
+		record.Tags = []string{}
+	}
+	for _, hop := range host.Via {
+		record.Jump = append(record.Jump, hop.Name)
+	}
+	return record
+}
+
+// OpenSSH 客户端配置；只导出部分主机时，被依赖但未导出的跳板机补充在最后，保证 ProxyJump 的别名可用
+func writeSSHConfig(w io.Writer, groups []models.Group) error {
+	fmt.Fprintf(w, "# 由 hostmanager export 生成\n")
+	for _, group := range groups {
+		fmt.Fprintf(w, "\n# 分组: %s\n", group.Name)
+		for _, host := range group.Hosts {
+			writeSSHHost(w, host)
+		}
+	}
+	if hops := missingHops(groups); len(hops) > 0 {
+		fmt.Fprintf(w, "\n# 跳板机（被以上主机使用）\n")
+		for _, hop := range hops {
+			writeSSHHost(w, hop)
+		}
+	}
+	return nil
+}
+
+// 单台主机的 Host 配置块
+func writeSSHHost(w io.Writer, host models.Host) {
+	if host.Description != "" {
+		fmt.Fprintf(w, "# %s\n", host.Description)
+	}
+	if len(host.Tags) > 0 {
+		fmt.Fprintf(w, "# tags: %s\n", strings.Join(host.Tags, ", "))
+	}
+	fmt.Fprintf(w, "Host %s\n", HostAlias(host.Name))
+	fmt.Fprintf(w, "    HostName %s\n", host.IP)
+	fmt.Fprintf(w, "    User %s\n", host.Username)
+	fmt.Fprintf(w, "    Port %d\n", host.Port)
+	if host.AuthType == "key" && host.KeyPath != "" {
+		fmt.Fprintf(w, "    IdentityFile %s\n", quoteIfNeeded(host.KeyPath))
+	}
+	if len(host.Via) > 0 {
+		fmt.Fprintf(w, "    ProxyJump %s\n", strings.Join(viaAliases(host), ","))
+	}
+}
+
+// 跳板机链中引用但不在导出范围内的主机（按首次出现的顺序）
+func missingHops(groups []models.Group) []models.Host {
+	seen := map[string]bool{}
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			seen[strings.ToLower(host.Name)] = true
+		}
+	}
+	var hops []models.Host
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			for _, hop := range host.Via {
+				if key := strings.ToLower(hop.Name); !seen[key] {
+					seen[key] = true
+					hops = append(hops, hop)
+				}
+			}
+		}
+	}
+	return hops
+}
+
+// Ansible INI 清单：分组 -> Ansible 组，标签 -> host_tags 变量
+func writeAnsibleINI(w io.Writer, groups []models.Group) error {
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "[%s]\n", AnsibleGroupName(group.Name))
+		for _, host := range group.Hosts {
+			var fields []string
+			for _, v := range ansibleVars(host) {
+				fields = append(fields, fmt.Sprintf("%s=%s", v.Key, iniValue(v.Value)))
+			}
+			fmt.Fprintf(w, "%s %s\n", HostAlias(host.Name), strings.Join(fields, " "))
+		}
+	}
+	return nil
+}
+
+// Ansible YAML 清单
+func writeAnsibleYAML(w io.Writer, groups []models.Group) error {
+	children := yaml.MapSlice{}
+	for _, group := range groups {
+		hosts := yaml.MapSlice{}
+		for _, host := range group.Hosts {
+			hosts = append(hosts, yaml.MapItem{Key: HostAlias(host.Name), Value: ansibleVars(host)})
+		}
+		children = append(children, yaml.MapItem{
+			Key:   AnsibleGroupName(group.Name),
+			Value: yaml.MapSlice{{Key: "hosts", Value: hosts}},
+		})
+	}
+
+	inventory := yaml.MapSlice{{Key: "all", Value: yaml.MapSlice{{Key: "children", Value: children}}}}
+	data, err := yaml.Marshal(inventory)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// CSV，每行一台主机
+func writeCSV(w io.Writer, groups []models.Group) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"group", "name", "ip", "port", "username", "auth_type", "key_path", "tags", "favorite", "description", "jump"})
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			r := NewHostRecord(group.Name, host)
+			writer.Write([]string{
+				r.Group, r.Name, r.IP, strconv.Itoa(r.Port), r.Username, r.AuthType, r.KeyPath,
+				strings.Join(r.Tags, ";"), strconv.FormatBool(r.Favorite), r.Description, strings.Join(r.Jump, ";"),
+			})
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// JSON 数组
+func writeJSON(w io.Writer, groups []models.Group) error {
+	records := []HostRecord{}
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			records = append(records, NewHostRecord(group.Name, host))
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// 主机的 Ansible 变量（保持固定顺序）
+func ansibleVars(host models.Host) yaml.MapSlice {
+	vars := yaml.MapSlice{
+		{Key: "ansible_host", Value: host.IP},
+		{Key: "ansible_port", Value: host.Port},
+		{Key: "ansible_user", Value: host.Username},
+	}
+	if host.AuthType == "key" && host.KeyPath != "" {
+		vars = append(vars, yaml.MapItem{Key: "ansible_ssh_private_key_file", Value: host.KeyPath})
+	}
+	if len(host.Via) > 0 {
+		vars = append(vars, yaml.MapItem{Key: "ansible_ssh_common_args", Value: "-o ProxyJump=" + proxyJumpSpec(host)})
+	}
+	if len(host.Tags) > 0 {
+		vars = append(vars, yaml.MapItem{Key: "host_tags", Value: host.Tags})
+	}
+	if host.Description != "" {
+		vars = append(vars, yaml.MapItem{Key: "description", Value: host.Description})
+	}
+	return vars
+}
+
+// INI 变量值：列表写成 JSON，含空白的字符串加引号
+func iniValue(value interface{}) string {
+	switch v := value.(type) {
+	case []string:
+		data, _ := json.Marshal(v)
+		return "'" + string(data) + "'"
+	case string:
+		if strings.ContainsAny(v, " \t'\"") {
+			return strconv.Quote(v)
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// 跳板机链对应的 ssh_config 别名
+func viaAliases(host models.Host) []string {
+	aliases := make([]string, len(host.Via))
+	for i, hop := range host.Via {
+		aliases[i] = HostAlias(hop.Name)
+	}
+	return aliases
+}
+
+// 跳板机链的 user@host:port 形式（Ansible 不依赖 ssh_config 别名）
+func proxyJumpSpec(host models.Host) string {
+	hops := make([]string, len(host.Via))
+	for i, hop := range host.Via {
+		hops[i] = fmt.Sprintf("%s@%s:%d", hop.Username, hop.IP, hop.Port)
+	}
+	return strings.Join(hops, ",")
+}
+
+// 主机名称转换为 ssh_config / Ansible 可用的别名（空白替换为 -）
+func HostAlias(name string) string {
+	return strings.Join(strings.Fields(name), "-")
+}
+
+// 分组名称转换为合法的 Ansible 组名（字母、数字、下划线）
+func AnsibleGroupName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	result := strings.Trim(b.String(), "_")
+	if result == "" || unicode.IsDigit([]rune(result)[0]) {
+		result = "group_" + result
+	}
+	return result
+}
+
+// 路径包含空白时加引号
+func quoteIfNeeded(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return strconv.Quote(value)
+	}
+	return value
+}
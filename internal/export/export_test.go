@@ -0,0 +1,89 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 创建测试用的分组
+func createTestGroups() []models.Group {
+	bastion := models.Host{Name: "bastion", IP: "10.0.0.1", Port: 22, Username: "ops", AuthType: "key"}
+	return []models.Group{
+		{
+			Name: "生产 环境",
+			Hosts: []models.Host{
+				bastion,
+				{
+					Name: "web 01", IP: "10.0.1.1", Port: 2222, Username: "deploy",
+					AuthType: "password", Password: "secret", Tags: []string{"web"},
+					Via: []models.Host{bastion},
+				},
+			},
+		},
+	}
+}
+
+// 测试 ssh_config 导出
+func TestWriteSSHConfig(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "ssh-config", createTestGroups()); err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{"Host web-01\n", "    Port 2222\n", "    ProxyJump bastion\n"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("导出内容缺少 %q:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "secret") {
+		t.Error("导出内容不应包含密码")
+	}
+
+	// 只导出依赖跳板机的主机时，跳板机也要写入
+	groups := createTestGroups()
+	groups[0].Hosts = groups[0].Hosts[1:]
+	buf.Reset()
+	if err := Write(&buf, "ssh-config", groups); err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Host bastion\n    HostName 10.0.0.1\n") || strings.Count(out, "Host bastion\n") != 1 {
+		t.Errorf("应补充被依赖的跳板机:\n%s", out)
+	}
+}
+
+// 测试 Ansible INI 导出
+func TestWriteAnsibleINI(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "ansible-ini", createTestGroups()); err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "[生产_环境]\n") {
+		t.Errorf("Ansible 组名错误:\n%s", out)
+	}
+	if !strings.Contains(out, `host_tags='["web"]'`) || !strings.Contains(out, "ProxyJump=ops@10.0.0.1:22") {
+		t.Errorf("Ansible 主机变量错误:\n%s", out)
+	}
+}
+
+// 测试 JSON 导出结构
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "json", createTestGroups()); err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+
+	var records []HostRecord
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("解析JSON失败: %v", err)
+	}
+	if len(records) != 2 || records[1].Jump[0] != "bastion" || records[0].Tags == nil {
+		t.Errorf("JSON 导出内容错误: %+v", records)
+	}
+}
@@ -0,0 +1,117 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// 测试原子写入
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "file.yaml")
+	if err := WriteFile(path, []byte("a"), 0600); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if err := WriteFile(path, []byte("b"), 0600); err != nil {
+		t.Fatalf("覆盖失败: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "b" {
+		t.Errorf("内容错误: %q %v", data, err)
+	}
+	info, _ := os.Stat(path)
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("权限错误: %v", info.Mode())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("不应残留临时文件: %v", entries)
+	}
+}
+
+// 测试写入符号链接指向的文件并保留原有权限
+func TestWriteFileSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 创建符号链接需要权限")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "shared", "hosts.yaml")
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "hosts.yaml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(link, []byte("b"), 0600); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("符号链接不应被替换为普通文件: %v", err)
+	}
+	data, _ := os.ReadFile(target)
+	info, _ := os.Stat(target)
+	if string(data) != "b" || info.Mode().Perm() != 0644 {
+		t.Errorf("应写入链接指向的文件并保留权限: %q %v", data, info.Mode())
+	}
+}
+
+// 测试文件锁互斥
+func TestLockFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 不支持 flock")
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	lock, err := LockFile(path)
+	if err != nil {
+		t.Fatalf("加锁失败: %v", err)
+	}
+
+	file, err := os.OpenFile(path+".lock", os.O_RDWR, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if locked, _ := tryLock(file); locked {
+		t.Error("已加锁的文件不应再次获得锁")
+	}
+
+	lock.Unlock()
+	if locked, err := tryLock(file); !locked || err != nil {
+		t.Errorf("释放后应能加锁: %v", err)
+	}
+	unlock(file)
+
+	// 目录不存在时自动创建
+	nested, err := LockFile(filepath.Join(t.TempDir(), "missing", "x"))
+	if err != nil {
+		t.Fatalf("加锁失败: %v", err)
+	}
+	nested.Unlock()
+}
+
+// 测试路径展开
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("无法获取家目录")
+	}
+	t.Setenv("HM_KEYS", "/keys")
+	cases := map[string]string{
+		"~/.ssh/id_rsa":   filepath.Join(home, ".ssh/id_rsa"),
+		"$HM_KEYS/id_rsa": "/keys/id_rsa",
+		"${HM_KEYS}/a":    "/keys/a",
+		"/etc/ssh/key":    "/etc/ssh/key",
+		"~other/.ssh/key": "~other/.ssh/key",
+	}
+	for input, want := range cases {
+		if got := ExpandPath(input); got != want {
+			t.Errorf("ExpandPath(%q) = %q, 期望 %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 等待文件锁的最长时间
+const lockTimeout = 5 * time.Second
+
+// 文件被其他进程锁定
+var ErrLocked = errors.New("文件正被其他 hostmanager 进程修改")
+
+// 进程间的建议锁（锁文件为 path + ".lock"）
+type Lock struct {
+	file *os.File
+}
+
+// 获取 path 对应的排他锁，超时返回 ErrLocked
+func LockFile(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if locked {
+			return &Lock{file: file}, nil
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// 释放锁
+func (l *Lock) Unlock() error {
+	unlock(l.file)
+	return l.file.Close()
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package fsutil
+
+import "os"
+
+// Windows 上不支持 flock，仅依赖原子写入
+func tryLock(file *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlock(file *os.File) {}
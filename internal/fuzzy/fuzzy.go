@@ -0,0 +1,182 @@
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 评分参数（参考 fzf）
+const (
+	scoreMatch        = 16 // 每个匹配字符
+	scoreGapStart     = -3 // 匹配字符之间出现间隔
+	scoreGapExtension = -1 // 间隔每多一个字符
+	bonusBoundary     = 8  // 单词开头（分隔符之后）
+	bonusCamel        = 7  // 驼峰或字母数字切换处
+	bonusConsecutive  = 4  // 连续匹配
+	bonusFirstChar    = 2  // 模式首字符的加成倍数
+	bonusNameField    = 20 // 匹配主机名称时的额外加分
+)
+
+// 主机字段
+const (
+	FieldName        = "name"
+	FieldIP          = "ip"
+	FieldUsername    = "username"
+	FieldTags        = "tags"
+	FieldDescription = "description"
+)
+
+// 主机匹配结果
+type HostMatch struct {
+	Score     int
+	Positions map[string][]int // 字段 -> 匹配的字符下标（按 rune 计）
+}
+
+// 在 text 中按顺序查找 pattern 的全部字符（忽略大小写），返回评分和匹配位置
+func Match(pattern, text string) (int, []int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(text)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	lower := make([]rune, len(t))
+	for i, r := range t {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// 正向扫描找到最早完成匹配的位置
+	pi, end := 0, -1
+	for i, r := range lower {
+		if r == p[pi] {
+			pi++
+			if pi == len(p) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// 反向扫描得到最短的匹配区间
+	start := end
+	pi = len(p) - 1
+	for i := end; i >= 0; i-- {
+		if lower[i] == p[pi] {
+			pi--
+			if pi < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	// 在区间内正向确定匹配位置并计分
+	positions := make([]int, 0, len(p))
+	score, pi, prev, chunkBonus := 0, 0, -1, 0
+	for i := start; i <= end && pi < len(p); i++ {
+		if lower[i] != p[pi] {
+			continue
+		}
+		bonus := charBonus(t, i)
+		if prev >= 0 && i == prev+1 {
+			// 连续匹配沿用该段首字符的加成
+			bonus = max(bonus, chunkBonus, bonusConsecutive)
+		} else {
+			if prev >= 0 {
+				score += scoreGapStart + scoreGapExtension*(i-prev-2)
+			}
+			chunkBonus = bonus
+		}
+		if pi == 0 {
+			score += bonus * (bonusFirstChar - 1)
+		}
+		score += scoreMatch + bonus
+		positions = append(positions, i)
+		prev = i
+		pi++
+	}
+	return score, positions, true
+}
+
+// 字符位置的加成：单词开头和驼峰处更可能是用户想匹配的位置
+func charBonus(t []rune, i int) int {
+	if i == 0 {
+		return bonusBoundary
+	}
+	prev, cur := t[i-1], t[i]
+	switch {
+	case isSeparator(prev) && !isSeparator(cur):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusCamel
+	case !unicode.IsDigit(prev) && unicode.IsDigit(cur):
+		return bonusCamel
+	default:
+		return 0
+	}
+}
+
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// 在主机的名称、IP、用户名、标签和描述中模糊匹配
+// 查询按空白拆分为多个词，每个词都需要在某个字段中匹配
+func MatchHost(query string, host models.Host) (HostMatch, bool) {
+	result := HostMatch{Positions: map[string][]int{}}
+	fields := []struct {
+		name string
+		text string
+	}{
+		{FieldName, host.Name},
+		{FieldIP, host.IP},
+		{FieldUsername, host.Username},
+		{FieldTags, strings.Join(host.Tags, " ")},
+		{FieldDescription, host.Description},
+	}
+
+	for _, term := range strings.Fields(query) {
+		best, bestField, matched := 0, "", false
+		var bestPositions []int
+		for _, field := range fields {
+			score, positions, ok := Match(term, field.text)
+			if !ok {
+				continue
+			}
+			if field.name == FieldName {
+				score += bonusNameField
+			}
+			if !matched || score > best {
+				best, bestField, bestPositions, matched = score, field.name, positions, true
+			}
+		}
+		if !matched {
+			return HostMatch{}, false
+		}
+		result.Score += best
+		result.Positions[bestField] = mergePositions(result.Positions[bestField], bestPositions)
+	}
+	return result, true
+}
+
+// 合并两组位置（去重并保持升序）
+func mergePositions(a, b []int) []int {
+	seen := map[int]bool{}
+	var merged []int
+	for _, list := range [][]int{a, b} {
+		for _, p := range list {
+			if !seen[p] {
+				seen[p] = true
+				merged = append(merged, p)
+			}
+		}
+	}
+	sort.Ints(merged)
+	return merged
+}
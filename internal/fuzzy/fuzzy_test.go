@@ -0,0 +1,60 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 测试子序列匹配和位置
+func TestMatch(t *testing.T) {
+	_, positions, ok := Match("wb1", "Web-Server-01")
+	if !ok || !reflect.DeepEqual(positions, []int{0, 2, 12}) {
+		t.Errorf("匹配位置错误: %v %v", positions, ok)
+	}
+	if _, _, ok := Match("xyz", "web01"); ok {
+		t.Error("不应匹配")
+	}
+	if _, _, ok := Match("服务器", "Web服务器-1"); !ok {
+		t.Error("应匹配中文")
+	}
+}
+
+// 测试评分：连续匹配和单词开头优先
+func TestMatchScore(t *testing.T) {
+	consecutive, _, _ := Match("web", "web-01")
+	scattered, _, _ := Match("web", "w-e-b")
+	if consecutive <= scattered {
+		t.Errorf("连续匹配评分应更高: %d <= %d", consecutive, scattered)
+	}
+
+	boundary, _, _ := Match("db", "prod-db")
+	middle, _, _ := Match("db", "prodbox")
+	if boundary <= middle {
+		t.Errorf("单词开头评分应更高: %d <= %d", boundary, middle)
+	}
+}
+
+// 测试主机多字段匹配
+func TestMatchHost(t *testing.T) {
+	host := models.Host{
+		Name: "api-server", IP: "10.0.0.5", Username: "deploy",
+		Tags: []string{"prod", "web"}, Description: "订单服务",
+	}
+
+	match, ok := MatchHost("api prod", host)
+	if !ok {
+		t.Fatal("应匹配名称和标签")
+	}
+	if !reflect.DeepEqual(match.Positions[FieldName], []int{0, 1, 2}) || len(match.Positions[FieldTags]) != 4 {
+		t.Errorf("匹配位置错误: %v", match.Positions)
+	}
+
+	if _, ok := MatchHost("订单", host); !ok {
+		t.Error("应匹配描述")
+	}
+	if _, ok := MatchHost("api staging", host); ok {
+		t.Error("所有词都需要匹配")
+	}
+}
@@ -0,0 +1,81 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 主机的使用频率评分（次数按时间衰减累加，参考 zoxide）
+type Scores map[string]float64
+
+// 主机标识（用户@地址:端口），与 Entry.Matches 一致
+func Key(host models.Host) string {
+	return fmt.Sprintf("%s@%s:%d", host.Username, host.IP, host.Port)
+}
+
+// 根据历史记录计算评分，连接失败的记录不计入
+func Frecency(entries []Entry, now time.Time) Scores {
+	scores := Scores{}
+	for _, entry := range entries {
+		if entry.ExitStatus < 0 {
+			continue
+		}
+		scores[entry.key()] += recencyWeight(now.Sub(entry.Time))
+	}
+	return scores
+}
+
+// 读取历史文件并计算评分，读取失败时返回空评分
+func LoadScores(path string) Scores {
+	entries, err := Load(path)
+	if err != nil {
+		return Scores{}
+	}
+	return Frecency(entries, time.Now())
+}
+
+// 越近的连接权重越高
+func recencyWeight(age time.Duration) float64 {
+	switch {
+	case age < time.Hour:
+		return 4
+	case age < 24*time.Hour:
+		return 2
+	case age < 7*24*time.Hour:
+		return 0.5
+	default:
+		return 0.25
+	}
+}
+
+// 主机的评分，未连接过为 0
+func (s Scores) Score(host models.Host) float64 {
+	return s[Key(host)]
+}
+
+// 按评分从高到低排序，评分相同时保持原有顺序
+func (s Scores) Rank(hosts []models.Host) {
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return s.Score(hosts[i]) > s.Score(hosts[j])
+	})
+}
+
+// 评分最高的 n 台主机（只包含连接过的主机）
+func (s Scores) Top(groups []models.Group, n int) []models.Host {
+	var hosts []models.Host
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			if s.Score(host) > 0 {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	s.Rank(hosts)
+	if len(hosts) > n {
+		hosts = hosts[:n]
+	}
+	return hosts
+}
@@ -0,0 +1,39 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 测试频率评分排序
+func TestFrecencyRank(t *testing.T) {
+	now := time.Now()
+	web1 := models.Host{Name: "web1", IP: "10.0.0.1", Port: 22, Username: "root"}
+	web2 := models.Host{Name: "web2", IP: "10.0.0.2", Port: 22, Username: "root"}
+	web3 := models.Host{Name: "web3", IP: "10.0.0.3", Port: 22, Username: "root"}
+
+	entries := []Entry{
+		// web1 很久以前连接过很多次
+		NewEntry(web1, SourceCLI, now.AddDate(0, -1, 0), 0),
+		NewEntry(web1, SourceCLI, now.AddDate(0, -1, 0), 0),
+		NewEntry(web1, SourceCLI, now.AddDate(0, -1, 0), 0),
+		// web2 刚刚连接过
+		NewEntry(web2, SourceTUI, now.Add(-time.Minute), 0),
+		// web3 连接失败不计分
+		NewEntry(web3, SourceCLI, now, -1),
+	}
+	scores := Frecency(entries, now)
+
+	hosts := []models.Host{web3, web1, web2}
+	scores.Rank(hosts)
+	if hosts[0].Name != "web2" || hosts[1].Name != "web1" || hosts[2].Name != "web3" {
+		t.Errorf("排序错误: %s %s %s", hosts[0].Name, hosts[1].Name, hosts[2].Name)
+	}
+
+	top := scores.Top([]models.Group{{Name: "web", Hosts: []models.Host{web1, web2, web3}}}, 5)
+	if len(top) != 2 || top[0].Name != "web2" {
+		t.Errorf("常用主机错误: %+v", top)
+	}
+}
@@ -0,0 +1,200 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 历史记录来源
+const (
+	SourceCLI = "cli"
+	SourceTUI = "tui"
+)
+
+// 最多保留的历史记录条数
+const maxEntries = 1000
+
+// 一次连接的历史记录
+type Entry struct {
+	Host       string    `json:"host" yaml:"host"`
+	IP         string    `json:"ip" yaml:"ip"`
+	Port       int       `json:"port" yaml:"port"`
+	Username   string    `json:"username" yaml:"username"`
+	Time       time.Time `json:"time" yaml:"time"`
+	DurationMs int64     `json:"duration_ms" yaml:"duration_ms"`
+	ExitStatus int       `json:"exit_status" yaml:"exit_status"` // -1 表示连接失败
+	Source     string    `json:"source" yaml:"source"`
+}
+
+// 查询条件
+type Filter struct {
+	Host  string    // 主机名称（忽略大小写的子串）或IP
+	Since time.Time // 只保留此时间之后的记录
+	Limit int       // 最多返回条数，0 表示不限制
+}
+
+// 历史文件的默认路径（不与 config.yaml 混在一起）
+func DefaultPath() string {
+	if path := os.Getenv("HOSTMANAGER_HISTORY"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "history.jsonl"
+	}
+	return filepath.Join(home, ".hostmanager", "history.jsonl")
+}
+
+// 根据连接结果生成记录
+func NewEntry(host models.Host, source string, start time.Time, exitStatus int) Entry {
+	return Entry{
+		Host:       host.Name,
+		IP:         host.IP,
+		Port:       host.Port,
+		Username:   host.Username,
+		Time:       start,
+		DurationMs: time.Since(start).Milliseconds(),
+		ExitStatus: exitStatus,
+		Source:     source,
+	}
+}
+
+// 记录是否对应指定主机
+func (e Entry) Matches(host models.Host) bool {
+	return e.IP == host.IP && e.Port == host.Port && e.Username == host.Username
+}
+
+// 记录对应的主机标识，与 Key 一致
+func (e Entry) key() string {
+	return fmt.Sprintf("%s@%s:%d", e.Username, e.IP, e.Port)
+}
+
+// 连接耗时
+func (e Entry) Duration() time.Duration {
+	return time.Duration(e.DurationMs) * time.Millisecond
+}
+
+// 读取历史记录（按时间先后），文件不存在时返回空
+func Load(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry Entry
+		// 跳过损坏的行（例如写入中断），不影响其余记录
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// 追加一条记录，超过上限时只保留最近的记录
+func Append(path string, entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// 命令行和界面可能同时写入
+	lock, err := fsutil.LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	entries, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if len(entries)+1 > maxEntries {
+		entries = append(entries, entry)
+		return rewrite(path, entries[len(entries)-maxEntries:])
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// 重写整个历史文件（先写临时文件再替换）
+func rewrite(path string, entries []Entry) error {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(data, '\n'))
+	}
+	return fsutil.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// 按条件筛选，结果按时间倒序
+func Apply(entries []Entry, filter Filter) []Entry {
+	result := []Entry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+			continue
+		}
+		if filter.Host != "" && entry.IP != filter.Host &&
+			!strings.Contains(strings.ToLower(entry.Host), strings.ToLower(filter.Host)) {
+			continue
+		}
+		result = append(result, entry)
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// 解析 --since 参数：相对时长（30m、12h、7d）或日期（2006-01-02、RFC3339）
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("无效的时间: %s (示例: 2h, 7d, 2024-01-31)", value)
+}
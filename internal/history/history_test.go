@@ -0,0 +1,87 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 测试追加和读取历史记录
+func TestAppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "history.jsonl")
+	host := models.Host{Name: "web1", IP: "10.0.0.1", Port: 22, Username: "root"}
+
+	if entries, err := Load(path); err != nil || len(entries) != 0 {
+		t.Fatalf("文件不存在时应返回空记录: %v %v", entries, err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := Append(path, NewEntry(host, SourceCLI, time.Now(), i)); err != nil {
+			t.Fatalf("追加失败: %v", err)
+		}
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	if len(entries) != 3 || entries[2].ExitStatus != 2 || !entries[0].Matches(host) {
+		t.Errorf("读取内容错误: %+v", entries)
+	}
+}
+
+// 测试超过上限后只保留最近的记录
+func TestAppendTrims(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	entries := make([]Entry, maxEntries)
+	if err := rewrite(path, entries); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if err := Append(path, Entry{Host: "last"}); err != nil {
+		t.Fatalf("追加失败: %v", err)
+	}
+
+	loaded, _ := Load(path)
+	if len(loaded) != maxEntries || loaded[len(loaded)-1].Host != "last" {
+		t.Errorf("裁剪结果错误: %d 条", len(loaded))
+	}
+}
+
+// 测试筛选
+func TestApply(t *testing.T) {
+	now := time.Now()
+	entries := []Entry{
+		{Host: "web1", IP: "10.0.0.1", Port: 22, Time: now.Add(-48 * time.Hour)},
+		{Host: "db", IP: "10.0.0.2", Port: 22, Time: now.Add(-2 * time.Hour)},
+		{Host: "web1", IP: "10.0.0.1", Port: 22, Time: now.Add(-time.Hour)},
+	}
+
+	result := Apply(entries, Filter{Host: "WEB"})
+	if len(result) != 2 || !result[0].Time.After(result[1].Time) {
+		t.Errorf("按主机筛选错误: %+v", result)
+	}
+	result = Apply(entries, Filter{Since: now.Add(-24 * time.Hour), Limit: 1})
+	if len(result) != 1 || result[0].Host != "web1" {
+		t.Errorf("按时间筛选错误: %+v", result)
+	}
+}
+
+// 测试 --since 解析
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"2h":                   now.Add(-2 * time.Hour),
+		"7d":                   now.AddDate(0, 0, -7),
+		"2024-01-31T08:00:00Z": time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC),
+	}
+	for value, expected := range cases {
+		got, err := ParseSince(value, now)
+		if err != nil || !got.Equal(expected) {
+			t.Errorf("ParseSince(%q) = %v, %v; 期望 %v", value, got, err, expected)
+		}
+	}
+	if _, err := ParseSince("yesterday", now); err == nil {
+		t.Error("无效时间应返回错误")
+	}
+}
@@ -0,0 +1,281 @@
+package hostkey
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+)
+
+// 已记录的主机密钥
+type Entry struct {
+	Key  ssh.PublicKey
+	File string // 所在的 known_hosts 文件
+	Line int
+}
+
+// 首次连接：known_hosts 中没有该主机的记录
+type UnknownError struct {
+	Address string
+	Key     ssh.PublicKey
+}
+
+func (e *UnknownError) Error() string {
+	return fmt.Sprintf("未知的主机密钥 %s (%s)，请先运行 hostmanager hostkey scan 确认指纹", e.Address, Fingerprint(e.Key))
+}
+
+// 主机密钥与记录不一致，可能存在中间人攻击
+type ChangedError struct {
+	Address string
+	Key     ssh.PublicKey
+	Known   []Entry
+}
+
+func (e *ChangedError) Error() string {
+	return fmt.Sprintf("⚠️  %s 的主机密钥已改变（可能存在中间人攻击），已拒绝连接", e.Address)
+}
+
+// 醒目的警告信息，包括新旧指纹和处理方法
+func (e *ChangedError) Warning(name string) string {
+	line := strings.Repeat("@", 60)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n@    警告：%s (%s) 的主机密钥已改变！\n%s\n", line, name, e.Address, line)
+	fmt.Fprintf(&b, "有人可能正在进行中间人攻击，也可能是主机重装或更换了密钥。\n")
+	fmt.Fprintf(&b, "收到的 %s 密钥指纹: %s\n", e.Key.Type(), Fingerprint(e.Key))
+	for _, entry := range e.Known {
+		fmt.Fprintf(&b, "已记录的 %s 密钥指纹: %s (%s:%d)\n", entry.Key.Type(), Fingerprint(entry.Key), entry.File, entry.Line)
+	}
+	fmt.Fprintf(&b, "确认密钥变更是预期的之后，运行 hostmanager hostkey forget %s 删除旧记录", name)
+	if e.inUserFile() {
+		fmt.Fprintf(&b, "，并运行 ssh-keygen -R '%s'", e.Address)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (e *ChangedError) inUserFile() bool {
+	for _, entry := range e.Known {
+		if entry.File == UserPath() {
+			return true
+		}
+	}
+	return false
+}
+
+// hostmanager 管理的 known_hosts，默认 ~/.hostmanager/known_hosts，可通过 HOSTMANAGER_KNOWN_HOSTS 覆盖
+func DefaultPath() string {
+	if path := os.Getenv("HOSTMANAGER_KNOWN_HOSTS"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".hostmanager", "known_hosts")
+	}
+	return filepath.Join(home, ".hostmanager", "known_hosts")
+}
+
+// 用户的 ~/.ssh/known_hosts（只读取，不修改）
+func UserPath() string {
+	return fsutil.ExpandPath("~/.ssh/known_hosts")
+}
+
+// known_hosts 中的主机地址，端口不是 22 时为 [ip]:port
+func Address(ip string, port int) string {
+	return knownhosts.Normalize(net.JoinHostPort(ip, strconv.Itoa(port)))
+}
+
+// 密钥的 SHA256 指纹
+func Fingerprint(key ssh.PublicKey) string {
+	return ssh.FingerprintSHA256(key)
+}
+
+// 校验主机密钥：已记录且一致时返回 nil，未记录该类型的密钥返回 *UnknownError，
+// 同类型的密钥不一致返回 *ChangedError
+func Check(address string, key ssh.PublicKey) error {
+	err := lookup(address, key)
+	var keyErr *knownhosts.KeyError
+	if !errors.As(err, &keyErr) {
+		return err
+	}
+	for _, known := range keyErr.Want {
+		if known.Key.Type() == key.Type() {
+			return &ChangedError{Address: address, Key: key, Known: entries(keyErr.Want)}
+		}
+	}
+	return &UnknownError{Address: address, Key: key}
+}
+
+// 客户端默认支持的主机密钥算法（与 x/crypto/ssh 的默认顺序相同）
+var defaultAlgorithms = []string{
+	ssh.CertAlgoRSASHA256v01, ssh.CertAlgoRSASHA512v01,
+	ssh.CertAlgoRSAv01, ssh.CertAlgoDSAv01, ssh.CertAlgoECDSA256v01,
+	ssh.CertAlgoECDSA384v01, ssh.CertAlgoECDSA521v01, ssh.CertAlgoED25519v01,
+	ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521,
+	ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSASHA512,
+	ssh.KeyAlgoRSA, ssh.KeyAlgoDSA,
+	ssh.KeyAlgoED25519,
+}
+
+// 握手时使用的主机密钥算法：已记录的密钥类型排在最前，避免服务端有多种密钥时
+// 出示另一种类型的密钥而被误判为密钥改变；没有记录时返回 nil（使用默认顺序）
+func Algorithms(address string) []string {
+	known, err := Known(address)
+	if err != nil || len(known) == 0 {
+		return nil
+	}
+	var algorithms []string
+	seen := make(map[string]bool)
+	add := func(names ...string) {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				algorithms = append(algorithms, name)
+			}
+		}
+	}
+	for _, entry := range known {
+		if entry.Key.Type() == ssh.KeyAlgoRSA {
+			// RSA 密钥可以使用三种签名算法
+			add(ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA)
+		} else {
+			add(entry.Key.Type())
+		}
+	}
+	add(defaultAlgorithms...)
+	return algorithms
+}
+
+// 列出主机已记录的密钥（每种密钥类型一条）
+func Known(address string) ([]Entry, error) {
+	// 用不存在的密钥类型查询，knownhosts 会返回该地址的全部记录
+	err := lookup(address, probeKey{})
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		return entries(keyErr.Want), nil
+	}
+	return nil, err
+}
+
+// 在两个 known_hosts 文件中查找，不存在的文件会被忽略
+func lookup(address string, key ssh.PublicKey) error {
+	var files []string
+	for _, path := range []string{DefaultPath(), UserPath()} {
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	if len(files) == 0 {
+		return &knownhosts.KeyError{}
+	}
+	callback, err := knownhosts.New(files...)
+	if err != nil {
+		return fmt.Errorf("读取 known_hosts 失败: %v", err)
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host, port = address, "22"
+	}
+	// Normalize 后的 [ip]:port 需要还原为 ip:port 才能被解析
+	hostname := net.JoinHostPort(strings.Trim(host, "[]"), port)
+	return callback(hostname, &net.TCPAddr{}, key)
+}
+
+func entries(known []knownhosts.KnownKey) []Entry {
+	result := make([]Entry, len(known))
+	for i, k := range known {
+		result[i] = Entry{Key: k.Key, File: k.Filename, Line: k.Line}
+	}
+	return result
+}
+
+// 把主机密钥追加到 hostmanager 管理的 known_hosts
+func Add(address string, key ssh.PublicKey) error {
+	path := DefaultPath()
+	lock, err := fsutil.LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(file, knownhosts.Line([]string{address}, key)); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// 从 hostmanager 管理的 known_hosts 中删除主机的记录，返回删除的条数
+func Forget(address string) (int, error) {
+	path := DefaultPath()
+	lock, err := fsutil.LockFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var kept bytes.Buffer
+	removed := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if matchesLine(line, address) {
+			removed++
+			continue
+		}
+		kept.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, fsutil.WriteFile(path, kept.Bytes(), 0600)
+}
+
+// 记录行的主机列表中是否包含该地址（只比较明文地址，hostmanager 写入的记录不做哈希）
+func matchesLine(line, address string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+		return false
+	}
+	hosts := fields[0]
+	if strings.HasPrefix(hosts, "@") && len(fields) > 2 {
+		hosts = fields[1]
+	}
+	for _, host := range strings.Split(hosts, ",") {
+		if host == address {
+			return true
+		}
+	}
+	return false
+}
+
+// 用于查询记录的占位密钥，类型不会与真实密钥相同
+type probeKey struct{}
+
+func (probeKey) Type() string                        { return "hostmanager-probe" }
+func (probeKey) Marshal() []byte                     { return []byte("hostmanager-probe") }
+func (probeKey) Verify([]byte, *ssh.Signature) error { return errors.New("probe key") }
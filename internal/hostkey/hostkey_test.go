@@ -0,0 +1,103 @@
+package hostkey
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// 使用临时目录中的 known_hosts
+func setup(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("HOSTMANAGER_KNOWN_HOSTS", filepath.Join(home, ".hostmanager", "known_hosts"))
+	return home
+}
+
+// 测试记录、校验和删除主机密钥
+func TestCheckAddForget(t *testing.T) {
+	setup(t)
+	address := Address("10.0.0.1", 2222)
+	if address != "[10.0.0.1]:2222" || Address("10.0.0.1", 22) != "10.0.0.1" {
+		t.Fatalf("地址格式错误: %s", address)
+	}
+
+	key := newKey(t)
+	var unknown *UnknownError
+	if err := Check(address, key); !errors.As(err, &unknown) {
+		t.Fatalf("未记录的主机应返回 UnknownError: %v", err)
+	}
+
+	if err := Add(address, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := Add(Address("10.0.0.2", 22), newKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Check(address, key); err != nil {
+		t.Errorf("已记录的密钥应通过校验: %v", err)
+	}
+
+	var changed *ChangedError
+	if err := Check(address, newKey(t)); !errors.As(err, &changed) || len(changed.Known) != 1 {
+		t.Errorf("密钥改变时应返回 ChangedError: %v", err)
+	}
+	if known, err := Known(address); err != nil || len(known) != 1 || known[0].Line != 1 {
+		t.Errorf("Known 结果错误: %+v %v", known, err)
+	}
+
+	if removed, err := Forget(address); err != nil || removed != 1 {
+		t.Fatalf("Forget 结果错误: %d %v", removed, err)
+	}
+	if err := Check(address, key); !errors.As(err, &unknown) {
+		t.Errorf("删除后应视为未知主机: %v", err)
+	}
+	if known, _ := Known(Address("10.0.0.2", 22)); len(known) != 1 {
+		t.Error("不应删除其他主机的记录")
+	}
+}
+
+// 测试读取 ~/.ssh/known_hosts 中的哈希记录
+func TestUserKnownHosts(t *testing.T) {
+	home := setup(t)
+	key := newKey(t)
+	line := knownhosts.Line([]string{knownhosts.HashHostname("10.0.0.3")}, key)
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "known_hosts"), []byte(line+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	address := Address("10.0.0.3", 22)
+	if err := Check(address, key); err != nil {
+		t.Errorf("应识别用户 known_hosts 中的记录: %v", err)
+	}
+	var changed *ChangedError
+	if err := Check(address, newKey(t)); !errors.As(err, &changed) || !changed.inUserFile() {
+		t.Errorf("密钥改变时应指出用户 known_hosts 中的记录: %v", err)
+	}
+	if removed, _ := Forget(address); removed != 0 {
+		t.Error("Forget 不应修改用户的 known_hosts")
+	}
+}
@@ -12,6 +12,8 @@ type Texts struct {
 	Operations        string `yaml:"operations"`
 	Favorites         string `yaml:"favorites"`
 	NoFavorites       string `yaml:"no_favorites"`
+	TagView           string `yaml:"tag_view"`
+	NoTags            string `yaml:"no_tags"`
 	Connecting        string `yaml:"connecting"`
 	ConnectionClosed  string `yaml:"connection_closed"`
 	PressAnyKey       string `yaml:"press_any_key"`
@@ -30,9 +32,11 @@ func GetChineseTexts() Texts {
 		FoundHosts:        "找到 %d 个匹配的主机",
 		QuickConnect:      "快速连接 (按数字键1-5直接连接):",
 		ServerGroups:      "服务器分组:",
-		Operations:        "操作: ↑↓选择 | 回车连接 | /搜索 | f收藏夹 | s状态检查 | r重载 | t主题 | l布局 | ESC退出",
+		Operations:        "操作: ↑↓选择 | 回车连接 | /搜索 | f收藏夹 | #标签 | s状态检查 | p隧道 | b文件 | r重载 | t主题 | l布局 | ESC退出",
 		Favorites:         "收藏的主机 (按f退出收藏模式):",
 		NoFavorites:       "暂无收藏的主机，在主机列表中按空格键添加收藏",
+		TagView:           "标签 (按#返回分组):",
+		NoTags:            "暂无标签，使用 hostmanager tag add 为主机添加标签",
 		Connecting:        "正在连接到 %s (%s@%s:%d)...",
 		ConnectionClosed:  "与 %s 的连接已断开",
 		PressAnyKey:       "按任意键返回主菜单...",
@@ -52,9 +56,11 @@ func GetEnglishTexts() Texts {
 		FoundHosts:        "Found %d matching hosts",
 		QuickConnect:      "Quick Connect (Press number key 1-5):",
 		ServerGroups:      "Server Groups:",
-		Operations:        "Operations: ↑↓Select | Enter Connect | /Search | f Favorites | s Status | r Reload | t Theme | l Layout | ESC Exit",
+		Operations:        "Operations: ↑↓Select | Enter Connect | /Search | f Favorites | # Tags | s Status | p Tunnels | b Files | r Reload | t Theme | l Layout | ESC Exit",
 		Favorites:         "Favorite Hosts (Press f to exit favorites mode):",
 		NoFavorites:       "No favorite hosts. Press Space in host list to add favorites",
+		TagView:           "Tags (Press # to return to groups):",
+		NoTags:            "No tags yet. Use hostmanager tag add to tag hosts",
 		Connecting:        "Connecting to %s (%s@%s:%d)...",
 		ConnectionClosed:  "Connection to %s closed",
 		PressAnyKey:       "Press any key to return to main menu...",
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// 端口转发类型
+const (
+	ForwardLocal   = "local"   // ssh -L：本地端口转发到远端可达的地址
+	ForwardRemote  = "remote"  // ssh -R：远端端口转发到本地可达的地址
+	ForwardDynamic = "dynamic" // ssh -D：本地 SOCKS5 代理
+)
+
+// 端口转发配置
+type Forward struct {
+	Name   string `yaml:"name,omitempty"`   // 名称，tunnel --name 使用
+	Type   string `yaml:"type"`             // local、remote 或 dynamic
+	Listen string `yaml:"listen"`           // 监听地址 [地址:]端口，默认只监听 127.0.0.1
+	Target string `yaml:"target,omitempty"` // 转发目标 主机:端口，dynamic 不需要
+}
+
+// 监听地址，未指定地址时使用 127.0.0.1
+func (f Forward) ListenAddress() string {
+	if _, err := strconv.Atoi(f.Listen); err == nil {
+		return net.JoinHostPort("127.0.0.1", f.Listen)
+	}
+	return f.Listen
+}
+
+// ssh 命令行参数的选项，例如 -L
+func (f Forward) Flag() string {
+	switch f.Type {
+	case ForwardLocal:
+		return "-L"
+	case ForwardRemote:
+		return "-R"
+	case ForwardDynamic:
+		return "-D"
+	}
+	return ""
+}
+
+// ssh 命令行参数的值，例如 127.0.0.1:5432:db:5432
+func (f Forward) Spec() string {
+	if f.Type == ForwardDynamic {
+		return f.ListenAddress()
+	}
+	return f.ListenAddress() + ":" + f.Target
+}
+
+// 显示文本，例如 "L 127.0.0.1:5432 → db:5432"
+func (f Forward) String() string {
+	text := strings.TrimPrefix(f.Flag(), "-") + " " + f.ListenAddress()
+	if f.Type == ForwardDynamic {
+		text += " (SOCKS5)"
+	} else {
+		text += " → " + f.Target
+	}
+	if f.Name != "" {
+		text = f.Name + ": " + text
+	}
+	return text
+}
+
+// 检查配置是否有效
+func (f Forward) Validate() error {
+	switch f.Type {
+	case ForwardLocal, ForwardRemote, ForwardDynamic:
+	default:
+		return fmt.Errorf("未知的转发类型: %s（可选 local、remote、dynamic）", f.Type)
+	}
+	if err := checkAddress(f.ListenAddress()); err != nil {
+		return fmt.Errorf("监听地址无效: %s", f.Listen)
+	}
+	if f.Type == ForwardDynamic {
+		if f.Target != "" {
+			return fmt.Errorf("dynamic 转发不需要 target")
+		}
+		return nil
+	}
+	if err := checkAddress(f.Target); err != nil {
+		return fmt.Errorf("转发目标无效: %s（应为 主机:端口）", f.Target)
+	}
+	return nil
+}
+
+// 检查 主机:端口 格式
+func checkAddress(address string) error {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("端口无效: %s", port)
+	}
+	return nil
+}
+
+// 按名称选择转发，names 为空时返回全部
+func (h *Host) SelectForwards(names []string) ([]Forward, error) {
+	if len(names) == 0 {
+		return h.Forwards, nil
+	}
+	var selected []Forward
+	for _, name := range names {
+		found := false
+		for _, f := range h.Forwards {
+			if strings.EqualFold(f.Name, name) {
+				selected = append(selected, f)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("主机 %s 没有名为 %s 的端口转发", h.Name, name)
+		}
+	}
+	return selected, nil
+}
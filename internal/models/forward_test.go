@@ -0,0 +1,43 @@
+package models
+
+import "testing"
+
+// 测试端口转发的参数生成和校验
+func TestForward(t *testing.T) {
+	cases := []struct {
+		forward Forward
+		flag    string
+		spec    string
+		valid   bool
+	}{
+		{Forward{Type: ForwardLocal, Listen: "5432", Target: "db:5432"}, "-L", "127.0.0.1:5432:db:5432", true},
+		{Forward{Type: ForwardRemote, Listen: "0.0.0.0:8080", Target: "localhost:3000"}, "-R", "0.0.0.0:8080:localhost:3000", true},
+		{Forward{Type: ForwardDynamic, Listen: "1080"}, "-D", "127.0.0.1:1080", true},
+		{Forward{Type: ForwardDynamic, Listen: "1080", Target: "db:1"}, "-D", "127.0.0.1:1080", false},
+		{Forward{Type: ForwardLocal, Listen: "5432", Target: "db"}, "-L", "127.0.0.1:5432:db", false},
+		{Forward{Type: ForwardLocal, Listen: "99999", Target: "db:1"}, "-L", "127.0.0.1:99999:db:1", false},
+		{Forward{Type: "socks", Listen: "1080"}, "", "127.0.0.1:1080:", false},
+	}
+	for _, c := range cases {
+		if flag, spec := c.forward.Flag(), c.forward.Spec(); flag != c.flag || spec != c.spec {
+			t.Errorf("%+v 参数错误: %s %s", c.forward, flag, spec)
+		}
+		if err := c.forward.Validate(); (err == nil) != c.valid {
+			t.Errorf("%+v 校验结果错误: %v", c.forward, err)
+		}
+	}
+}
+
+// 测试按名称选择端口转发
+func TestSelectForwards(t *testing.T) {
+	host := Host{Name: "db", Forwards: []Forward{{Name: "pg"}, {Name: "socks"}, {}}}
+	if all, err := host.SelectForwards(nil); err != nil || len(all) != 3 {
+		t.Errorf("未指定名称时应返回全部: %v %v", all, err)
+	}
+	if selected, err := host.SelectForwards([]string{"PG"}); err != nil || len(selected) != 1 || selected[0].Name != "pg" {
+		t.Errorf("按名称选择错误: %v %v", selected, err)
+	}
+	if _, err := host.SelectForwards([]string{"redis"}); err == nil {
+		t.Error("不存在的名称应返回错误")
+	}
+}
@@ -1,25 +1,115 @@
 package models
 
+import "strings"
+
 // 主机配置结构
 type Host struct {
-	Name         string   `yaml:"name"`
-	IP           string   `yaml:"ip"`
-	Port         int      `yaml:"port"`
-	Username     string   `yaml:"username"`
-	AuthType     string   `yaml:"auth_type"`
-	KeyPath      string   `yaml:"key_path,omitempty"`
-	Password     string   `yaml:"password,omitempty"`
-	Description  string   `yaml:"description,omitempty"`
-	Tags         []string `yaml:"tags,omitempty"`
-	Favorite     bool     `yaml:"favorite,omitempty"`
-	ZmodemEnable *bool    `yaml:"zmodem_enable,omitempty"` // 启用 Zmodem 文件传输支持，默认 true
-	Status       string   `yaml:"-"`                       // 运行时状态，不保存到配置文件
+	Name           string       `yaml:"name"`
+	IP             string       `yaml:"ip"`
+	Port           int          `yaml:"port,omitempty"` // 未设置时依次使用分组、全局和内置默认值
+	Username       string       `yaml:"username,omitempty"`
+	AuthType       string       `yaml:"auth_type,omitempty"`
+	KeyPath        string       `yaml:"key_path,omitempty"`
+	Password       string       `yaml:"password,omitempty"`
+	PasswordRef    string       `yaml:"password_ref,omitempty"` // 密码保险库中的条目ID，优先于明文密码
+	Description    string       `yaml:"description,omitempty"`
+	Tags           []string     `yaml:"tags,omitempty"`
+	Favorite       bool         `yaml:"favorite,omitempty"`
+	ZmodemEnable   *bool        `yaml:"zmodem_enable,omitempty"`   // 启用 Zmodem 文件传输支持，默认 true
+	ConnectMode    string       `yaml:"connect_mode,omitempty"`    // 连接方式: "native"(内置客户端，默认) 或 "external"(系统 ssh/expect)
+	ConnectTimeout int          `yaml:"connect_timeout,omitempty"` // 连接超时（秒），默认 10
+	Jump           JumpChain    `yaml:"jump,omitempty"`            // 跳板机（主机名称或名称列表），"none" 表示不使用分组默认跳板机
+	Forwards       []Forward    `yaml:"forwards,omitempty"`        // 端口转发，连接时自动建立
+	Status         string       `yaml:"-"`                         // 运行时状态，不保存到配置文件
+	Via            []Host       `yaml:"-"`                         // 运行时解析出的跳板机链（按连接顺序）
+	JumpError      error        `yaml:"-"`                         // 跳板机链无法解析的原因（跳板机不存在或循环引用），使用该主机时报告
+	Source         string       `yaml:"-"`                         // 主机所在的配置文件（通过 include 引入时为引入的文件）
+	Inherited      HostDefaults `yaml:"-"`                         // 加载时从分组、全局或内置默认值继承的字段，保存时不写入
 }
 
 // 分组配置结构
 type Group struct {
-	Name  string `yaml:"name"`
-	Hosts []Host `yaml:"hosts"`
+	Name         string           `yaml:"name"`
+	HostDefaults `yaml:",inline"` // 分组内主机的默认值（含默认跳板机 jump）
+	Hosts        []Host           `yaml:"hosts"`
+	Source       string           `yaml:"-"` // 分组所在的配置文件，为空表示主配置文件
+}
+
+// 主机默认值：配置文件的 defaults 段和分组中使用，优先级 主机 > 分组 > 全局 > 内置
+type HostDefaults struct {
+	Port           int       `yaml:"port,omitempty"`
+	Username       string    `yaml:"username,omitempty"`
+	AuthType       string    `yaml:"auth_type,omitempty"`
+	KeyPath        string    `yaml:"key_path,omitempty"`
+	ZmodemEnable   *bool     `yaml:"zmodem_enable,omitempty"`
+	ConnectMode    string    `yaml:"connect_mode,omitempty"`
+	ConnectTimeout int       `yaml:"connect_timeout,omitempty"`
+	Jump           JumpChain `yaml:"jump,omitempty"` // 默认跳板机，"none" 表示不继承上一级的跳板机
+}
+
+// 跳板机链，配置中可写成 "bastion"、"bastion1,bastion2" 或列表
+type JumpChain []string
+
+// 解析单个字符串或字符串列表
+func (j *JumpChain) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*j = nil
+		for _, name := range strings.Split(single, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				*j = append(*j, name)
+			}
+		}
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*j = list
+	return nil
+}
+
+// 单个跳板机保存为字符串，多个保存为列表
+func (j JumpChain) MarshalYAML() (interface{}, error) {
+	if len(j) == 1 {
+		return j[0], nil
+	}
+	return []string(j), nil
+}
+
+// 是否显式禁用跳板机
+func (j JumpChain) IsNone() bool {
+	return len(j) == 1 && strings.EqualFold(j[0], "none")
+}
+
+// 只保留主机自己配置的字段：值仍等于继承值的字段清空，保存后继续继承默认值
+func (h Host) Explicit() Host {
+	inherited := h.Inherited
+	if inherited.Port != 0 && h.Port == inherited.Port {
+		h.Port = 0
+	}
+	if inherited.Username != "" && h.Username == inherited.Username {
+		h.Username = ""
+	}
+	if inherited.AuthType != "" && h.AuthType == inherited.AuthType {
+		h.AuthType = ""
+	}
+	if inherited.KeyPath != "" && h.KeyPath == inherited.KeyPath {
+		h.KeyPath = ""
+	}
+	if inherited.ZmodemEnable != nil && h.ZmodemEnable != nil && *h.ZmodemEnable == *inherited.ZmodemEnable {
+		h.ZmodemEnable = nil
+	}
+	if inherited.ConnectMode != "" && h.ConnectMode == inherited.ConnectMode {
+		h.ConnectMode = ""
+	}
+	if inherited.ConnectTimeout != 0 && h.ConnectTimeout == inherited.ConnectTimeout {
+		h.ConnectTimeout = 0
+	}
+	h.Inherited = HostDefaults{}
+	return h
 }
 
 // 获取 Zmodem 启用状态，默认为 true
@@ -28,4 +118,28 @@ func (h *Host) IsZmodemEnabled() bool {
 		return true // 默认启用
 	}
 	return *h.ZmodemEnable
-}
\ No newline at end of file
+}
+
+// 是否使用外部 ssh 命令连接，默认使用内置客户端
+func (h *Host) UsesExternalSSH() bool {
+	return h.ConnectMode == "external"
+}
+
+// 是否配置了密码（明文或保险库引用）
+func (h *Host) HasStoredPassword() bool {
+	return h.Password != "" || h.PasswordRef != ""
+}
+
+// 跳板机链的显示文本，例如 "bastion → inner"
+func (h *Host) ViaDescription() string {
+	names := make([]string, len(h.Via))
+	for i, hop := range h.Via {
+		names[i] = hop.Name
+	}
+	return strings.Join(names, " → ")
+}
+
+// 是否指向同一个登录端点（IP、端口、用户名相同）
+func (h *Host) SameEndpoint(other Host) bool {
+	return h.IP == other.IP && h.Port == other.Port && h.Username == other.Username
+}
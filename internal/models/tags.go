@@ -0,0 +1,96 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// 是否带有指定标签（忽略大小写）
+func (h *Host) HasTag(tag string) bool {
+	for _, t := range h.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// 添加标签，已存在的忽略；返回是否有变化
+func (h *Host) AddTags(tags ...string) bool {
+	changed := false
+	for _, tag := range tags {
+		if tag != "" && !h.HasTag(tag) {
+			h.Tags = append(h.Tags, tag)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// 删除标签；返回是否有变化
+func (h *Host) RemoveTags(tags ...string) bool {
+	kept := h.Tags[:0:0]
+	for _, t := range h.Tags {
+		remove := false
+		for _, tag := range tags {
+			if strings.EqualFold(t, tag) {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == len(h.Tags) {
+		return false
+	}
+	h.Tags = kept
+	return true
+}
+
+// 重命名标签，在原标签的位置替换，新标签已存在时合并；返回是否有变化
+func (h *Host) RenameTag(oldTag, newTag string) bool {
+	if !h.HasTag(oldTag) {
+		return false
+	}
+	tags := h.Tags[:0:0]
+	renamed := false
+	for _, t := range h.Tags {
+		switch {
+		case strings.EqualFold(t, oldTag) && !renamed:
+			tags = append(tags, newTag)
+			renamed = true
+		case strings.EqualFold(t, oldTag), strings.EqualFold(t, newTag):
+			// 合并重复的标签
+		default:
+			tags = append(tags, t)
+		}
+	}
+	h.Tags = tags
+	return true
+}
+
+// 按标签重新分组：每个标签一组，包含所有分组中带该标签的主机（标签按名称排序）
+func GroupByTag(groups []Group) []Group {
+	index := map[string]int{}
+	var tagGroups []Group
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			for _, tag := range host.Tags {
+				key := strings.ToLower(tag)
+				i, ok := index[key]
+				if !ok {
+					i = len(tagGroups)
+					index[key] = i
+					tagGroups = append(tagGroups, Group{Name: tag})
+				}
+				tagGroups[i].Hosts = append(tagGroups[i].Hosts, host)
+			}
+		}
+	}
+	sort.SliceStable(tagGroups, func(i, j int) bool {
+		return strings.ToLower(tagGroups[i].Name) < strings.ToLower(tagGroups[j].Name)
+	})
+	return tagGroups
+}
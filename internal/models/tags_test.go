@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+// 测试标签的添加、删除和重命名
+func TestHostTags(t *testing.T) {
+	host := Host{Tags: []string{"web"}}
+	if host.AddTags("WEB") || !host.AddTags("prod", "blue") || len(host.Tags) != 3 {
+		t.Errorf("添加标签错误: %v", host.Tags)
+	}
+	if !host.RemoveTags("Blue") || host.HasTag("blue") || host.RemoveTags("missing") {
+		t.Errorf("删除标签错误: %v", host.Tags)
+	}
+	if !host.RenameTag("web", "prod") || len(host.Tags) != 1 || host.Tags[0] != "prod" {
+		t.Errorf("重命名为已有标签时应合并: %v", host.Tags)
+	}
+
+	host.Tags = []string{"a", "web", "b", "prod"}
+	if !host.RenameTag("web", "prod") || strings.Join(host.Tags, ",") != "a,prod,b" {
+		t.Errorf("重命名应保持标签顺序并去重: %v", host.Tags)
+	}
+	if !host.RenameTag("b", "c") || strings.Join(host.Tags, ",") != "a,prod,c" {
+		t.Errorf("重命名应在原位置替换: %v", host.Tags)
+	}
+}
+
+// 测试按标签分组
+func TestGroupByTag(t *testing.T) {
+	groups := []Group{
+		{Name: "a", Hosts: []Host{{Name: "h1", Tags: []string{"web"}}, {Name: "h2"}}},
+		{Name: "b", Hosts: []Host{{Name: "h3", Tags: []string{"Web", "db"}}}},
+	}
+	tagGroups := GroupByTag(groups)
+	if len(tagGroups) != 2 || tagGroups[0].Name != "db" || len(tagGroups[1].Hosts) != 2 {
+		t.Errorf("按标签分组错误: %+v", tagGroups)
+	}
+}
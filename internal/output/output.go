@@ -0,0 +1,127 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"gopkg.in/yaml.v2"
+)
+
+// 输出格式
+type Format string
+
+const (
+	Text  Format = ""      // 默认的可读文本
+	JSON  Format = "json"  // 稳定的 JSON 结构
+	YAML  Format = "yaml"  // 与 JSON 字段相同
+	Table Format = "table" // 对齐的纯文本表格
+	TSV   Format = "tsv"   // 制表符分隔，第一行为表头
+)
+
+// 支持的格式名称
+var Formats = []string{"json", "yaml", "table", "tsv"}
+
+// 解析 --output 参数
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(value)) {
+	case JSON:
+		return JSON, nil
+	case YAML:
+		return YAML, nil
+	case Table:
+		return Table, nil
+	case TSV:
+		return TSV, nil
+	}
+	return Text, fmt.Errorf("不支持的输出格式: %s (支持: %s)", value, strings.Join(Formats, ", "))
+}
+
+// 是否为结构化输出（不应混入提示文本）
+func (f Format) Structured() bool {
+	return f != Text
+}
+
+// 表格形式的数据（table/tsv 使用）
+type Rows struct {
+	Header []string
+	Rows   [][]string
+}
+
+// 按格式输出：json/yaml 序列化 value，table/tsv 输出 rows
+func Write(w io.Writer, format Format, value interface{}, rows Rows) error {
+	switch format {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case YAML:
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case TSV:
+		return writeTSV(w, rows)
+	default:
+		return writeTable(w, rows)
+	}
+}
+
+// 字段中的制表符和换行替换为空格，保证一行一条记录
+var cleanField = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// 制表符分隔
+func writeTSV(w io.Writer, rows Rows) error {
+	for _, row := range append([][]string{rows.Header}, rows.Rows...) {
+		fields := make([]string, len(row))
+		for i, field := range row {
+			fields[i] = cleanField.Replace(field)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 按显示宽度对齐的表格（正确处理中文）
+func writeTable(w io.Writer, rows Rows) error {
+	header := make([]string, len(rows.Header))
+	for i, h := range rows.Header {
+		header[i] = strings.ToUpper(h)
+	}
+	all := [][]string{header}
+	for _, row := range rows.Rows {
+		fields := make([]string, len(row))
+		for i, field := range row {
+			fields[i] = cleanField.Replace(field)
+		}
+		all = append(all, fields)
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range all {
+		for i, field := range row {
+			widths[i] = max(widths[i], runewidth.StringWidth(field))
+		}
+	}
+
+	for _, row := range all {
+		var b strings.Builder
+		for i, field := range row {
+			if i == len(row)-1 {
+				b.WriteString(field)
+			} else {
+				b.WriteString(runewidth.FillRight(field, widths[i]+2))
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(b.String(), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
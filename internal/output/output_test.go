@@ -0,0 +1,41 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+// 测试表格输出
+func TestWriteRows(t *testing.T) {
+	rows := Rows{
+		Header: []string{"name", "ip"},
+		Rows:   [][]string{{"数据库", "10.0.0.1"}, {"web\t01", "10.0.0.2"}},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, TSV, nil, rows); err != nil {
+		t.Fatalf("输出失败: %v", err)
+	}
+	if buf.String() != "name\tip\n数据库\t10.0.0.1\nweb 01\t10.0.0.2\n" {
+		t.Errorf("TSV 输出错误:\n%q", buf.String())
+	}
+
+	buf.Reset()
+	if err := Write(&buf, Table, nil, rows); err != nil {
+		t.Fatalf("输出失败: %v", err)
+	}
+	expected := "NAME    IP\n数据库  10.0.0.1\nweb 01  10.0.0.2\n"
+	if buf.String() != expected {
+		t.Errorf("表格输出错误:\n%q\n期望:\n%q", buf.String(), expected)
+	}
+}
+
+// 测试格式解析
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("JSON"); err != nil || f != JSON {
+		t.Errorf("解析失败: %v %v", f, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("不支持的格式应返回错误")
+	}
+}
@@ -0,0 +1,295 @@
+package query
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/daihao4371/hostmanager/internal/fuzzy"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 支持的筛选字段
+var Fields = []string{"name", "ip", "user", "group", "tag", "port", "auth", "status", "fav"}
+
+// 字段别名
+var aliases = map[string]string{
+	"host":     "name",
+	"username": "user",
+	"tags":     "tag",
+	"favorite": "fav",
+}
+
+// 单个筛选条件，例如 -tag:legacy
+type filter struct {
+	field  string
+	values []string // 逗号分隔的多个值，任一匹配即可
+	negate bool
+}
+
+// 解析后的查询：所有筛选条件都需满足，其余文本做模糊匹配
+type Query struct {
+	filters []filter
+	text    string
+}
+
+// 解析查询语句，例如 "tag:prod user:root -tag:legacy web"
+// 值中包含空白时可用双引号：group:"生产 环境"；未知的字段按普通文本处理
+func Parse(input string) (*Query, error) {
+	q := &Query{}
+	var terms []string
+
+	for _, token := range tokenize(input) {
+		negate := false
+		body := token
+		if strings.HasPrefix(body, "-") && len(body) > 1 {
+			negate = true
+			body = body[1:]
+		}
+
+		key, value, found := strings.Cut(body, ":")
+		field := strings.ToLower(key)
+		if alias, ok := aliases[field]; ok {
+			field = alias
+		}
+		// 不是筛选字段的 "xxx:" 按普通文本匹配，例如主机描述中的 "db:primary"
+		if !found || !isFieldName(key) || !isKnownField(field) {
+			terms = append(terms, token)
+			continue
+		}
+		// 尚未输入值的条件（例如搜索框中正在输入 "tag:"）暂不生效
+		if value == "" {
+			continue
+		}
+
+		f := filter{field: field, negate: negate}
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				if err := validate(field, v); err != nil {
+					return nil, err
+				}
+				f.values = append(f.values, v)
+			}
+		}
+		q.filters = append(q.filters, f)
+	}
+
+	q.text = strings.Join(terms, " ")
+	return q, nil
+}
+
+// 生成一个筛选条件，值中含空白时加引号
+func Term(field, value string) string {
+	if strings.ContainsAny(value, " \t\"") {
+		value = strconv.Quote(value)
+	}
+	return field + ":" + value
+}
+
+// 查询是否为空（匹配所有主机）
+func (q *Query) IsEmpty() bool {
+	return len(q.filters) == 0 && q.text == ""
+}
+
+// 模糊匹配的文本部分
+func (q *Query) Text() string {
+	return q.text
+}
+
+// 是否需要主机的在线状态（调用方需先检查状态）
+func (q *Query) NeedsStatus() bool {
+	for _, f := range q.filters {
+		if f.field == "status" {
+			return true
+		}
+	}
+	return false
+}
+
+// 判断主机是否满足查询，返回模糊匹配结果（用于排序和高亮）
+func (q *Query) Match(group string, host models.Host) (fuzzy.HostMatch, bool) {
+	for _, f := range q.filters {
+		if f.matches(group, host) == f.negate {
+			return fuzzy.HostMatch{}, false
+		}
+	}
+	return fuzzy.MatchHost(q.text, host)
+}
+
+// 筛选分组中的主机，去掉没有匹配主机的分组
+func (q *Query) FilterGroups(groups []models.Group) []models.Group {
+	result := []models.Group{}
+	for _, group := range groups {
+		filtered := group
+		filtered.Hosts = []models.Host{}
+		for _, host := range group.Hosts {
+			if _, ok := q.Match(group.Name, host); ok {
+				filtered.Hosts = append(filtered.Hosts, host)
+			}
+		}
+		if len(filtered.Hosts) > 0 {
+			result = append(result, filtered)
+		}
+	}
+	return result
+}
+
+// 任一值匹配即满足条件
+func (f filter) matches(group string, host models.Host) bool {
+	for _, value := range f.values {
+		if f.matchValue(group, host, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f filter) matchValue(group string, host models.Host, value string) bool {
+	switch f.field {
+	case "name":
+		return glob(value, host.Name)
+	case "ip":
+		return glob(value, host.IP)
+	case "user":
+		return glob(value, host.Username)
+	case "group":
+		return glob(value, group)
+	case "tag":
+		for _, tag := range host.Tags {
+			if glob(value, tag) {
+				return true
+			}
+		}
+		return false
+	case "port":
+		port, _ := strconv.Atoi(value)
+		return host.Port == port
+	case "auth":
+		return strings.EqualFold(host.AuthType, value)
+	case "status":
+		status := host.Status
+		if status == "" {
+			status = "unknown"
+		}
+		return strings.EqualFold(status, value)
+	case "fav":
+		fav, _ := parseBool(value)
+		return host.Favorite == fav
+	}
+	return false
+}
+
+// 检查字段值是否合法
+func validate(field, value string) error {
+	switch field {
+	case "port":
+		if port, err := strconv.Atoi(value); err != nil || port <= 0 || port > 65535 {
+			return fmt.Errorf("无效的端口: %s", value)
+		}
+	case "fav":
+		if _, err := parseBool(value); err != nil {
+			return fmt.Errorf("无效的 fav 值: %s (应为 true/false)", value)
+		}
+	case "status":
+		switch strings.ToLower(value) {
+		case "online", "offline", "unknown":
+		default:
+			return fmt.Errorf("无效的状态: %s (应为 online/offline/unknown)", value)
+		}
+	case "auth":
+		switch strings.ToLower(value) {
+		case "key", "password":
+		default:
+			return fmt.Errorf("无效的认证方式: %s (应为 key/password)", value)
+		}
+	default:
+		if _, err := path.Match(strings.ToLower(value), ""); err != nil {
+			return fmt.Errorf("无效的匹配模式: %s", value)
+		}
+	}
+	return nil
+}
+
+// 忽略大小写的通配符匹配（支持 * ? []）
+func glob(pattern, value string) bool {
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+	return ok
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "1":
+		return true, nil
+	case "false", "no", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("无效的布尔值: %s", value)
+}
+
+// 只由字母组成的前缀才视为字段名（避免把 10.0.0.1:22 之类的文本当作条件）
+func isFieldName(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isKnownField(field string) bool {
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// 按空白拆分，双引号内的空白保留
+func tokenize(input string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuote, hasToken := false, false
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			hasToken = true
+		case (r == ' ' || r == '\t') && !inQuote:
+			if hasToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				hasToken = false
+			}
+		default:
+			current.WriteRune(r)
+			hasToken = true
+		}
+	}
+	if hasToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// 把命令行参数拼接为查询语句（shell 已去掉的引号按需补回）
+func Join(args []string) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = arg
+		if !strings.ContainsAny(arg, " \t") {
+			continue
+		}
+		if key, value, found := strings.Cut(arg, ":"); found && isFieldName(strings.TrimPrefix(key, "-")) {
+			parts[i] = key + ":" + strconv.Quote(value)
+		} else {
+			parts[i] = strconv.Quote(arg)
+		}
+	}
+	return strings.Join(parts, " ")
+}
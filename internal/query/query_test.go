@@ -0,0 +1,102 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 创建测试用的分组
+func createTestGroups() []models.Group {
+	return []models.Group{
+		{Name: "生产环境", Hosts: []models.Host{
+			{Name: "web-01", IP: "10.0.1.1", Port: 22, Username: "root", AuthType: "key", Tags: []string{"prod", "web"}, Favorite: true, Status: "online"},
+			{Name: "web-old", IP: "10.0.1.9", Port: 2222, Username: "root", AuthType: "password", Tags: []string{"prod", "legacy"}, Status: "offline"},
+		}},
+		{Name: "开发环境", Hosts: []models.Host{
+			{Name: "dev", IP: "192.168.1.100", Port: 22, Username: "developer", AuthType: "key", Tags: []string{"dev"}},
+		}},
+	}
+}
+
+// 按查询筛选出主机名称
+func matchNames(t *testing.T, input string) []string {
+	t.Helper()
+	q, err := Parse(input)
+	if err != nil {
+		t.Fatalf("解析 %q 失败: %v", input, err)
+	}
+	var names []string
+	for _, group := range q.FilterGroups(createTestGroups()) {
+		for _, host := range group.Hosts {
+			names = append(names, host.Name)
+		}
+	}
+	return names
+}
+
+// 测试各类筛选条件
+func TestFilters(t *testing.T) {
+	cases := map[string]string{
+		"tag:prod -tag:legacy":        "web-01",
+		"user:root port:2222":         "web-old",
+		"group:开发环境":                  "dev",
+		`group:"生产环境" status:offline`: "web-old",
+		"fav:true":                    "web-01",
+		"ip:192.168.*":                "dev",
+		"auth:password":               "web-old",
+		"status:unknown":              "dev",
+		"tag:dev,legacy -name:dev":    "web-old",
+		"tag:prod old":                "web-old",
+	}
+	for input, expected := range cases {
+		names := matchNames(t, input)
+		if len(names) != 1 || names[0] != expected {
+			t.Errorf("%q 匹配结果 %v，期望 [%s]", input, names, expected)
+		}
+	}
+
+	if names := matchNames(t, ""); len(names) != 3 {
+		t.Errorf("空查询应匹配所有主机: %v", names)
+	}
+	// 尚未输入值的条件不生效，IP:端口 形式按普通文本处理
+	if names := matchNames(t, "tag:"); len(names) != 3 {
+		t.Errorf("不完整的条件不应过滤: %v", names)
+	}
+	if names := matchNames(t, "10.0.1.1"); len(names) != 1 {
+		t.Errorf("普通文本匹配错误: %v", names)
+	}
+	// 未知字段按普通文本处理
+	if q, err := Parse("tga:prod web"); err != nil || len(q.filters) != 0 || q.Text() != "tga:prod web" {
+		t.Errorf("未知字段应按普通文本处理: %+v %v", q, err)
+	}
+}
+
+// 测试解析错误
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{"port:abc", "fav:maybe", "status:up", "name:[web"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("%q 应返回错误", input)
+		}
+	}
+}
+
+// 测试生成条件
+func TestTerm(t *testing.T) {
+	q, err := Parse(Term("group", "生产 环境") + " " + Term("tag", "web"))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(q.filters) != 2 || q.filters[0].values[0] != "生产 环境" || q.Text() != "" {
+		t.Errorf("解析结果错误: %+v", q)
+	}
+}
+
+// 测试命令行参数拼接
+func TestJoin(t *testing.T) {
+	joined := Join([]string{"group:生产 环境", "-tag:legacy", "web server"})
+	expected := `group:"生产 环境" -tag:legacy "web server"`
+	if joined != expected {
+		t.Errorf("拼接结果 %s，期望 %s", joined, expected)
+	}
+}
@@ -0,0 +1,412 @@
+package ssh
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/term"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+	"github.com/daihao4371/hostmanager/internal/hostkey"
+	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/vault"
+)
+
+// 建立连接的超时时间
+const dialTimeout = 10 * time.Second
+
+// 使用内置客户端连接主机，配置了跳板机时依次经过跳板机
+func Dial(host models.Host) (*ssh.Client, error) {
+	return dial(host, true)
+}
+
+// 建立到目标主机的连接，interactive 为 false 时不会在终端提示输入
+func dial(host models.Host, interactive bool) (*ssh.Client, error) {
+	if host.JumpError != nil {
+		return nil, host.JumpError
+	}
+	hops := append(append([]models.Host{}, host.Via...), host)
+
+	var jumps []*ssh.Client
+	closeJumps := func() {
+		for i := len(jumps) - 1; i >= 0; i-- {
+			jumps[i].Close()
+		}
+	}
+
+	var current *ssh.Client
+	for _, hop := range hops {
+		client, err := dialHop(current, hop, interactive)
+		if err != nil {
+			if current != nil {
+				current.Close()
+			}
+			closeJumps()
+			return nil, err
+		}
+		if current != nil {
+			jumps = append(jumps, current)
+		}
+		current = client
+	}
+
+	// 目标连接关闭后释放跳板机连接
+	if len(jumps) > 0 {
+		go func() {
+			current.Wait()
+			closeJumps()
+		}()
+	}
+	return current, nil
+}
+
+// 连接单跳：via 为空时直连，否则通过上一跳转发
+func dialHop(via *ssh.Client, host models.Host, interactive bool) (*ssh.Client, error) {
+	clientConfig, err := newClientConfig(host, interactive)
+	if err != nil {
+		return nil, err
+	}
+
+	address := net.JoinHostPort(host.IP, strconv.Itoa(host.Port))
+	if via == nil {
+		client, err := ssh.Dial("tcp", address, clientConfig)
+		if err != nil {
+			return nil, fmt.Errorf("连接 %s (%s) 失败: %w", host.Name, address, err)
+		}
+		return client, nil
+	}
+
+	conn, err := dialThrough(via, address, connectTimeout(host))
+	if err != nil {
+		return nil, fmt.Errorf("经跳板机连接 %s (%s) 失败: %v", host.Name, address, err)
+	}
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, address, clientConfig)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("连接 %s (%s) 失败: %w", host.Name, address, err)
+	}
+	return ssh.NewClient(clientConn, chans, reqs), nil
+}
+
+// 通过已建立的连接打开到目标地址的TCP通道（带超时）
+func dialThrough(via *ssh.Client, address string, timeout time.Duration) (net.Conn, error) {
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := via.Dial("tcp", address)
+		done <- result{conn, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.conn, r.err
+	case <-time.After(timeout):
+		// 超时后仍可能建立成功，需要关闭
+		go func() {
+			if r := <-done; r.conn != nil {
+				r.conn.Close()
+			}
+		}()
+		return nil, fmt.Errorf("连接超时")
+	}
+}
+
+// 构建SSH客户端配置
+func newClientConfig(host models.Host, interactive bool) (*ssh.ClientConfig, error) {
+	auths, err := authMethods(host, interactive)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssh.ClientConfig{
+		User:              host.Username,
+		Auth:              auths,
+		HostKeyCallback:   hostKeyCallback(host, interactive),
+		HostKeyAlgorithms: hostkey.Algorithms(hostkey.Address(host.IP, host.Port)),
+		Timeout:           connectTimeout(host),
+	}, nil
+}
+
+// 连接超时：主机未配置 connect_timeout 时使用默认值
+func connectTimeout(host models.Host) time.Duration {
+	if host.ConnectTimeout > 0 {
+		return time.Duration(host.ConnectTimeout) * time.Second
+	}
+	return dialTimeout
+}
+
+// 主机密钥校验：与 known_hosts 中的记录比对。首次连接时在终端显示指纹请用户确认，
+// 非交互模式下拒绝未知主机；密钥改变时给出警告并拒绝连接
+func hostKeyCallback(host models.Host, interactive bool) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		address := hostkey.Address(host.IP, host.Port)
+		err := hostkey.Check(address, key)
+		var unknown *hostkey.UnknownError
+		var changed *hostkey.ChangedError
+		switch {
+		case errors.As(err, &changed):
+			if interactive {
+				fmt.Fprint(os.Stderr, changed.Warning(host.Name))
+			}
+		case errors.As(err, &unknown) && interactive && term.IsTerminal(int(os.Stdin.Fd())):
+			return confirmHostKey(host, address, key)
+		}
+		return err
+	}
+}
+
+// 首次连接时显示主机密钥指纹，用户确认后记录到 known_hosts
+func confirmHostKey(host models.Host, address string, key ssh.PublicKey) error {
+	fmt.Printf("🔐 首次连接 %s (%s)，无法确认主机身份\n", host.Name, address)
+	fmt.Printf("   %s 密钥指纹: %s\n", key.Type(), hostkey.Fingerprint(key))
+	answer, err := promptLine("是否信任该主机并继续连接? (yes/no): ")
+	if err != nil {
+		return err
+	}
+	if answer = strings.ToLower(answer); answer != "yes" && answer != "y" {
+		return fmt.Errorf("未确认主机密钥，已取消连接")
+	}
+	if err := hostkey.Add(address, key); err != nil {
+		return fmt.Errorf("记录主机密钥失败: %v", err)
+	}
+	fmt.Printf("✅ 已将 %s 的主机密钥记录到 %s\n", host.Name, hostkey.DefaultPath())
+	return nil
+}
+
+// 获取主机公钥（不登录目标主机），配置了跳板机时经由跳板机连接
+func ScanHostKey(host models.Host) (ssh.PublicKey, error) {
+	if host.JumpError != nil {
+		return nil, host.JumpError
+	}
+	address := net.JoinHostPort(host.IP, strconv.Itoa(host.Port))
+
+	var conn net.Conn
+	var err error
+	if len(host.Via) == 0 {
+		conn, err = net.DialTimeout("tcp", address, connectTimeout(host))
+	} else {
+		bastion := host.Via[len(host.Via)-1]
+		bastion.Via = host.Via[:len(host.Via)-1]
+		client, dialErr := Dial(bastion)
+		if dialErr != nil {
+			return nil, dialErr
+		}
+		defer client.Close()
+		conn, err = dialThrough(client, address, connectTimeout(host))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("连接 %s (%s) 失败: %v", host.Name, address, err)
+	}
+	defer conn.Close()
+
+	// 收到主机密钥后立即中断握手
+	var scanned ssh.PublicKey
+	errScanned := errors.New("已获取主机密钥")
+	config := &ssh.ClientConfig{
+		User: host.Username,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			scanned = key
+			return errScanned
+		},
+		HostKeyAlgorithms: hostkey.Algorithms(hostkey.Address(host.IP, host.Port)),
+		Timeout:           connectTimeout(host),
+	}
+	conn.SetDeadline(time.Now().Add(connectTimeout(host)))
+	if _, _, _, err := ssh.NewClientConn(conn, address, config); scanned == nil {
+		return nil, fmt.Errorf("获取 %s (%s) 的主机密钥失败: %v", host.Name, address, err)
+	}
+	return scanned, nil
+}
+
+// 根据主机配置生成认证方式：密钥、ssh-agent、密码、键盘交互
+func authMethods(host models.Host, interactive bool) ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+	var signers []ssh.Signer
+
+	if host.AuthType == "key" && host.KeyPath != "" {
+		if _, err := os.Stat(fsutil.ExpandPath(host.KeyPath)); os.IsNotExist(err) {
+			// 私钥不存在时不中断，继续尝试 ssh-agent 和密码认证
+			fmt.Printf("⚠️  私钥文件不存在: %s\n", host.KeyPath)
+		} else {
+			signer, err := loadPrivateKey(host.KeyPath, interactive)
+			if err != nil {
+				return nil, err
+			}
+			signers = append(signers, signer)
+		}
+	}
+
+	// 有 ssh-agent 时一并尝试其中的密钥
+	if agentSigners := agentSigners(); len(agentSigners) > 0 {
+		signers = append(signers, agentSigners...)
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+
+	// 非交互模式下只使用已保存的密码
+	if !interactive {
+		if host.HasStoredPassword() {
+			methods = append(methods, ssh.PasswordCallback(func() (string, error) {
+				return storedPassword(host, false)
+			}))
+		}
+		return methods, nil
+	}
+
+	methods = append(methods,
+		ssh.PasswordCallback(func() (string, error) {
+			return passwordFor(host)
+		}),
+		ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+			return answerKeyboardInteractive(host, instruction, questions, echos)
+		}),
+	)
+	return methods, nil
+}
+
+// 读取私钥，加密私钥在交互模式下会提示输入口令
+func loadPrivateKey(keyPath string, interactive bool) (ssh.Signer, error) {
+	path := fsutil.ExpandPath(keyPath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取私钥 %s 失败: %v", path, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) && interactive {
+		passphrase, promptErr := promptSecret(fmt.Sprintf("私钥 %s 的口令: ", path))
+		if promptErr != nil {
+			return nil, promptErr
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析私钥 %s 失败: %v", path, err)
+	}
+	return signer, nil
+}
+
+// 进程内共享的 ssh-agent 连接，避免每次连接（每个跳板机、每次重连）都打开新的套接字
+var sharedAgent struct {
+	sync.Mutex
+	socket string
+	conn   net.Conn
+	client agent.ExtendedAgent
+}
+
+// 获取 ssh-agent 中的密钥；连接失效（例如 agent 重启）时重新连接一次
+func agentSigners() []ssh.Signer {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil
+	}
+	sharedAgent.Lock()
+	defer sharedAgent.Unlock()
+	for attempt := 0; attempt < 2; attempt++ {
+		if sharedAgent.client == nil || sharedAgent.socket != socket {
+			if sharedAgent.conn != nil {
+				sharedAgent.conn.Close()
+			}
+			conn, err := net.Dial("unix", socket)
+			if err != nil {
+				sharedAgent.conn, sharedAgent.client = nil, nil
+				return nil
+			}
+			sharedAgent.socket, sharedAgent.conn, sharedAgent.client = socket, conn, agent.NewClient(conn)
+		}
+		if signers, err := sharedAgent.client.Signers(); err == nil {
+			return signers
+		}
+		sharedAgent.conn.Close()
+		sharedAgent.conn, sharedAgent.client = nil, nil
+	}
+	return nil
+}
+
+// 获取主机密码：保险库 > 明文配置 > 终端提示输入
+func passwordFor(host models.Host) (string, error) {
+	if host.HasStoredPassword() {
+		return storedPassword(host, true)
+	}
+	return promptSecret(fmt.Sprintf("%s@%s 的密码: ", host.Username, host.IP))
+}
+
+// 获取已保存的密码，非交互模式下保险库未解锁时直接返回错误
+func storedPassword(host models.Host, interactive bool) (string, error) {
+	if host.PasswordRef != "" {
+		if interactive {
+			return vault.Lookup(host.PasswordRef)
+		}
+		return vault.LookupCached(host.PasswordRef)
+	}
+	return host.Password, nil
+}
+
+// 应答键盘交互认证，密码类问题优先使用已配置的密码
+func answerKeyboardInteractive(host models.Host, instruction string, questions []string, echos []bool) ([]string, error) {
+	if instruction != "" {
+		fmt.Println(instruction)
+	}
+
+	answers := make([]string, len(questions))
+	for i, question := range questions {
+		if !echos[i] && host.HasStoredPassword() && strings.Contains(strings.ToLower(question), "password") {
+			password, err := passwordFor(host)
+			if err != nil {
+				return nil, err
+			}
+			answers[i] = password
+			continue
+		}
+
+		var err error
+		if echos[i] {
+			answers[i], err = promptLine(question)
+		} else {
+			answers[i], err = promptSecret(question)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return answers, nil
+}
+
+// 在终端提示输入（不回显）
+func promptSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("无法读取输入: 标准输入不是终端")
+	}
+	fmt.Print(prompt)
+	secret, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// 在终端提示输入（回显）
+func promptLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -1,19 +1,34 @@
 package ssh
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+	"github.com/daihao4371/hostmanager/internal/hostkey"
 	"github.com/daihao4371/hostmanager/internal/models"
 )
 
-// 检查主机连通性
+// 检查主机连通性，配置了跳板机时通过跳板机检查；跳板机配置错误时无法判断，返回 unknown
 func CheckHostStatus(host models.Host) string {
+	if host.JumpError != nil {
+		return "unknown"
+	}
 	address := net.JoinHostPort(host.IP, strconv.Itoa(host.Port))
+	if len(host.Via) > 0 {
+		return checkStatusThroughJump(host, address)
+	}
+
 	conn, err := net.DialTimeout("tcp", address, 3*time.Second)
 	if err != nil {
 		return "offline"
@@ -22,6 +37,31 @@ func CheckHostStatus(host models.Host) string {
 	return "online"
 }
 
+// 登录最后一跳跳板机后探测目标端口（不会提示输入密码）；
+// 跳板机的主机密钥未记录或已改变时无法判断目标状态，返回 unknown
+func checkStatusThroughJump(host models.Host, address string) string {
+	bastion := host.Via[len(host.Via)-1]
+	bastion.Via = host.Via[:len(host.Via)-1]
+
+	client, err := dial(bastion, false)
+	if err != nil {
+		var unknown *hostkey.UnknownError
+		var changed *hostkey.ChangedError
+		if errors.As(err, &unknown) || errors.As(err, &changed) {
+			return "unknown"
+		}
+		return "offline"
+	}
+	defer client.Close()
+
+	conn, err := dialThrough(client, address, 3*time.Second)
+	if err != nil {
+		return "offline"
+	}
+	conn.Close()
+	return "online"
+}
+
 // 检查expect工具是否可用
 func CheckExpectAvailable() bool {
 	_, err := exec.LookPath("expect")
@@ -38,29 +78,83 @@ func CheckLrzszAvailable() bool {
 // 检查 Zmodem 支持的综合状态
 func CheckZmodemSupport() (bool, string) {
 	if !CheckLrzszAvailable() {
-		return false, "系统缺少 lrzsz 工具包，请安装：brew install lrzsz (macOS) 或 apt install lrzsz (Ubuntu)"
+		return false, "系统缺少 lrzsz 工具包，请安装：brew install lrzsz (macOS) 或 apt install lrzsz (Ubuntu)，或使用 hostmanager cp 传输文件"
 	}
 	return true, ""
 }
 
-// 创建expect脚本进行SSH密码认证（支持Zmodem）
+// 传递密码给 expect 脚本的环境变量，脚本读取后立即删除；跳板机的密码使用带序号的变量
+const expectPasswordEnv = "HOSTMANAGER_SSH_PASSWORD"
+
+// 第 i 个跳板机（从 1 开始）的密码环境变量
+func hopPasswordEnv(i int) string {
+	return fmt.Sprintf("%s_%d", expectPasswordEnv, i)
+}
+
+// 匹配 ssh 对某个主机的密码提示，包括 "user@host's password:" 和
+// 键盘交互认证的 "(user@host) Password:"，避免把目标主机的密码发给跳板机
+func passwordPrompt(host models.Host) string {
+	return `(^|[\s(])` + regexp.QuoteMeta(host.Username+"@"+host.IP) + `('s password|\) Password):`
+}
+
+// 创建expect脚本进行SSH密码认证（支持Zmodem）。
+// 脚本中不包含密码：运行时通过 expectPasswordEnv 等环境变量传入，不会写入磁盘，
+// 也不会被当作 Tcl 代码解析。每个密码只应答对应主机的提示，
+// 没有保存密码的跳板机由用户在终端输入
 func CreateExpectScript(host models.Host) (string, error) {
 	// 构建SSH参数，支持Zmodem时添加必要选项
 	sshArgs := fmt.Sprintf("-p %d", host.Port)
+	if host.ConnectTimeout > 0 {
+		sshArgs += fmt.Sprintf(" -o ConnectTimeout=%d", host.ConnectTimeout)
+	}
+	if jump := proxyJumpArg(host); jump != "" {
+		sshArgs += " -J " + jump
+	}
 	if host.IsZmodemEnabled() {
 		// 启用 Zmodem 支持需要的 SSH 选项
 		sshArgs += " -o RequestTTY=yes"
 	}
+	for _, arg := range forwardArgs(host) {
+		sshArgs += " " + arg
+	}
+	// 选项值中可能包含空格，用 Tcl 的花括号保持为一个参数
+	sshArgs += " -o {" + knownHostsOption() + "}"
 
+	var passwords, prompts strings.Builder
+	addPassword := func(variable, env string) {
+		fmt.Fprintf(&passwords, "set %s $env(%s)\nunset env(%s)\n", variable, env, env)
+	}
+	addPassword("password", expectPasswordEnv)
+	for i, hop := range host.Via {
+		if !hop.HasStoredPassword() {
+			continue
+		}
+		variable := fmt.Sprintf("hop_password_%d", i+1)
+		addPassword(variable, hopPasswordEnv(i+1))
+		fmt.Fprintf(&prompts, "    -re {%s} {\n        send -- \"$%s\\r\"\n        exp_continue\n    }\n", passwordPrompt(hop), variable)
+	}
+
+	// 没有跳板机时其他格式的密码提示只可能来自目标主机，否则交给用户在 interact 中输入
+	fallback := "{}"
+	if len(host.Via) == 0 {
+		fallback = `{ send -- "$password\r" }`
+	}
+
+	// 首次连接时 ssh 会显示主机密钥指纹，由用户输入 yes/no 确认，不自动应答
 	scriptContent := fmt.Sprintf(`#!/usr/bin/expect -f
-set timeout 30
+%sset timeout 30
 spawn ssh %s %s@%s
 expect {
-    "yes/no" { send "yes\r"; exp_continue }
-    "password:" { send "%s\r" }
+    "yes/no" {
+        expect_user -timeout -1 -re "(.*)\n"
+        send "$expect_out(1,string)\r"
+        exp_continue
+    }
+%s    -re {%s} { send -- "$password\r" }
+    -re {[Pp]assword:} %s
 }
 interact
-`, sshArgs, host.Username, host.IP, host.Password)
+`, passwords.String(), sshArgs, host.Username, host.IP, prompts.String(), passwordPrompt(host), fallback)
 
 	tmpFile, err := os.CreateTemp("", "ssh_expect_*.exp")
 	if err != nil {
@@ -73,7 +167,7 @@ interact
 		return "", err
 	}
 
-	err = os.Chmod(tmpFile.Name(), 0755)
+	err = os.Chmod(tmpFile.Name(), 0700)
 	if err != nil {
 		return "", err
 	}
@@ -81,13 +175,64 @@ interact
 	return tmpFile.Name(), nil
 }
 
-// SSH连接函数
-func Connect(host models.Host, onConnect func(models.Host)) {
+// SSH连接函数，默认使用内置客户端，主机配置 connect_mode: external 时使用系统 ssh
+func Connect(host models.Host, onConnect func(models.Host)) error {
+	if host.JumpError != nil {
+		fmt.Printf("连接失败: %v\n", host.JumpError)
+		return host.JumpError
+	}
+
 	// 添加到连接历史
 	if onConnect != nil {
 		onConnect(host)
 	}
 
+	if host.UsesExternalSSH() {
+		return connectExternal(host)
+	}
+	return connectNative(host)
+}
+
+// 使用内置客户端连接
+func connectNative(host models.Host) error {
+	printConnectBanner(host)
+
+	if host.IsZmodemEnabled() {
+		// 内置客户端透传终端数据，由本地终端处理 Zmodem 传输
+		if supported, msg := CheckZmodemSupport(); !supported {
+			fmt.Printf("⚠️  Zmodem 不可用: %s\n", msg)
+		} else {
+			fmt.Printf("📁 Zmodem 文件传输已启用 (sz/rz 命令可用)\n")
+		}
+	}
+
+	client, err := Dial(host)
+	if err != nil {
+		fmt.Printf("连接失败: %v\n", err)
+		return err
+	}
+	defer client.Close()
+
+	// 与 ssh 一致，端口转发失败时给出警告但不中断会话
+	if len(host.Forwards) > 0 {
+		stop, err := startForwards(client, host.Forwards)
+		if err != nil {
+			fmt.Printf("⚠️  端口转发失败: %v\n", err)
+		} else {
+			defer stop()
+		}
+	}
+
+	err = runInteractiveSession(client)
+	if err != nil && !IsExitError(err) {
+		fmt.Printf("连接失败: %v\n", err)
+	}
+	fmt.Printf("\n📋 与 %s 的连接已断开\n", host.Name)
+	return err
+}
+
+// 使用系统 ssh 命令连接（密码认证依赖 expect）
+func connectExternal(host models.Host) error {
 	var cmd *exec.Cmd
 
 	// 构建SSH连接命令
@@ -95,45 +240,63 @@ func Connect(host models.Host, onConnect func(models.Host)) {
 
 	// 处理认证方式
 	if host.AuthType == "key" && host.KeyPath != "" {
-		sshArgs = append(sshArgs, "-i", host.KeyPath)
-	} else if host.AuthType == "password" || (host.AuthType == "key" && host.KeyPath == "" && host.Password != "") {
+		sshArgs = append(sshArgs, "-i", fsutil.ExpandPath(host.KeyPath))
+	} else if host.AuthType == "password" || (host.AuthType == "key" && host.KeyPath == "" && host.HasStoredPassword()) {
 		if !CheckExpectAvailable() {
 			fmt.Printf("错误: 系统缺少 expect 工具来支持密码认证\n")
 			fmt.Printf("请手动输入密码进行连接\n")
 		} else {
+			// expect 需要明文密码，从保险库解析后通过环境变量传入
+			password, err := storedPassword(host, true)
+			if err != nil {
+				fmt.Printf("读取保险库密码失败: %v\n", err)
+				return err
+			}
+
 			scriptPath, err := CreateExpectScript(host)
 			if err != nil {
 				fmt.Printf("创建expect脚本失败: %v\n", err)
 				// 不在这里等待输入，让UI层处理
-				return
+				return err
 			}
 
 			defer func() {
 				os.Remove(scriptPath)
 			}()
 
+			env := append(os.Environ(), expectPasswordEnv+"="+password)
+			for i, hop := range host.Via {
+				if !hop.HasStoredPassword() {
+					continue
+				}
+				hopPassword, err := storedPassword(hop, true)
+				if err != nil {
+					fmt.Printf("读取跳板机 %s 的密码失败: %v\n", hop.Name, err)
+					return err
+				}
+				env = append(env, hopPasswordEnv(i+1)+"="+hopPassword)
+			}
+
 			cmd = exec.Command("expect", scriptPath)
+			cmd.Env = env
 			cmd.Stdin = os.Stdin
 			cmd.Stdout = os.Stdout
 			cmd.Stderr = os.Stderr
 
-			fmt.Printf("\n🔗 正在连接到 %s (%s@%s:%d)...\n", host.Name, host.Username, host.IP, host.Port)
-			fmt.Printf("💡 提示: 连接断开后将自动返回主菜单\n")
-			fmt.Printf("═══════════════════════════════════════════════════════════\n")
+			printConnectBanner(host)
 			err = cmd.Run()
 			if err != nil {
 				fmt.Printf("连接失败: %v\n", err)
 			}
 			fmt.Printf("\n📋 与 %s 的连接已断开\n", host.Name)
 			// 不在这里等待输入，让UI层处理
-			return
+			return err
 		}
 	}
 
-	// 添加端口参数
-	if host.Port != 22 {
-		sshArgs = append(sshArgs, "-p", strconv.Itoa(host.Port))
-	}
+	// 添加端口、跳板机和端口转发参数
+	sshArgs = append(sshArgs, endpointArgs(host)...)
+	sshArgs = append(sshArgs, forwardArgs(host)...)
 
 	// 添加 Zmodem 支持参数
 	if host.IsZmodemEnabled() {
@@ -157,13 +320,93 @@ func Connect(host models.Host, onConnect func(models.Host)) {
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
-	fmt.Printf("\n🔗 正在连接到 %s (%s@%s:%d)...\n", host.Name, host.Username, host.IP, host.Port)
-	fmt.Printf("💡 提示: 连接断开后将自动返回主菜单\n")
-	fmt.Printf("═══════════════════════════════════════════════════════════\n")
+	printConnectBanner(host)
 	err := cmd.Run()
 	if err != nil {
 		fmt.Printf("连接失败: %v\n", err)
 	}
 	fmt.Printf("\n📋 与 %s 的连接已断开\n", host.Name)
 	// 不在这里等待输入，让UI层统一处理
-}
\ No newline at end of file
+	return err
+}
+
+// 系统 ssh 的端口、跳板机和 known_hosts 参数
+func endpointArgs(host models.Host) []string {
+	args := []string{"-o", knownHostsOption()}
+	if host.Port != 22 {
+		args = append(args, "-p", strconv.Itoa(host.Port))
+	}
+	if host.ConnectTimeout > 0 {
+		args = append(args, "-o", fmt.Sprintf("ConnectTimeout=%d", host.ConnectTimeout))
+	}
+	if jump := proxyJumpArg(host); jump != "" {
+		args = append(args, "-J", jump)
+	}
+	return args
+}
+
+// 让系统 ssh 与内置客户端使用相同的 known_hosts：新密钥记录到 hostmanager 管理的文件
+func knownHostsOption() string {
+	files := []string{hostkey.DefaultPath(), hostkey.UserPath()}
+	// ssh 不会创建 known_hosts 所在的目录
+	os.MkdirAll(filepath.Dir(files[0]), 0700)
+	for i, file := range files {
+		if strings.ContainsAny(file, " \t") {
+			files[i] = `"` + file + `"`
+		}
+	}
+	return "UserKnownHostsFile=" + strings.Join(files, " ")
+}
+
+// 系统 ssh 的端口转发参数，例如 -L 127.0.0.1:5432:db:5432
+func forwardArgs(host models.Host) []string {
+	var args []string
+	for _, forward := range host.Forwards {
+		args = append(args, forward.Flag(), forward.Spec())
+	}
+	return args
+}
+
+// 生成 ssh -J 参数，例如 "admin@10.0.0.1:22,ops@10.0.1.1:2222"
+func proxyJumpArg(host models.Host) string {
+	hops := make([]string, len(host.Via))
+	for i, hop := range host.Via {
+		hops[i] = fmt.Sprintf("%s@%s", hop.Username, net.JoinHostPort(hop.IP, strconv.Itoa(hop.Port)))
+	}
+	return strings.Join(hops, ",")
+}
+
+// 打印连接提示
+func printConnectBanner(host models.Host) {
+	fmt.Printf("\n🔗 正在连接到 %s (%s@%s:%d)...\n", host.Name, host.Username, host.IP, host.Port)
+	if len(host.Via) > 0 {
+		fmt.Printf("🛡️  经由跳板机: %s\n", host.ViaDescription())
+	}
+	for _, forward := range host.Forwards {
+		fmt.Printf("🔀 端口转发: %s\n", forward)
+	}
+	fmt.Printf("💡 提示: 连接断开后将自动返回主菜单\n")
+	fmt.Printf("═══════════════════════════════════════════════════════════\n")
+}
+
+// 判断是否为远端Shell以非零状态退出（连接本身正常）
+func IsExitError(err error) bool {
+	var exitErr *gossh.ExitError
+	return errors.As(err, &exitErr)
+}
+
+// 会话的退出状态：正常结束为 0，远端Shell的退出码原样返回，连接失败为 -1
+func ExitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *gossh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus()
+	}
+	var cmdErr *exec.ExitError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.ExitCode()
+	}
+	return -1
+}
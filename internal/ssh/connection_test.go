@@ -0,0 +1,74 @@
+package ssh
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 测试 expect 脚本不包含密码，密码通过环境变量传入
+func TestCreateExpectScript(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOSTMANAGER_KNOWN_HOSTS", t.TempDir()+"/known_hosts")
+	host := models.Host{Name: "web", IP: "10.0.0.1", Port: 22, Username: "deploy", AuthType: "password", Password: `p"$[exit]\`}
+	path, err := CreateExpectScript(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := string(data)
+	if strings.Contains(script, host.Password) {
+		t.Error("脚本中不应包含密码")
+	}
+	if !strings.Contains(script, "$env("+expectPasswordEnv+")") || !strings.Contains(script, "unset env("+expectPasswordEnv+")") {
+		t.Errorf("脚本应从环境变量读取密码并删除:\n%s", script)
+	}
+}
+
+// 测试经过跳板机时每个密码只应答对应主机的提示
+func TestCreateExpectScriptWithJump(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOSTMANAGER_KNOWN_HOSTS", t.TempDir()+"/known_hosts")
+	host := models.Host{
+		Name: "db", IP: "10.0.1.5", Port: 22, Username: "dba", AuthType: "password", Password: "t-secret",
+		Via: []models.Host{
+			{Name: "bastion", IP: "10.0.0.1", Port: 22, Username: "ops", AuthType: "password", Password: "h-secret"},
+			{Name: "inner", IP: "10.0.0.2", Port: 22, Username: "ops", AuthType: "key"},
+		},
+	}
+	path, err := CreateExpectScript(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := string(data)
+
+	for _, expected := range []string{
+		"-J ops@10.0.0.1:22,ops@10.0.0.2:22",
+		"$env(" + hopPasswordEnv(1) + ")",
+		"-re {" + passwordPrompt(host.Via[0]) + "}",
+		"-re {" + passwordPrompt(host) + "} { send -- \"$password\\r\" }",
+		"-re {[Pp]assword:} {}",
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("脚本中缺少 %q:\n%s", expected, script)
+		}
+	}
+	if strings.Contains(script, hopPasswordEnv(2)) {
+		t.Error("未保存密码的跳板机不应读取密码")
+	}
+	if strings.Contains(script, "t-secret") || strings.Contains(script, "h-secret") {
+		t.Error("脚本中不应包含密码")
+	}
+}
@@ -0,0 +1,76 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 在远端主机上执行命令（非交互），返回命令退出码；连接失败时返回 -1 和错误
+func RunCommand(host models.Host, command string, stdout, stderr io.Writer) (int, error) {
+	if host.JumpError != nil {
+		return -1, host.JumpError
+	}
+	if host.UsesExternalSSH() {
+		return runExternalCommand(host, command, stdout, stderr)
+	}
+
+	client, err := dial(host, false)
+	if err != nil {
+		return -1, err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return -1, fmt.Errorf("创建会话失败: %v", err)
+	}
+	defer session.Close()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	err = session.Run(command)
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
+
+// 使用系统 ssh 执行命令（BatchMode，不会提示输入密码）
+func runExternalCommand(host models.Host, command string, stdout, stderr io.Writer) (int, error) {
+	args := []string{"-o", "BatchMode=yes"}
+	if host.AuthType == "key" && host.KeyPath != "" {
+		args = append(args, "-i", fsutil.ExpandPath(host.KeyPath))
+	}
+	args = append(args, endpointArgs(host)...)
+	args = append(args, fmt.Sprintf("%s@%s", host.Username, host.IP), command)
+
+	cmd := exec.Command("ssh", args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// ssh 自身出错时退出码为 255
+		if exitErr.ExitCode() == 255 {
+			return -1, fmt.Errorf("ssh 连接失败")
+		}
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,343 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 端口转发的运行状态
+type ForwardStats struct {
+	Forward models.Forward
+	Active  int64 // 当前连接数
+	Total   int64 // 累计连接数
+	Checked bool  // 是否已做过健康检查
+	Health  error // 最近一次健康检查的错误，通过时为 nil
+}
+
+// 单个端口转发：本地监听在整个隧道生命周期内保持，远端监听随连接重建
+type forwarder struct {
+	forward models.Forward
+	active  atomic.Int64
+	total   atomic.Int64
+
+	mu     sync.Mutex
+	client *ssh.Client
+	local  net.Listener // local、dynamic 的本地监听
+	remote net.Listener // remote 在远端的监听
+
+	checked bool
+	health  error
+
+	probeMu sync.Mutex
+	probes  map[string]bool // 健康检查探测连接的本地地址，不计入连接数
+}
+
+func newForwarder(forward models.Forward) *forwarder {
+	return &forwarder{forward: forward, probes: make(map[string]bool)}
+}
+
+func (f *forwarder) stats() ForwardStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return ForwardStats{Forward: f.forward, Active: f.active.Load(), Total: f.total.Load(), Checked: f.checked, Health: f.health}
+}
+
+// 开始本地监听（remote 转发在 attach 时监听）
+func (f *forwarder) listen() error {
+	if f.forward.Type == models.ForwardRemote {
+		return nil
+	}
+	listener, err := net.Listen("tcp", f.forward.ListenAddress())
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %v", f.forward.ListenAddress(), err)
+	}
+	f.local = listener
+	go f.serve(listener, f.handleLocal)
+	return nil
+}
+
+// 使用新建立的连接转发
+func (f *forwarder) attach(client *ssh.Client) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.client = client
+	if f.forward.Type != models.ForwardRemote {
+		return nil
+	}
+	listener, err := client.Listen("tcp", f.forward.ListenAddress())
+	if err != nil {
+		return fmt.Errorf("远端监听 %s 失败: %v", f.forward.ListenAddress(), err)
+	}
+	f.remote = listener
+	go f.serve(listener, f.handleRemote)
+	return nil
+}
+
+// 连接断开后停止使用该连接
+func (f *forwarder) detach() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.client = nil
+	if f.remote != nil {
+		f.remote.Close()
+		f.remote = nil
+	}
+}
+
+func (f *forwarder) close() {
+	f.detach()
+	if f.local != nil {
+		f.local.Close()
+	}
+}
+
+func (f *forwarder) currentClient() *ssh.Client {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.client
+}
+
+// 接受连接直到监听关闭
+func (f *forwarder) serve(listener net.Listener, handle func(net.Conn)) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			if f.isProbe(conn) {
+				conn.Close()
+				return
+			}
+			f.active.Add(1)
+			f.total.Add(1)
+			defer f.active.Add(-1)
+			handle(conn)
+		}()
+	}
+}
+
+// 健康检查：local/dynamic 确认本地端口可以连接，local 还经 SSH 连接目标，remote 确认本地目标可达。
+// restart 表示 SSH 连接本身异常，需要重新连接
+func (f *forwarder) check(client *ssh.Client) (restart bool, err error) {
+	defer func() {
+		f.mu.Lock()
+		f.checked, f.health = true, err
+		f.mu.Unlock()
+	}()
+
+	if f.forward.Type == models.ForwardRemote {
+		conn, err := net.DialTimeout("tcp", f.forward.Target, healthCheckTimeout)
+		if err != nil {
+			return false, fmt.Errorf("目标 %s 不可达: %v", f.forward.Target, err)
+		}
+		conn.Close()
+		return false, nil
+	}
+
+	if err := f.probe(); err != nil {
+		return false, fmt.Errorf("本地端口 %s 无法连接: %v", f.forward.ListenAddress(), err)
+	}
+	if f.forward.Type != models.ForwardLocal {
+		return false, nil
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		conn, err := client.Dial("tcp", f.forward.Target)
+		if err == nil {
+			conn.Close()
+		}
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		var openErr *ssh.OpenChannelError
+		if errors.As(err, &openErr) {
+			return false, fmt.Errorf("目标 %s 不可达: %s", f.forward.Target, openErr.Message)
+		}
+		if err != nil {
+			return true, fmt.Errorf("SSH 连接异常: %v", err)
+		}
+		return false, nil
+	case <-time.After(healthCheckTimeout):
+		return true, fmt.Errorf("连接目标 %s 超时", f.forward.Target)
+	}
+}
+
+// 连接本地监听端口；服务端据地址识别出探测连接后直接关闭，不计入连接数
+func (f *forwarder) probe() error {
+	f.probeMu.Lock()
+	conn, err := net.DialTimeout("tcp", f.forward.ListenAddress(), healthCheckTimeout)
+	if err == nil {
+		f.probes[conn.LocalAddr().String()] = true
+	}
+	f.probeMu.Unlock()
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (f *forwarder) isProbe(conn net.Conn) bool {
+	f.probeMu.Lock()
+	defer f.probeMu.Unlock()
+	addr := conn.RemoteAddr().String()
+	if !f.probes[addr] {
+		return false
+	}
+	delete(f.probes, addr)
+	return true
+}
+
+// 本地连接经 SSH 转发到目标地址；dynamic 先完成 SOCKS5 握手
+func (f *forwarder) handleLocal(conn net.Conn) {
+	defer conn.Close()
+	client := f.currentClient()
+	if client == nil {
+		return // 正在重连，拒绝新连接
+	}
+
+	target := f.forward.Target
+	if f.forward.Type == models.ForwardDynamic {
+		var err error
+		if target, err = socksHandshake(conn); err != nil {
+			return
+		}
+	}
+	remote, err := client.Dial("tcp", target)
+	if f.forward.Type == models.ForwardDynamic {
+		socksReply(conn, err)
+	}
+	if err != nil {
+		return
+	}
+	pipe(conn, remote)
+}
+
+// 远端连接转发到本地可达的目标地址
+func (f *forwarder) handleRemote(conn net.Conn) {
+	defer conn.Close()
+	local, err := net.DialTimeout("tcp", f.forward.Target, dialTimeout)
+	if err != nil {
+		return
+	}
+	pipe(conn, local)
+}
+
+// 双向复制数据，任一方向结束后关闭两端
+func pipe(a, b net.Conn) {
+	defer b.Close()
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(a, b)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(b, a)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// 建立主机配置中的全部端口转发，返回停止函数
+func startForwards(client *ssh.Client, forwards []models.Forward) (func(), error) {
+	var started []*forwarder
+	stop := func() {
+		for _, f := range started {
+			f.close()
+		}
+	}
+	for _, forward := range forwards {
+		f := newForwarder(forward)
+		if err := f.listen(); err != nil {
+			stop()
+			return nil, err
+		}
+		started = append(started, f)
+		if err := f.attach(client); err != nil {
+			stop()
+			return nil, err
+		}
+	}
+	return stop, nil
+}
+
+// SOCKS5 握手（仅支持无认证的 CONNECT），返回目标地址
+func socksHandshake(conn net.Conn) (string, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", err
+	}
+	if header[0] != 5 {
+		return "", errors.New("仅支持 SOCKS5")
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return "", err
+	}
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		return "", err
+	}
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return "", err
+	}
+	if request[1] != 1 {
+		conn.Write([]byte{5, 7, 0, 1, 0, 0, 0, 0, 0, 0}) // 不支持的命令
+		return "", errors.New("仅支持 CONNECT")
+	}
+
+	var host string
+	switch request[3] {
+	case 1: // IPv4
+		addr := make([]byte, 4)
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return "", err
+		}
+		host = net.IP(addr).String()
+	case 3: // 域名
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", err
+		}
+		name := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, name); err != nil {
+			return "", err
+		}
+		host = string(name)
+	case 4: // IPv6
+		addr := make([]byte, 16)
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return "", err
+		}
+		host = net.IP(addr).String()
+	default:
+		return "", errors.New("未知的地址类型")
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, fmt.Sprint(int(port[0])<<8|int(port[1]))), nil
+}
+
+// 回复 SOCKS5 CONNECT 结果
+func socksReply(conn net.Conn, err error) {
+	status := byte(0)
+	if err != nil {
+		status = 5 // 连接被拒绝
+	}
+	conn.Write([]byte{5, status, 0, 1, 0, 0, 0, 0, 0, 0})
+}
@@ -0,0 +1,391 @@
+package ssh
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/daihao4371/hostmanager/internal/hostkey"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 启动只支持密码认证和 direct-tcpip 通道的测试 SSH 服务器，ed25519 主机密钥记录在临时的 known_hosts 中，
+// extraKeys 是服务端额外持有但未记录的主机密钥
+func startTestServer(t *testing.T, extraKeys ...ssh.Signer) models.Host {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("密码错误")
+		},
+	}
+	config.AddHostKey(signer)
+	for _, extra := range extraKeys {
+		config.AddHostKey(extra)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestConn(conn, config)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOSTMANAGER_KNOWN_HOSTS", filepath.Join(t.TempDir(), "known_hosts"))
+	if err := hostkey.Add(hostkey.Address("127.0.0.1", addr.Port), signer.PublicKey()); err != nil {
+		t.Fatal(err)
+	}
+	return models.Host{Name: "test", IP: "127.0.0.1", Port: addr.Port, Username: "tester", AuthType: "password", Password: "secret"}
+}
+
+func serveTestConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		var payload struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		ssh.Unmarshal(newChannel.ExtraData(), &payload)
+		target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			target.Close()
+			continue
+		}
+		go ssh.DiscardRequests(requests)
+		go func() {
+			defer channel.Close()
+			defer target.Close()
+			go io.Copy(target, channel)
+			io.Copy(channel, target)
+		}()
+	}
+}
+
+// 启动回显服务，返回地址
+func startEchoServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// 获取一个空闲的本地端口
+func freePort(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// 通过连接发送数据并检查回显
+func expectEcho(t *testing.T, conn net.Conn, message string) {
+	t.Helper()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte(message)); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, len(message))
+	if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != message {
+		t.Fatalf("回显错误: %q %v", reply, err)
+	}
+}
+
+// 测试隧道的本地转发和 SOCKS5 代理
+func TestTunnel(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	host := startTestServer(t)
+	echo := startEchoServer(t)
+	localPort, socksPort, downPort := freePort(t), freePort(t), freePort(t)
+
+	tunnel := NewTunnel(host, []models.Forward{
+		{Name: "echo", Type: models.ForwardLocal, Listen: localPort, Target: echo},
+		{Name: "socks", Type: models.ForwardDynamic, Listen: socksPort},
+		{Name: "down", Type: models.ForwardLocal, Listen: freePort(t), Target: "127.0.0.1:" + downPort},
+	})
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() { done <- tunnel.Run(stop, func(string) {}) }()
+
+	// 连接后立即做一次健康检查
+	deadline := time.Now().Add(5 * time.Second)
+	for !tunnel.Connected() || !tunnel.Stats()[2].Checked {
+		if time.Now().After(deadline) {
+			t.Fatal("隧道未连接")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	stats := tunnel.Stats()
+	if stats[0].Health != nil || stats[1].Health != nil || stats[2].Health == nil {
+		t.Errorf("健康检查结果错误: %+v", stats)
+	}
+	if stats[0].Total != 0 {
+		t.Errorf("健康检查的探测连接不应计入连接数: %+v", stats[0])
+	}
+
+	local, err := net.Dial("tcp", "127.0.0.1:"+localPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+	expectEcho(t, local, "hello")
+
+	// SOCKS5：无认证握手后 CONNECT 到回显服务
+	socks, err := net.Dial("tcp", "127.0.0.1:"+socksPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer socks.Close()
+	echoHost, echoPortText, _ := net.SplitHostPort(echo)
+	echoPort, _ := strconv.Atoi(echoPortText)
+	request := []byte{5, 1, 0, 5, 1, 0, 1}
+	request = append(request, net.ParseIP(echoHost).To4()...)
+	request = append(request, byte(echoPort>>8), byte(echoPort))
+	if _, err := socks.Write(request); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, 12)
+	if _, err := io.ReadFull(socks, reply); err != nil || reply[1] != 0 || reply[3] != 0 {
+		t.Fatalf("SOCKS5 握手失败: %v %v", reply, err)
+	}
+	expectEcho(t, socks, "world")
+
+	stats = tunnel.Stats()
+	if stats[0].Active != 1 || stats[0].Total != 1 || stats[1].Total != 1 {
+		t.Errorf("连接统计错误: %+v", stats)
+	}
+	if tunnel.Reconnects() != 0 {
+		t.Errorf("目标不可达不应触发重连: %d", tunnel.Reconnects())
+	}
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("隧道退出错误: %v", err)
+	}
+	if _, err := net.Dial("tcp", "127.0.0.1:"+localPort); err == nil {
+		t.Error("隧道停止后应关闭本地监听")
+	}
+}
+
+// 测试主机密钥校验：已记录时可以连接，未记录或密钥改变时拒绝（非交互）
+func TestHostKeyVerification(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	host := startTestServer(t)
+	client, err := dial(host, false)
+	if err != nil {
+		t.Fatalf("已记录的主机密钥应通过校验: %v", err)
+	}
+	client.Close()
+
+	key, err := ScanHostKey(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := hostkey.Address(host.IP, host.Port)
+	if err := hostkey.Check(address, key); err != nil {
+		t.Errorf("扫描到的密钥应与记录一致: %v", err)
+	}
+
+	if _, err := hostkey.Forget(address); err != nil {
+		t.Fatal(err)
+	}
+	var unknown *hostkey.UnknownError
+	if _, err := dial(host, false); !errors.As(err, &unknown) {
+		t.Errorf("未记录的主机应拒绝连接: %v", err)
+	}
+
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	otherKey, _ := ssh.NewPublicKey(other)
+	if err := hostkey.Add(address, otherKey); err != nil {
+		t.Fatal(err)
+	}
+	var changed *hostkey.ChangedError
+	if _, err := dial(host, false); !errors.As(err, &changed) {
+		t.Errorf("密钥改变时应拒绝连接: %v", err)
+	}
+}
+
+// 测试服务端有多种主机密钥时使用已记录的类型，未记录的类型视为未知而不是改变
+func TestHostKeyMultipleKeys(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaSigner, err := ssh.NewSignerFromKey(ecdsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := startTestServer(t, ecdsaSigner)
+
+	client, err := dial(host, false)
+	if err != nil {
+		t.Fatalf("已记录 ed25519 密钥时应能连接: %v", err)
+	}
+	client.Close()
+
+	key, err := ScanHostKey(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := hostkey.Address(host.IP, host.Port)
+	if key.Type() != ssh.KeyAlgoED25519 {
+		t.Errorf("扫描应优先获取已记录类型的密钥, 得到 %s", key.Type())
+	}
+	if err := hostkey.Check(address, key); err != nil {
+		t.Errorf("扫描到的密钥应与记录一致: %v", err)
+	}
+	var unknown *hostkey.UnknownError
+	if err := hostkey.Check(address, ecdsaSigner.PublicKey()); !errors.As(err, &unknown) {
+		t.Errorf("未记录的密钥类型应视为未知: %v", err)
+	}
+}
+
+// 测试多次连接共用同一个 ssh-agent 连接
+func TestAgentConnectionShared(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("不支持 unix 套接字: %v", err)
+	}
+	defer listener.Close()
+	accepted := make(chan struct{}, 10)
+	keyring := agent.NewKeyring()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- struct{}{}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", socket)
+
+	host := startTestServer(t)
+	for i := 0; i < 3; i++ {
+		client, err := dial(host, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.Close()
+	}
+	if len(accepted) != 1 {
+		t.Errorf("应只建立 1 个 ssh-agent 连接, 实际 %d 个", len(accepted))
+	}
+}
+
+// 测试跳板机密钥未记录时状态为未知，后台隧道把密钥改变的警告写入日志
+func TestHostKeyErrorReporting(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	bastion := startTestServer(t)
+	echo := startEchoServer(t)
+	echoHost, echoPort, _ := net.SplitHostPort(echo)
+	port, _ := strconv.Atoi(echoPort)
+	target := models.Host{Name: "inner", IP: echoHost, Port: port, Via: []models.Host{bastion}}
+	if status := CheckHostStatus(target); status != "online" {
+		t.Fatalf("经跳板机检查状态错误: %s", status)
+	}
+
+	address := hostkey.Address(bastion.IP, bastion.Port)
+	if _, err := hostkey.Forget(address); err != nil {
+		t.Fatal(err)
+	}
+	if status := CheckHostStatus(target); status != "unknown" {
+		t.Errorf("跳板机密钥未记录时状态应为 unknown: %s", status)
+	}
+
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	otherKey, _ := ssh.NewPublicKey(other)
+	if err := hostkey.Add(address, otherKey); err != nil {
+		t.Fatal(err)
+	}
+	tunnel := NewTunnel(bastion, []models.Forward{{Type: models.ForwardLocal, Listen: freePort(t), Target: echo}})
+	tunnel.Background()
+	messages := make(chan string, 10)
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() { done <- tunnel.Run(stop, func(m string) { messages <- m }) }()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case m := <-messages:
+			if strings.Contains(m, "主机密钥已改变") && strings.Contains(m, bastion.Name) {
+				return
+			}
+		case <-timeout:
+			t.Fatal("隧道日志中应包含密钥改变的警告")
+		}
+	}
+}
@@ -0,0 +1,37 @@
+//go:build windows
+
+package ssh
+
+import (
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+// Windows 没有 SIGWINCH，定时轮询窗口大小，返回停止函数
+func watchWindowResize(fd int, session *ssh.Session) func() {
+	done := make(chan struct{})
+	ticker := time.NewTicker(500 * time.Millisecond)
+	lastWidth, lastHeight, _ := term.GetSize(fd)
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				width, height, err := term.GetSize(fd)
+				if err == nil && (width != lastWidth || height != lastHeight) {
+					lastWidth, lastHeight = width, height
+					session.WindowChange(height, width)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
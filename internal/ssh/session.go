@@ -0,0 +1,60 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+// 在已建立的连接上运行交互式Shell，结束后恢复本地终端
+func runInteractiveSession(client *ssh.Client) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("创建会话失败: %v", err)
+	}
+	defer session.Close()
+
+	session.Stdin = os.Stdin
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		// 本地终端切换到原始模式，按键直接透传给远端
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("设置终端模式失败: %v", err)
+		}
+		defer term.Restore(fd, state)
+
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+
+		termType := os.Getenv("TERM")
+		if termType == "" {
+			termType = "xterm-256color"
+		}
+
+		modes := ssh.TerminalModes{
+			ssh.ECHO:          1,
+			ssh.TTY_OP_ISPEED: 14400,
+			ssh.TTY_OP_OSPEED: 14400,
+		}
+		if err := session.RequestPty(termType, height, width, modes); err != nil {
+			return fmt.Errorf("申请伪终端失败: %v", err)
+		}
+
+		// 同步本地窗口大小变化
+		stop := watchWindowResize(fd, session)
+		defer stop()
+	}
+
+	if err := session.Shell(); err != nil {
+		return fmt.Errorf("启动Shell失败: %v", err)
+	}
+	return session.Wait()
+}
@@ -0,0 +1,38 @@
+package ssh
+
+import (
+	"fmt"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// SFTP 会话，关闭时一并关闭底层 SSH 连接
+type SFTP struct {
+	*sftp.Client
+	conn *ssh.Client
+}
+
+// 使用内置客户端建立 SFTP 会话（与主机配置的认证方式和跳板机相同），
+// connect_mode 为 external 的主机同样使用内置客户端
+func OpenSFTP(host models.Host) (*SFTP, error) {
+	conn, err := Dial(host)
+	if err != nil {
+		return nil, err
+	}
+	// 保持顺序写入：中断后目标文件不会留下空洞，可以按已有大小续传
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("启动 SFTP 失败（远端可能未启用 sftp 子系统）: %v", err)
+	}
+	return &SFTP{Client: client, conn: conn}, nil
+}
+
+// 关闭 SFTP 会话和 SSH 连接
+func (s *SFTP) Close() error {
+	s.Client.Close()
+	return s.conn.Close()
+}
@@ -0,0 +1,241 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/daihao4371/hostmanager/internal/hostkey"
+	"github.com/daihao4371/hostmanager/internal/models"
+)
+
+// 断线重连的最长等待时间
+const maxReconnectDelay = 30 * time.Second
+
+// 保活请求间隔，网络静默中断时也能及时发现并重连
+const keepAliveInterval = 15 * time.Second
+
+// 健康检查间隔
+const healthCheckInterval = 30 * time.Second
+
+// 单次健康检查的超时时间
+const healthCheckTimeout = 10 * time.Second
+
+// SSH 连接连续多次健康检查异常后重新连接
+const maxHealthFailures = 3
+
+// 不启动 Shell 的端口转发隧道，连接断开后自动重连
+type Tunnel struct {
+	host           models.Host
+	forwarders     []*forwarder
+	connected      atomic.Bool
+	reconnects     atomic.Int64
+	healthInterval time.Duration
+	interactive    bool
+}
+
+// 创建隧道（尚未连接）
+func NewTunnel(host models.Host, forwards []models.Forward) *Tunnel {
+	t := &Tunnel{host: host, healthInterval: healthCheckInterval, interactive: true}
+	for _, forward := range forwards {
+		t.forwarders = append(t.forwarders, newForwarder(forward))
+	}
+	return t
+}
+
+// 后台运行：不在终端提示输入密码或口令，只使用密钥、ssh-agent 和已保存的密码
+func (t *Tunnel) Background() {
+	t.interactive = false
+}
+
+// 各转发的连接统计
+func (t *Tunnel) Stats() []ForwardStats {
+	stats := make([]ForwardStats, len(t.forwarders))
+	for i, f := range t.forwarders {
+		stats[i] = f.stats()
+	}
+	return stats
+}
+
+// 是否已连接
+func (t *Tunnel) Connected() bool {
+	return t.connected.Load()
+}
+
+// 首次连接之后重新连接的次数
+func (t *Tunnel) Reconnects() int {
+	return int(t.reconnects.Load())
+}
+
+// 运行隧道直到 stop 关闭；本地端口无法监听时立即返回错误，
+// 连接失败或断开后按指数退避重连，状态变化通过 notify 通知
+func (t *Tunnel) Run(stop <-chan struct{}, notify func(string)) error {
+	// 跳板机配置错误时重连没有意义
+	if t.host.JumpError != nil {
+		return t.host.JumpError
+	}
+	for _, f := range t.forwarders {
+		if err := f.listen(); err != nil {
+			t.close()
+			return err
+		}
+	}
+	defer t.close()
+
+	delay := time.Second
+	for attempt := 0; ; attempt++ {
+		client, err := dial(t.host, t.interactive)
+		if err == nil {
+			err = t.attach(client)
+			if err != nil {
+				client.Close()
+			}
+		}
+
+		if err == nil {
+			delay = time.Second
+			if attempt > 0 {
+				t.reconnects.Add(1)
+			}
+			t.connected.Store(true)
+			notify(fmt.Sprintf("已连接到 %s", t.host.Name))
+
+			closed := make(chan error, 1)
+			go func() { closed <- client.Wait() }()
+			done := make(chan struct{})
+			go keepAlive(client, done)
+			go t.watchHealth(client, done, notify)
+			select {
+			case <-stop:
+				close(done)
+				t.detach()
+				client.Close()
+				return nil
+			case err = <-closed:
+				close(done)
+				t.detach()
+			}
+			if err != nil {
+				notify(fmt.Sprintf("与 %s 的连接已断开: %v", t.host.Name, err))
+			} else {
+				notify(fmt.Sprintf("与 %s 的连接已断开", t.host.Name))
+			}
+		} else {
+			notify(fmt.Sprintf("连接失败: %v", err))
+			// 后台隧道不会在终端显示警告，写入隧道日志
+			var changed *hostkey.ChangedError
+			if errors.As(err, &changed) && !t.interactive {
+				notify(strings.TrimSuffix(changed.Warning(t.hopName(changed.Address)), "\n"))
+			}
+		}
+
+		notify(fmt.Sprintf("%d 秒后重连...", int(delay/time.Second)))
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// 按 known_hosts 地址查找连接链中的主机名称
+func (t *Tunnel) hopName(address string) string {
+	for _, hop := range append(append([]models.Host{}, t.host.Via...), t.host) {
+		if hostkey.Address(hop.IP, hop.Port) == address {
+			return hop.Name
+		}
+	}
+	return address
+}
+
+func (t *Tunnel) attach(client *ssh.Client) error {
+	for _, f := range t.forwarders {
+		if err := f.attach(client); err != nil {
+			t.detach()
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Tunnel) detach() {
+	t.connected.Store(false)
+	for _, f := range t.forwarders {
+		f.detach()
+	}
+}
+
+func (t *Tunnel) close() {
+	for _, f := range t.forwarders {
+		f.close()
+	}
+}
+
+// 连接后立即并定期检查各转发；SSH 连接连续异常时关闭连接，由 Run 重新连接
+func (t *Tunnel) watchHealth(client *ssh.Client, done <-chan struct{}, notify func(string)) {
+	ticker := time.NewTicker(t.healthInterval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		restart := false
+		for _, f := range t.forwarders {
+			previous := f.stats().Health
+			broken, err := f.check(client)
+			restart = restart || broken
+			if err != nil && (previous == nil || previous.Error() != err.Error()) {
+				notify(fmt.Sprintf("%s 健康检查失败: %v", f.forward, err))
+			} else if err == nil && previous != nil {
+				notify(fmt.Sprintf("%s 已恢复正常", f.forward))
+			}
+		}
+		if restart {
+			failures++
+		} else {
+			failures = 0
+		}
+		if failures >= maxHealthFailures {
+			notify(fmt.Sprintf("健康检查连续 %d 次失败，重新连接", failures))
+			client.Close()
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
+
+// 定期发送保活请求，无响应时关闭连接
+func keepAlive(client *ssh.Client, done <-chan struct{}) {
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			reply := make(chan error, 1)
+			go func() {
+				_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+				reply <- err
+			}()
+			select {
+			case err := <-reply:
+				if err != nil {
+					client.Close()
+					return
+				}
+			case <-time.After(keepAliveInterval):
+				client.Close()
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package sshconfig
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Include 最大嵌套深度（与 OpenSSH 保持一致）
+const maxIncludeDepth = 16
+
+// 从 ssh_config 中解析出的主机
+type Entry struct {
+	Alias        string // Host 行中的别名
+	HostName     string // 实际地址，未配置时等于别名
+	User         string
+	Port         int      // 未配置时为 0
+	IdentityFile string   // 第一个 IdentityFile
+	ProxyJump    []string // 跳板机链，"none" 表示不使用
+	Source       string   // 定义该别名的文件
+}
+
+// 一个 Host 配置块
+type block struct {
+	patterns []string
+	options  map[string][]string // 小写关键字 -> 参数，同一块内先出现的生效
+	source   string
+}
+
+// 解析后的配置文件
+type Config struct {
+	blocks []*block
+}
+
+// 解析指定路径的 ssh_config（包括 Include 的文件）
+func ParseFile(path string) (*Config, error) {
+	cfg := &Config{}
+	// 第一个块之前的选项相当于 Host *
+	global := &block{patterns: []string{"*"}, options: map[string][]string{}, source: path}
+	cfg.blocks = append(cfg.blocks, global)
+
+	if err := cfg.parseFile(expandHome(path), global, 0); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) parseFile(path string, current *block, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("Include 嵌套过深: %s", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	skipping := false // 处于不支持的 Match 块中
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		keyword, args := splitLine(scanner.Text())
+		if keyword == "" {
+			continue
+		}
+
+		switch keyword {
+		case "host":
+			if len(args) == 0 {
+				return fmt.Errorf("%s:%d: Host 缺少模式", path, lineNo)
+			}
+			current = &block{patterns: args, options: map[string][]string{}, source: path}
+			c.blocks = append(c.blocks, current)
+			skipping = false
+		case "match":
+			skipping = true
+		case "include":
+			if skipping {
+				continue
+			}
+			for _, pattern := range args {
+				if err := c.include(pattern, current, depth); err != nil {
+					return err
+				}
+			}
+		default:
+			if skipping || len(args) == 0 {
+				continue
+			}
+			if _, exists := current.options[keyword]; !exists {
+				current.options[keyword] = args
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// 处理 Include，相对路径相对于 ~/.ssh
+func (c *Config) include(pattern string, current *block, depth int) error {
+	pattern = expandHome(pattern)
+	if !filepath.IsAbs(pattern) {
+		home, _ := os.UserHomeDir()
+		pattern = filepath.Join(home, ".ssh", pattern)
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("Include 模式无效 %s: %v", pattern, err)
+	}
+	for _, match := range matches {
+		if err := c.parseFile(match, current, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 列出所有具体主机（不含通配符模式），按首次出现的顺序
+func (c *Config) Entries() []Entry {
+	var entries []Entry
+	seen := map[string]bool{}
+
+	for _, b := range c.blocks {
+		for _, alias := range b.patterns {
+			if isPattern(alias) || seen[alias] {
+				continue
+			}
+			seen[alias] = true
+			entry := c.Resolve(alias)
+			entry.Source = b.source
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// 计算别名的最终配置：按文件顺序，先匹配到的值生效
+func (c *Config) Resolve(alias string) Entry {
+	options := map[string][]string{}
+	for _, b := range c.blocks {
+		if !b.matches(alias) {
+			continue
+		}
+		for keyword, args := range b.options {
+			if _, exists := options[keyword]; !exists {
+				options[keyword] = args
+			}
+		}
+	}
+
+	entry := Entry{Alias: alias, HostName: alias}
+	if args, ok := options["hostname"]; ok {
+		entry.HostName = strings.ReplaceAll(args[0], "%h", alias)
+	}
+	if args, ok := options["user"]; ok {
+		entry.User = args[0]
+	}
+	if args, ok := options["port"]; ok {
+		entry.Port, _ = strconv.Atoi(args[0])
+	}
+	if args, ok := options["identityfile"]; ok {
+		entry.IdentityFile = args[0]
+	}
+	if args, ok := options["proxyjump"]; ok {
+		for _, hop := range strings.Split(strings.Join(args, ""), ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				entry.ProxyJump = append(entry.ProxyJump, hop)
+			}
+		}
+	}
+	return entry
+}
+
+// 判断块的模式列表是否匹配别名（支持 * ? 和 ! 取反）
+func (b *block) matches(alias string) bool {
+	matched := false
+	for _, pattern := range b.patterns {
+		negate := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		if ok, _ := filepath.Match(pattern, alias); ok {
+			if negate {
+				return false
+			}
+			matched = true
+		}
+	}
+	return matched
+}
+
+// 拆分一行为小写关键字和参数，支持 "Key=Value" 和引号
+func splitLine(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil
+	}
+
+	var keyword string
+	if idx := strings.IndexAny(line, " \t="); idx >= 0 {
+		keyword = line[:idx]
+		line = strings.TrimLeft(line[idx:], " \t")
+		line = strings.TrimPrefix(line, "=")
+	} else {
+		keyword, line = line, ""
+	}
+	return strings.ToLower(keyword), splitArgs(line)
+}
+
+// 按空白拆分参数，双引号内的空白保留
+func splitArgs(s string) []string {
+	var args []string
+	var current strings.Builder
+	inQuote, hasArg := false, false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			hasArg = true
+		case (r == ' ' || r == '\t') && !inQuote:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		case r == '#' && !inQuote && !hasArg:
+			return args
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// 是否为通配符或取反模式
+func isPattern(alias string) bool {
+	return strings.ContainsAny(alias, "*?!")
+}
+
+// 展开路径中的 ~
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
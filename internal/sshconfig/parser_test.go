@@ -0,0 +1,91 @@
+package sshconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 测试 Host 块、通配符默认值和 Include 的解析
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+	included := filepath.Join(dir, "conf.d", "db.conf")
+	os.MkdirAll(filepath.Dir(included), 0755)
+	os.WriteFile(included, []byte(`
+Host db
+    HostName 10.0.1.2
+    ProxyJump bastion
+`), 0644)
+
+	main := filepath.Join(dir, "config")
+	os.WriteFile(main, []byte(`
+# 全局设置
+Port 2200
+
+Include `+filepath.Join(dir, "conf.d", "*.conf")+`
+
+Host bastion
+    HostName=10.0.0.1
+    Port 2222
+    IdentityFile "~/.ssh/id bastion"
+
+Host web web2
+    HostName %h.example.com
+    User deploy
+
+Host *.internal !skip.internal
+    User internal
+
+Host *
+    User ignored
+    Port 22
+`), 0644)
+
+	cfg, err := ParseFile(main)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	entries := map[string]Entry{}
+	var aliases []string
+	for _, entry := range cfg.Entries() {
+		entries[entry.Alias] = entry
+		aliases = append(aliases, entry.Alias)
+	}
+	if strings.Join(aliases, ",") != "db,bastion,web,web2" {
+		t.Errorf("主机顺序错误: %v", aliases)
+	}
+
+	if e := entries["bastion"]; e.HostName != "10.0.0.1" || e.Port != 2200 || e.User != "ignored" || !strings.HasSuffix(e.IdentityFile, "id bastion") {
+		t.Errorf("bastion 解析错误: %+v", e)
+	}
+	if e := entries["web2"]; e.HostName != "web2.example.com" || e.User != "deploy" || e.Port != 2200 {
+		t.Errorf("web2 解析错误: %+v", e)
+	}
+	if e := entries["db"]; e.HostName != "10.0.1.2" || len(e.ProxyJump) != 1 || e.ProxyJump[0] != "bastion" || e.Source != included {
+		t.Errorf("db 解析错误: %+v", e)
+	}
+
+	// 第一个块之前的全局设置优先于后面的 Host 块
+	if e := cfg.Resolve("a.internal"); e.User != "internal" || e.Port != 2200 {
+		t.Errorf("a.internal 解析错误: %+v", e)
+	}
+	if e := cfg.Resolve("skip.internal"); e.User != "ignored" {
+		t.Errorf("skip.internal 解析错误: %+v", e)
+	}
+}
+
+// 测试取反模式
+func TestBlockMatches(t *testing.T) {
+	b := &block{patterns: []string{"*.internal", "!skip.internal"}}
+	if !b.matches("a.internal") {
+		t.Error("a.internal 应当匹配")
+	}
+	if b.matches("skip.internal") {
+		t.Error("skip.internal 被取反，不应匹配")
+	}
+	if b.matches("example.com") {
+		t.Error("example.com 不应匹配")
+	}
+}
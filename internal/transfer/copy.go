@@ -0,0 +1,248 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// 复制的一端：文件系统和路径（可以包含通配符）
+type Endpoint struct {
+	FS   FS
+	Path string
+}
+
+func (e Endpoint) String() string {
+	return e.FS.Label(e.Path)
+}
+
+// 单个复制任务：创建目录或复制文件
+type Job struct {
+	Src     FS
+	SrcPath string
+	Dst     FS
+	DstPath string
+	Dir     bool
+	Size    int64
+	Mode    os.FileMode
+}
+
+// 复制结果统计
+type Result struct {
+	Files   int   // 复制的文件数
+	Bytes   int64 // 实际传输的字节数
+	Skipped int   // 已完整存在而跳过的文件数（--resume）
+	Resumed int   // 续传的文件数
+}
+
+// 路径是否包含通配符
+func hasMeta(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// 展开源路径中的通配符，没有匹配时返回错误
+func expand(src Endpoint) ([]string, error) {
+	if !hasMeta(src.Path) {
+		return []string{src.Path}, nil
+	}
+	matches, err := src.FS.Glob(src.Path)
+	if err != nil {
+		return nil, fmt.Errorf("无效的通配符 %s: %v", src, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("没有匹配的文件: %s", src)
+	}
+	return matches, nil
+}
+
+// 生成复制任务，语义与 scp 相同：目标是已存在的目录时复制到目录下，
+// 多个源时目标必须是目录；目录需要 recursive
+func Plan(sources []Endpoint, dst Endpoint, recursive bool) ([]Job, error) {
+	var paths []Endpoint
+	for _, src := range sources {
+		matches, err := expand(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			paths = append(paths, Endpoint{FS: src.FS, Path: match})
+		}
+	}
+
+	dstInfo, err := dst.FS.Stat(dst.Path)
+	dstIsDir := err == nil && dstInfo.IsDir()
+	if len(paths) > 1 && !dstIsDir {
+		return nil, fmt.Errorf("复制多个文件时目标必须是已存在的目录: %s", dst)
+	}
+
+	var jobs []Job
+	for _, src := range paths {
+		info, err := src.FS.Stat(src.Path)
+		if err != nil {
+			return nil, fmt.Errorf("无法访问 %s: %v", src, describe(err))
+		}
+		target := dst.Path
+		if dstIsDir {
+			target = dst.FS.Join(dst.Path, src.FS.Base(src.Path))
+		}
+		if !info.IsDir() {
+			jobs = append(jobs, Job{Src: src.FS, SrcPath: src.Path, Dst: dst.FS, DstPath: target, Size: info.Size(), Mode: info.Mode().Perm()})
+			continue
+		}
+		if !recursive {
+			return nil, fmt.Errorf("%s 是目录，请使用 -r 递归复制", src)
+		}
+		dirJobs, err := planDir(src, Endpoint{FS: dst.FS, Path: target}, info)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, dirJobs...)
+	}
+	for _, job := range jobs {
+		if !job.Dir && sameFile(job.Src, job.SrcPath, job.Dst, job.DstPath) {
+			return nil, fmt.Errorf("%s 和 %s 是同一个文件", job.Src.Label(job.SrcPath), job.Dst.Label(job.DstPath))
+		}
+	}
+	return jobs, nil
+}
+
+// 递归生成目录的复制任务；指向目录的符号链接会被跳过，避免循环
+func planDir(src, dst Endpoint, info os.FileInfo) ([]Job, error) {
+	jobs := []Job{{Src: src.FS, SrcPath: src.Path, Dst: dst.FS, DstPath: dst.Path, Dir: true, Mode: info.Mode().Perm()}}
+	entries, err := src.FS.ReadDir(src.Path)
+	if err != nil {
+		return nil, fmt.Errorf("读取目录 %s 失败: %v", src, describe(err))
+	}
+	for _, entry := range entries {
+		child := Endpoint{FS: src.FS, Path: src.FS.Join(src.Path, entry.Name())}
+		target := Endpoint{FS: dst.FS, Path: dst.FS.Join(dst.Path, entry.Name())}
+		if entry.Mode()&os.ModeSymlink != 0 {
+			resolved, err := src.FS.Stat(child.Path)
+			if err != nil || resolved.IsDir() {
+				continue
+			}
+			entry = resolved
+		}
+		if entry.IsDir() {
+			childJobs, err := planDir(child, target, entry)
+			if err != nil {
+				return nil, err
+			}
+			jobs = append(jobs, childJobs...)
+		} else if entry.Mode().IsRegular() {
+			jobs = append(jobs, Job{Src: src.FS, SrcPath: child.Path, Dst: dst.FS, DstPath: target.Path, Size: entry.Size(), Mode: entry.Mode().Perm()})
+		}
+	}
+	return jobs, nil
+}
+
+// 任务中文件的总大小
+func TotalSize(jobs []Job) int64 {
+	var total int64
+	for _, job := range jobs {
+		total += job.Size
+	}
+	return total
+}
+
+// 执行复制任务
+type Copier struct {
+	Resume   bool      // 目标文件较小时从已有大小处续传，大小相同则跳过
+	Progress *Progress // 为空时不显示进度
+}
+
+// 依次执行任务；单个文件失败不影响其他文件，返回所有错误
+func (c *Copier) Run(jobs []Job) (Result, []error) {
+	var result Result
+	var errs []error
+	for _, job := range jobs {
+		if job.Dir {
+			if err := job.Dst.MkdirAll(job.DstPath, job.Mode|0700); err != nil {
+				errs = append(errs, fmt.Errorf("创建目录 %s 失败: %v", job.Dst.Label(job.DstPath), describe(err)))
+			}
+			continue
+		}
+		if err := c.copyFile(job, &result); err != nil {
+			errs = append(errs, fmt.Errorf("复制 %s 失败: %v", job.Src.Label(job.SrcPath), describe(err)))
+		}
+	}
+	c.Progress.Finish()
+	return result, errs
+}
+
+func (c *Copier) copyFile(job Job, result *Result) error {
+	var offset int64
+	if c.Resume {
+		if info, err := job.Dst.Stat(job.DstPath); err == nil && info.Mode().IsRegular() {
+			switch {
+			case info.Size() == job.Size:
+				result.Skipped++
+				c.Progress.Skip(job.Src.Label(job.SrcPath), job.Size)
+				return nil
+			case info.Size() < job.Size:
+				offset = info.Size()
+			}
+		}
+	}
+
+	in, err := job.Src.Open(job.SrcPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if offset > 0 {
+		if _, err := in.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	// 不续传时先写入同目录的临时文件，复制成功后再替换目标，失败时不会破坏已有文件；
+	// 已存在的目标保留原有权限
+	target, mode := job.DstPath, job.Mode
+	if !c.Resume {
+		target = job.DstPath + ".hostmanager-tmp"
+		if info, err := job.Dst.Stat(job.DstPath); err == nil && info.Mode().IsRegular() {
+			mode = info.Mode().Perm()
+		}
+	}
+	out, err := job.Dst.OpenWriter(target, offset, mode)
+	if err != nil {
+		return err
+	}
+
+	c.Progress.Begin(job.Src.Label(job.SrcPath), offset)
+	n, err := io.Copy(out, c.Progress.Reader(in))
+	result.Bytes += n
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n+offset != job.Size {
+		err = fmt.Errorf("大小不一致（预期 %d 字节，实际 %d 字节），源文件可能在复制过程中被修改", job.Size, n+offset)
+	}
+	if err == nil && target != job.DstPath {
+		err = job.Dst.Replace(target, job.DstPath)
+	}
+	if err != nil {
+		if target != job.DstPath {
+			job.Dst.RemoveAll(target)
+		}
+		return err
+	}
+	result.Files++
+	if offset > 0 {
+		result.Resumed++
+	}
+	return nil
+}
+
+// 常见错误的中文说明
+func describe(err error) error {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return errors.New("文件不存在")
+	case errors.Is(err, os.ErrPermission):
+		return errors.New("权限不足")
+	}
+	return err
+}
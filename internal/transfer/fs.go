@@ -0,0 +1,261 @@
+package transfer
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+)
+
+// 读写文件的统一接口，本地文件系统和 SFTP 各有一个实现
+type FS interface {
+	Stat(name string) (os.FileInfo, error)
+	ReadDir(name string) ([]os.FileInfo, error)
+	Open(name string) (io.ReadSeekCloser, error)
+	// 打开写入的文件：offset 为 0 时创建或截断，否则从 offset 处续写
+	OpenWriter(name string, offset int64, perm os.FileMode) (io.WriteCloser, error)
+	MkdirAll(name string, perm os.FileMode) error
+	// 删除文件或整个目录（不跟随符号链接）
+	RemoveAll(name string) error
+	Rename(oldname, newname string) error
+	// 重命名并覆盖已存在的目标文件
+	Replace(oldname, newname string) error
+	Glob(pattern string) ([]string, error)
+	Join(elem ...string) string
+	Base(name string) string
+	// 显示给用户的路径，例如 web1:/var/log/app.log
+	Label(name string) string
+}
+
+// 本地文件系统
+type Local struct{}
+
+func (Local) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(fsutil.ExpandPath(name))
+}
+
+func (Local) ReadDir(name string) ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(fsutil.ExpandPath(name))
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (Local) Open(name string) (io.ReadSeekCloser, error) {
+	return os.Open(fsutil.ExpandPath(name))
+}
+
+func (Local) OpenWriter(name string, offset int64, perm os.FileMode) (io.WriteCloser, error) {
+	name = fsutil.ExpandPath(name)
+	if offset == 0 {
+		return os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func (Local) MkdirAll(name string, perm os.FileMode) error {
+	return os.MkdirAll(fsutil.ExpandPath(name), perm)
+}
+
+func (Local) RemoveAll(name string) error {
+	return os.RemoveAll(fsutil.ExpandPath(name))
+}
+
+func (Local) Rename(oldname, newname string) error {
+	return os.Rename(fsutil.ExpandPath(oldname), fsutil.ExpandPath(newname))
+}
+
+func (Local) Replace(oldname, newname string) error {
+	return os.Rename(fsutil.ExpandPath(oldname), fsutil.ExpandPath(newname))
+}
+
+func (Local) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(fsutil.ExpandPath(pattern))
+}
+
+func (Local) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (Local) Base(name string) string {
+	return filepath.Base(name)
+}
+
+func (Local) Label(name string) string {
+	return name
+}
+
+// 远端主机上的文件（SFTP），相对路径和 ~ 都相对于登录用户的主目录
+type Remote struct {
+	Host   string
+	Client *sftp.Client
+}
+
+func (r Remote) Stat(name string) (os.FileInfo, error) {
+	return r.Client.Stat(remotePath(name))
+}
+
+func (r Remote) ReadDir(name string) ([]os.FileInfo, error) {
+	return r.Client.ReadDir(remotePath(name))
+}
+
+func (r Remote) Open(name string) (io.ReadSeekCloser, error) {
+	return r.Client.Open(remotePath(name))
+}
+
+func (r Remote) OpenWriter(name string, offset int64, perm os.FileMode) (io.WriteCloser, error) {
+	name = remotePath(name)
+	if offset == 0 {
+		file, err := r.Client.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+		if err != nil {
+			return nil, err
+		}
+		file.Chmod(perm)
+		return file, nil
+	}
+	file, err := r.Client.OpenFile(name, os.O_WRONLY)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func (r Remote) MkdirAll(name string, perm os.FileMode) error {
+	name = remotePath(name)
+	if info, err := r.Client.Stat(name); err == nil && info.IsDir() {
+		return nil // 已存在的目录保留原有权限
+	}
+	if err := r.Client.MkdirAll(name); err != nil {
+		return err
+	}
+	r.Client.Chmod(name, perm) // 与文件相同，权限设置失败不影响复制
+	return nil
+}
+
+// sftp.Client.RemoveAll 会进入指向目录的符号链接，这里使用 Lstat 只删除链接本身
+func (r Remote) RemoveAll(name string) error {
+	name = remotePath(name)
+	info, err := r.Client.Lstat(name)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return r.Client.Remove(name)
+	}
+	entries, err := r.Client.ReadDir(name)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := r.RemoveAll(path.Join(name, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return r.Client.RemoveDirectory(name)
+}
+
+// 目标已存在时失败，不会覆盖
+func (r Remote) Rename(oldname, newname string) error {
+	return r.Client.Rename(remotePath(oldname), remotePath(newname))
+}
+
+// 优先使用 posix-rename 扩展原子覆盖；服务端不支持时先删除目标再重命名
+func (r Remote) Replace(oldname, newname string) error {
+	oldname, newname = remotePath(oldname), remotePath(newname)
+	if err := r.Client.PosixRename(oldname, newname); err == nil {
+		return nil
+	}
+	if err := r.Client.Remove(newname); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return r.Client.Rename(oldname, newname)
+}
+
+func (r Remote) Glob(pattern string) ([]string, error) {
+	return r.Client.Glob(remotePath(pattern))
+}
+
+func (Remote) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (Remote) Base(name string) string {
+	return path.Base(name)
+}
+
+func (r Remote) Label(name string) string {
+	return r.Host + ":" + name
+}
+
+// 服务端解析后的绝对路径，不支持时退回到清理后的路径
+func (r Remote) realPath(name string) string {
+	name = remotePath(name)
+	if resolved, err := r.Client.RealPath(name); err == nil {
+		return resolved
+	}
+	return path.Clean(name)
+}
+
+// SFTP 不展开 ~，去掉前缀后作为相对于主目录的路径
+func remotePath(name string) string {
+	switch {
+	case name == "" || name == "~":
+		return "."
+	case strings.HasPrefix(name, "~/"):
+		return strings.TrimPrefix(name, "~/")
+	}
+	return name
+}
+
+// 两端是否指向同一个文件：本地比较文件标识，远端比较同一连接上解析后的路径
+func sameFile(a FS, aPath string, b FS, bPath string) bool {
+	switch a := a.(type) {
+	case Local:
+		if _, ok := b.(Local); !ok {
+			return false
+		}
+		aInfo, err := a.Stat(aPath)
+		if err != nil {
+			return false
+		}
+		bInfo, err := b.Stat(bPath)
+		if err != nil {
+			return false
+		}
+		return os.SameFile(aInfo, bInfo)
+	case Remote:
+		b, ok := b.(Remote)
+		if !ok || a.Host != b.Host || a.Client != b.Client {
+			return false
+		}
+		return a.realPath(aPath) == b.realPath(bPath)
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package transfer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 进度条宽度和刷新间隔
+const (
+	barWidth        = 30
+	refreshInterval = 100 * time.Millisecond
+)
+
+// ANSI 颜色，与界面中进度条的渐变一致：红 → 黄 → 绿
+const (
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorGreen  = "\033[32m"
+	colorWhite  = "\033[97m"
+	colorDim    = "\033[2m"
+	colorReset  = "\033[0m"
+)
+
+// 传输进度：终端中单行刷新进度条，否则每个文件输出一行；为空时不显示
+type Progress struct {
+	mu    sync.Mutex
+	out   io.Writer // 为空时只记录进度，由界面通过 Status 读取
+	live  bool
+	files int
+	total int64
+
+	index   int       // 当前是第几个文件
+	done    int64     // 已完成的字节数（包括跳过和续传前已有的部分）
+	copied  int64     // 本次实际传输的字节数，用于计算速度
+	name    string    // 当前文件
+	started time.Time // 开始时间
+	drawn   time.Time // 上次刷新时间
+}
+
+// 进度快照
+type ProgressStatus struct {
+	Index int    // 当前是第几个文件
+	Files int    // 文件总数
+	Done  int64  // 已完成的字节数
+	Total int64  // 总字节数
+	Speed int64  // 每秒传输的字节数
+	Name  string // 当前文件
+}
+
+// 完成比例（0-1）
+func (s ProgressStatus) Ratio() float64 {
+	if s.Total <= 0 {
+		return 1
+	}
+	return float64(s.Done) / float64(s.Total)
+}
+
+// 创建进度显示，live 为 true 时在同一行刷新彩色进度条
+func NewProgress(out io.Writer, live bool, files int, total int64) *Progress {
+	return &Progress{out: out, live: live, files: files, total: total, started: time.Now()}
+}
+
+// 开始复制文件，offset 为续传的起始位置
+func (p *Progress) Begin(name string, offset int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.index++
+	p.name = name
+	p.done += offset
+	if p.out == nil {
+		return
+	}
+	if !p.live {
+		if offset > 0 {
+			fmt.Fprintf(p.out, "📄 %s（从 %s 处续传）\n", name, FormatSize(offset))
+		} else {
+			fmt.Fprintf(p.out, "📄 %s\n", name)
+		}
+		return
+	}
+	p.draw(true)
+}
+
+// 跳过已完整存在的文件
+func (p *Progress) Skip(name string, size int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.index++
+	p.name = name
+	p.done += size
+	if p.out == nil {
+		return
+	}
+	if !p.live {
+		fmt.Fprintf(p.out, "⏭️  %s（已存在，跳过）\n", name)
+		return
+	}
+	p.draw(true)
+}
+
+// 包装读取端，统计传输的字节数
+func (p *Progress) Reader(r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+	return &progressReader{r: r, p: p}
+}
+
+// 结束进度显示
+func (p *Progress) Finish() {
+	if p == nil || p.out == nil || !p.live {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.draw(true)
+	fmt.Fprintln(p.out)
+}
+
+// 当前进度
+func (p *Progress) Status() ProgressStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status(time.Now())
+}
+
+func (p *Progress) status(now time.Time) ProgressStatus {
+	status := ProgressStatus{Index: p.index, Files: p.files, Done: p.done, Total: p.total, Name: p.name}
+	if elapsed := now.Sub(p.started).Seconds(); elapsed > 0 {
+		status.Speed = int64(float64(p.copied) / elapsed)
+	}
+	return status
+}
+
+func (p *Progress) add(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += int64(n)
+	p.copied += int64(n)
+	if p.out != nil && p.live {
+		p.draw(false)
+	}
+}
+
+// 刷新进度行，force 为 false 时按刷新间隔节流
+func (p *Progress) draw(force bool) {
+	now := time.Now()
+	if !force && now.Sub(p.drawn) < refreshInterval {
+		return
+	}
+	p.drawn = now
+
+	status := p.status(now)
+	fmt.Fprintf(p.out, "\r%s %5.1f%%  %d/%d  %s/%s  %s/s  %s\033[K",
+		Bar(barWidth, status.Ratio(), true), status.Ratio()*100, status.Index, status.Files,
+		FormatSize(status.Done), FormatSize(status.Total), FormatSize(status.Speed), status.Name)
+}
+
+type progressReader struct {
+	r io.Reader
+	p *Progress
+}
+
+func (r *progressReader) Read(buf []byte) (int, error) {
+	n, err := r.r.Read(buf)
+	r.p.add(n)
+	return n, err
+}
+
+// 绘制文本进度条：已完成部分为 █（按位置由红到绿），当前位置为 ◆，其余为 ░
+func Bar(width int, ratio float64, color bool) string {
+	if ratio < 0 {
+		ratio = 0
+	} else if ratio > 1 {
+		ratio = 1
+	}
+	fill := int(float64(width) * ratio)
+
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < width; i++ {
+		char, code := "░", colorDim
+		switch {
+		case i < fill:
+			char, code = "█", progressColor(float64(i)/float64(width))
+		case i == fill && ratio > 0 && ratio < 1:
+			char, code = "◆", colorWhite
+		}
+		if color {
+			b.WriteString(code + char + colorReset)
+		} else {
+			b.WriteString(char)
+		}
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// 渐变进度颜色
+func progressColor(ratio float64) string {
+	if ratio < 0.3 {
+		return colorRed
+	} else if ratio < 0.7 {
+		return colorYellow
+	}
+	return colorGreen
+}
+
+// 格式化字节数，例如 12.3MB
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	value, suffix := float64(size)/unit, "KMGTPE"
+	i := 0
+	for value >= unit && i < len(suffix)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f%cB", value, suffix[i])
+}
@@ -0,0 +1,302 @@
+package transfer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func copyAll(t *testing.T, sources []Endpoint, dst Endpoint, recursive, resume bool) Result {
+	t.Helper()
+	jobs, err := Plan(sources, dst, recursive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copier := Copier{Resume: resume}
+	result, errs := copier.Run(jobs)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	return result
+}
+
+// 测试本地复制：目标目录语义、递归、通配符
+func TestCopyLocal(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	writeFile(t, filepath.Join(src, "a.log"), "aaa")
+	writeFile(t, filepath.Join(src, "b.log"), "bb")
+	writeFile(t, filepath.Join(src, "sub", "c.txt"), "c")
+	local := Local{}
+
+	// 单个文件复制到新文件名
+	copyAll(t, []Endpoint{{local, filepath.Join(src, "a.log")}}, Endpoint{local, filepath.Join(dir, "x.log")}, false, false)
+	if got := readFile(t, filepath.Join(dir, "x.log")); got != "aaa" {
+		t.Errorf("复制内容错误: %q", got)
+	}
+
+	// 目录需要 -r
+	if _, err := Plan([]Endpoint{{local, src}}, Endpoint{local, filepath.Join(dir, "out")}, false); err == nil || !strings.Contains(err.Error(), "-r") {
+		t.Errorf("复制目录未使用 -r 应报错: %v", err)
+	}
+
+	// 递归复制到不存在的路径：目标即为复制后的目录
+	result := copyAll(t, []Endpoint{{local, src}}, Endpoint{local, filepath.Join(dir, "out")}, true, false)
+	if result.Files != 3 || result.Bytes != 6 {
+		t.Errorf("统计错误: %+v", result)
+	}
+	if got := readFile(t, filepath.Join(dir, "out", "sub", "c.txt")); got != "c" {
+		t.Errorf("递归复制内容错误: %q", got)
+	}
+
+	// 目标已存在时复制到目录下
+	copyAll(t, []Endpoint{{local, src}}, Endpoint{local, filepath.Join(dir, "out")}, true, false)
+	if _, err := os.Stat(filepath.Join(dir, "out", "src", "a.log")); err != nil {
+		t.Errorf("应复制到已存在的目录下: %v", err)
+	}
+
+	// 通配符展开，多个源时目标必须是目录
+	pattern := Endpoint{local, filepath.Join(src, "*.log")}
+	if _, err := Plan([]Endpoint{pattern}, Endpoint{local, filepath.Join(dir, "none")}, false); err == nil {
+		t.Error("多个源复制到不存在的目录应报错")
+	}
+	os.Mkdir(filepath.Join(dir, "logs"), 0755)
+	copyAll(t, []Endpoint{pattern}, Endpoint{local, filepath.Join(dir, "logs")}, false, false)
+	entries, _ := os.ReadDir(filepath.Join(dir, "logs"))
+	if len(entries) != 2 {
+		t.Errorf("通配符应匹配 2 个文件，实际 %d 个", len(entries))
+	}
+	if _, err := Plan([]Endpoint{{local, filepath.Join(src, "*.none")}}, Endpoint{local, dir}, false); err == nil {
+		t.Error("通配符没有匹配时应报错")
+	}
+}
+
+// 测试续传：较小的目标从已有大小处续写，大小相同时跳过
+func TestCopyResume(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "big.bin")
+	dst := filepath.Join(dir, "copy.bin")
+	writeFile(t, src, "0123456789")
+	writeFile(t, dst, "01234")
+	local := Local{}
+
+	result := copyAll(t, []Endpoint{{local, src}}, Endpoint{local, dst}, false, true)
+	if result.Resumed != 1 || result.Bytes != 5 {
+		t.Errorf("应续传 5 字节: %+v", result)
+	}
+	if got := readFile(t, dst); got != "0123456789" {
+		t.Errorf("续传内容错误: %q", got)
+	}
+
+	result = copyAll(t, []Endpoint{{local, src}}, Endpoint{local, dst}, false, true)
+	if result.Skipped != 1 || result.Bytes != 0 {
+		t.Errorf("大小相同应跳过: %+v", result)
+	}
+
+	// 不续传时覆盖目标
+	writeFile(t, dst, "xx")
+	result = copyAll(t, []Endpoint{{local, src}}, Endpoint{local, dst}, false, false)
+	if result.Bytes != 10 || readFile(t, dst) != "0123456789" {
+		t.Errorf("应完整复制: %+v", result)
+	}
+}
+
+// 测试源和目标是同一个文件时拒绝复制，且不会清空源文件
+func TestCopySameFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a")
+	writeFile(t, src, "data")
+	local := Local{}
+
+	for _, dst := range []string{src, dir, filepath.Join(dir, ".", "a")} {
+		if _, err := Plan([]Endpoint{{local, src}}, Endpoint{local, dst}, false); err == nil || !strings.Contains(err.Error(), "同一个文件") {
+			t.Errorf("复制到 %s 应报错: %v", dst, err)
+		}
+	}
+	if err := os.Symlink(src, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Plan([]Endpoint{{local, filepath.Join(dir, "link")}}, Endpoint{local, src}, false); err == nil {
+		t.Error("通过符号链接复制到自身应报错")
+	}
+	if got := readFile(t, src); got != "data" {
+		t.Errorf("源文件被修改: %q", got)
+	}
+
+	web1 := Remote{Host: "web1", Client: memSFTP(t)}
+	copyAll(t, []Endpoint{{local, src}}, Endpoint{web1, "/x"}, false, false)
+	if _, err := Plan([]Endpoint{{web1, "/x"}}, Endpoint{web1, "/"}, false); err == nil || !strings.Contains(err.Error(), "同一个文件") {
+		t.Errorf("远端复制到自身应报错: %v", err)
+	}
+	if info, err := web1.Stat("/x"); err != nil || info.Size() != 4 {
+		t.Errorf("远端源文件被修改: %v", err)
+	}
+}
+
+// 测试不续传时先写临时文件：复制失败不会破坏已有的目标文件
+func TestCopyKeepsTargetOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	dst := filepath.Join(dir, "dst.txt")
+	writeFile(t, src, "new content")
+	writeFile(t, dst, "old")
+	local := Local{}
+
+	jobs, err := Plan([]Endpoint{{local, src}}, Endpoint{local, dst}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs[0].Size++ // 模拟源文件在复制过程中被修改
+	copier := Copier{}
+	if _, errs := copier.Run(jobs); len(errs) != 1 {
+		t.Fatalf("大小不一致应报错: %v", errs)
+	}
+	if got := readFile(t, dst); got != "old" {
+		t.Errorf("复制失败时目标应保持不变: %q", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("临时文件应已删除，目录中有 %d 个文件", len(entries))
+	}
+
+	web1 := Remote{Host: "web1", Client: memSFTP(t)}
+	copyAll(t, []Endpoint{{local, dst}}, Endpoint{web1, "/f"}, false, false)
+	copyAll(t, []Endpoint{{local, src}}, Endpoint{web1, "/f"}, false, false)
+	if info, err := web1.Stat("/f"); err != nil || info.Size() != 11 {
+		t.Errorf("远端应覆盖已存在的文件: %v", err)
+	}
+}
+
+// 内存中的 SFTP 服务端
+func memSFTP(t *testing.T) *sftp.Client {
+	t.Helper()
+	clientRead, serverWrite := io.Pipe()
+	serverRead, clientWrite := io.Pipe()
+	server := sftp.NewRequestServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverRead, serverWrite}, sftp.InMemHandler())
+	go server.Serve()
+
+	client, err := sftp.NewClientPipe(clientRead, clientWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+	return client
+}
+
+// 测试上传、下载和主机间复制
+func TestCopyRemote(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "site", "index.html"), "<html>")
+	writeFile(t, filepath.Join(dir, "site", "css", "app.css"), "body{}")
+	local := Local{}
+	web1 := Remote{Host: "web1", Client: memSFTP(t)}
+	web2 := Remote{Host: "web2", Client: memSFTP(t)}
+
+	if label := web1.Label("/srv"); label != "web1:/srv" {
+		t.Errorf("远端路径显示错误: %s", label)
+	}
+
+	// 上传目录
+	copyAll(t, []Endpoint{{local, filepath.Join(dir, "site")}}, Endpoint{web1, "/srv"}, true, false)
+	if info, err := web1.Stat("/srv/css/app.css"); err != nil || info.Size() != 6 {
+		t.Fatalf("上传失败: %v", err)
+	}
+
+	// 主机间复制（远端通配符）
+	if err := web2.MkdirAll("/backup", 0755); err != nil {
+		t.Fatal(err)
+	}
+	copyAll(t, []Endpoint{{web1, "/srv/*.html"}}, Endpoint{web2, "/backup"}, false, false)
+
+	// 下载
+	out := filepath.Join(dir, "index.html")
+	copyAll(t, []Endpoint{{web2, "/backup/index.html"}}, Endpoint{local, out}, false, false)
+	if got := readFile(t, out); got != "<html>" {
+		t.Errorf("下载内容错误: %q", got)
+	}
+
+	// 远端续传
+	writer, err := web2.OpenWriter("/backup/app.css", 0, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte("bo"))
+	writer.Close()
+	result := copyAll(t, []Endpoint{{web1, "/srv/css/app.css"}}, Endpoint{web2, "/backup/app.css"}, false, true)
+	if result.Resumed != 1 || result.Bytes != 4 {
+		t.Errorf("远端续传统计错误: %+v", result)
+	}
+	copyAll(t, []Endpoint{{web2, "/backup/app.css"}}, Endpoint{local, out}, false, false)
+	if got := readFile(t, out); got != "body{}" {
+		t.Errorf("远端续传内容错误: %q", got)
+	}
+
+	// 重命名和递归删除
+	if err := web1.Rename("/srv/index.html", "/srv/home.html"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := web1.Stat("/srv/home.html"); err != nil {
+		t.Errorf("重命名失败: %v", err)
+	}
+	if err := web1.RemoveAll("/srv"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := web1.Stat("/srv"); err == nil {
+		t.Error("目录应已删除")
+	}
+}
+
+// 测试进度条和大小格式
+func TestProgress(t *testing.T) {
+	if bar := Bar(10, 0.5, false); bar != "[█████◆░░░░]" {
+		t.Errorf("进度条错误: %s", bar)
+	}
+	if bar := Bar(4, 1, false); bar != "[████]" {
+		t.Errorf("完成的进度条错误: %s", bar)
+	}
+	if bar := Bar(4, 0, false); bar != "[░░░░]" {
+		t.Errorf("空进度条错误: %s", bar)
+	}
+	for size, want := range map[int64]string{512: "512B", 1536: "1.5KB", 5 << 20: "5.0MB"} {
+		if got := FormatSize(size); got != want {
+			t.Errorf("FormatSize(%d) = %s，应为 %s", size, got, want)
+		}
+	}
+
+	var out strings.Builder
+	p := NewProgress(&out, false, 2, 10)
+	p.Begin("a.log", 4)
+	p.Skip("b.log", 6)
+	p.Finish()
+	if !strings.Contains(out.String(), "从 4B 处续传") || !strings.Contains(out.String(), "b.log（已存在，跳过）") {
+		t.Errorf("非终端进度输出错误: %q", out.String())
+	}
+}
@@ -0,0 +1,236 @@
+package tunnel
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+)
+
+// 日志文件超过该大小时，下次启动前轮转为 .log.1
+const maxLogSize = 1 << 20
+
+// 隧道状态
+const (
+	StatusConnected    = "connected"    // 已连接
+	StatusReconnecting = "reconnecting" // 连接断开，正在重连
+	StatusStarting     = "starting"     // 进程已启动，尚未写入状态
+	StatusExited       = "exited"       // 进程已退出（pid 文件仍在）
+)
+
+// 隧道列表中的一项：后台隧道（有名称）或前台运行的隧道
+type Entry struct {
+	Name   string // 后台隧道名称，前台隧道为空
+	PID    int
+	Status string
+	State  *State // 进程写入的最新状态，启动中或已退出时为空
+}
+
+// 是否仍在运行
+func (e Entry) Running() bool {
+	return e.Status != StatusExited
+}
+
+// 状态图标：已连接且转发健康为 🟢，已退出为 🔴，其余为 🟡
+func (e Entry) Icon() string {
+	switch {
+	case e.Status == StatusExited:
+		return "🔴"
+	case e.Status == StatusConnected && !e.State.Unhealthy():
+		return "🟢"
+	}
+	return "🟡"
+}
+
+// 状态的中文名称
+func StatusName(status string) string {
+	switch status {
+	case StatusConnected:
+		return "已连接"
+	case StatusReconnecting:
+		return "重连中"
+	case StatusStarting:
+		return "启动中"
+	}
+	return "已退出"
+}
+
+// 后台隧道的 pid 文件
+func PIDPath(dir, name string) string {
+	return filepath.Join(dir, name+".pid")
+}
+
+// 后台隧道的日志文件
+func LogPath(dir, name string) string {
+	return filepath.Join(dir, name+".log")
+}
+
+// 检查后台隧道名称（用作文件名）
+func CheckName(name string) error {
+	if name == "" {
+		return fmt.Errorf("隧道名称不能为空")
+	}
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "-") {
+		return fmt.Errorf("隧道名称不能以 . 或 - 开头: %s", name)
+	}
+	for _, r := range name {
+		if !validNameRune(r) {
+			return fmt.Errorf("隧道名称只能包含字母、数字和 ._-: %s", name)
+		}
+	}
+	return nil
+}
+
+func validNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-'
+}
+
+// 默认的后台隧道名称：主机名，选择了部分转发时附加转发名称，例如 db-pg-web
+func DefaultName(host string, names []string) string {
+	name := strings.Join(append([]string{host}, names...), "-")
+	name = strings.Map(func(r rune) rune {
+		if validNameRune(r) {
+			return r
+		}
+		return '-'
+	}, name)
+	return strings.TrimLeft(name, ".-")
+}
+
+// 记录后台隧道的进程号
+func WritePID(dir, name string, pid int) error {
+	return fsutil.WriteFile(PIDPath(dir, name), []byte(strconv.Itoa(pid)+"\n"), 0600)
+}
+
+// 读取后台隧道的进程号
+func ReadPID(dir, name string) (int, error) {
+	data, err := os.ReadFile(PIDPath(dir, name))
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("无效的 pid 文件 %s", PIDPath(dir, name))
+	}
+	return pid, nil
+}
+
+// 删除后台隧道的 pid 文件
+func RemovePID(dir, name string) error {
+	err := os.Remove(PIDPath(dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// 打开后台隧道的日志文件（追加写入），过大时先轮转
+func OpenLog(dir, name string) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	path := LogPath(dir, name)
+	if info, err := os.Stat(path); err == nil && info.Size() > maxLogSize {
+		os.Rename(path, path+".1")
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+}
+
+// 列出隧道：先按名称列出后台隧道（包括已退出的），再列出前台运行的隧道
+func List(dir string) ([]Entry, error) {
+	states, err := Active(dir)
+	if err != nil {
+		return nil, err
+	}
+	byPID := make(map[int]int, len(states))
+	for i, state := range states {
+		byPID[state.PID] = i
+	}
+	used := make(map[int]bool)
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pid"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, path := range paths {
+		entry := Entry{Name: strings.TrimSuffix(filepath.Base(path), ".pid"), Status: StatusExited}
+		entry.PID, _ = ReadPID(dir, entry.Name)
+		if i, ok := byPID[entry.PID]; ok && states[i].Name == entry.Name && Alive(entry.PID) {
+			used[entry.PID] = true
+			entry.State = &states[i]
+			entry.Status = states[i].status()
+		} else if starting(path, entry.PID) {
+			entry.Status = StatusStarting
+		}
+		entries = append(entries, entry)
+	}
+
+	for i, state := range states {
+		if !used[state.PID] && state.Name == "" {
+			entries = append(entries, Entry{PID: state.PID, Status: state.status(), State: &states[i]})
+		}
+	}
+	return entries, nil
+}
+
+func (s State) status() string {
+	if s.Connected {
+		return StatusConnected
+	}
+	return StatusReconnecting
+}
+
+// 进程刚启动、尚未写入状态（pid 文件较旧时视为进程号已被复用）
+func starting(path string, pid int) bool {
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) < staleAfter && Alive(pid)
+}
+
+// 按名称查找后台隧道
+func Find(dir, name string) (Entry, bool, error) {
+	entries, err := List(dir)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	for _, entry := range entries {
+		if entry.Name != "" && entry.Name == name {
+			return entry, true, nil
+		}
+	}
+	return Entry{}, false, nil
+}
+
+// 停止后台隧道：先请求退出，超时后强制结束，并清理 pid 和状态文件；
+// 返回隧道停止前是否在运行
+func Stop(dir, name string, timeout time.Duration) (bool, error) {
+	entry, ok, err := Find(dir, name)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, os.ErrNotExist
+	}
+
+	running := entry.Running()
+	if running {
+		if err := terminate(entry.PID); err != nil && Alive(entry.PID) {
+			return true, fmt.Errorf("停止隧道 %s (PID %d) 失败: %v", name, entry.PID, err)
+		}
+		deadline := time.Now().Add(timeout)
+		for Alive(entry.PID) && time.Now().Before(deadline) {
+			time.Sleep(50 * time.Millisecond)
+		}
+		if Alive(entry.PID) {
+			kill(entry.PID)
+		}
+	}
+
+	Remove(dir, entry.PID)
+	return running, RemovePID(dir, name)
+}
@@ -0,0 +1,113 @@
+package tunnel
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// 测试隧道名称
+func TestNames(t *testing.T) {
+	if name := DefaultName("db bastion", []string{"pg", "web"}); name != "db-bastion-pg-web" {
+		t.Errorf("默认名称错误: %s", name)
+	}
+	if name := DefaultName("数据库", nil); name != "数据库" {
+		t.Errorf("默认名称错误: %s", name)
+	}
+	for _, name := range []string{"pg", "db-1.prod", "数据库_pg"} {
+		if err := CheckName(name); err != nil {
+			t.Errorf("%s 应为有效名称: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../x", "a/b", ".hidden", "-x", "a b"} {
+		if err := CheckName(name); err == nil {
+			t.Errorf("%q 应为无效名称", name)
+		}
+	}
+}
+
+// 测试列出后台隧道和前台隧道
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	self := os.Getpid()
+
+	// 运行中的后台隧道
+	if err := WritePID(dir, "pg", self); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(dir, State{Name: "pg", Host: "db", PID: self, Connected: true, Forwards: []Forward{{Health: "目标不可达"}}}); err != nil {
+		t.Fatal(err)
+	}
+	// 已退出的后台隧道（进程不存在）
+	if err := WritePID(dir, "gone", 1<<30); err != nil {
+		t.Fatal(err)
+	}
+	// 前台隧道
+	if err := Save(dir, State{Host: "web", PID: 101}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := List(dir)
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("隧道数量错误: %+v", entries)
+	}
+	if entries[0].Name != "gone" || entries[0].Status != StatusExited || entries[0].Running() {
+		t.Errorf("已退出的隧道状态错误: %+v", entries[0])
+	}
+	if entries[1].Name != "pg" || entries[1].Status != StatusConnected || !entries[1].State.Unhealthy() {
+		t.Errorf("运行中的隧道状态错误: %+v", entries[1])
+	}
+	if entries[2].Name != "" || entries[2].State.Host != "web" || entries[2].Status != StatusReconnecting {
+		t.Errorf("前台隧道状态错误: %+v", entries[2])
+	}
+
+	// 停止已退出的隧道只清理文件
+	running, err := Stop(dir, "gone", time.Second)
+	if err != nil || running {
+		t.Errorf("停止已退出的隧道: running=%v err=%v", running, err)
+	}
+	if _, err := os.Stat(PIDPath(dir, "gone")); !os.IsNotExist(err) {
+		t.Error("pid 文件应被删除")
+	}
+	if _, err := Stop(dir, "missing", time.Second); !os.IsNotExist(err) {
+		t.Errorf("不存在的隧道应返回 ErrNotExist: %v", err)
+	}
+}
+
+// 测试停止后台进程
+func TestStop(t *testing.T) {
+	dir := t.TempDir()
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("无法启动测试进程: %v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	if err := WritePID(dir, "sleep", cmd.Process.Pid); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, ok, err := Find(dir, "sleep")
+	if err != nil || !ok || entry.Status != StatusStarting {
+		t.Fatalf("刚启动的隧道应为启动中: %+v %v", entry, err)
+	}
+	running, err := Stop(dir, "sleep", 5*time.Second)
+	if err != nil || !running {
+		t.Fatalf("停止失败: running=%v err=%v", running, err)
+	}
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("进程未退出")
+	}
+	if _, ok, _ := Find(dir, "sleep"); ok {
+		t.Error("停止后不应再列出")
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !windows
+
+package tunnel
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+)
+
+// 进程是否存在
+func Alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// 请求进程退出
+func terminate(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
+
+// 强制结束进程
+func kill(pid int) {
+	syscall.Kill(pid, syscall.SIGKILL)
+}
+
+// 让子进程脱离当前终端会话，关闭终端后继续运行
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,124 @@
+package tunnel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+)
+
+// 状态超过该时间未更新时视为隧道已退出
+const staleAfter = 10 * time.Second
+
+// 运行中隧道的状态，由 hostmanager tunnel 定期写入，TUI 读取后显示
+type State struct {
+	Name      string    `json:"name,omitempty"` // 后台隧道名称，前台运行时为空
+	Host      string    `json:"host"`
+	Names     []string  `json:"names,omitempty"` // 使用 --name 选择的转发
+	PID       int       `json:"pid"`
+	Connected bool      `json:"connected"`
+	Restarts  int       `json:"restarts,omitempty"` // 自动重连/重启次数
+	Forwards  []Forward `json:"forwards"`
+	Started   time.Time `json:"started"`
+	Updated   time.Time `json:"updated"`
+}
+
+// 单个端口转发的状态
+type Forward struct {
+	Spec   string `json:"spec"` // 显示文本，例如 L 127.0.0.1:5432 → db:5432
+	Active int64  `json:"active"`
+	Total  int64  `json:"total"`
+	Health string `json:"health,omitempty"` // 健康检查结果：ok 或错误信息，尚未检查时为空
+}
+
+// 健康检查通过时 Health 的值
+const HealthOK = "ok"
+
+// 是否有转发未通过健康检查
+func (s State) Unhealthy() bool {
+	for _, f := range s.Forwards {
+		if f.Health != "" && f.Health != HealthOK {
+			return true
+		}
+	}
+	return false
+}
+
+// 当前连接数合计
+func (s State) Active() int64 {
+	var active int64
+	for _, f := range s.Forwards {
+		active += f.Active
+	}
+	return active
+}
+
+// 状态文件目录，默认 ~/.hostmanager/tunnels，可通过 HOSTMANAGER_TUNNEL_DIR 覆盖
+func Dir() string {
+	if dir := os.Getenv("HOSTMANAGER_TUNNEL_DIR"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".hostmanager", "tunnels")
+	}
+	return filepath.Join(home, ".hostmanager", "tunnels")
+}
+
+func statePath(dir string, pid int) string {
+	return filepath.Join(dir, fmt.Sprintf("%d.json", pid))
+}
+
+// 写入隧道状态（每个进程一个文件）
+func Save(dir string, state State) error {
+	state.Updated = time.Now()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(statePath(dir, state.PID), data, 0600)
+}
+
+// 隧道退出时删除状态文件
+func Remove(dir string, pid int) error {
+	err := os.Remove(statePath(dir, pid))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// 读取运行中的隧道，按主机名排序；过期的状态文件会被删除
+func Active(dir string) ([]State, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var states []State
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var state State
+		if err := json.Unmarshal(data, &state); err != nil {
+			continue
+		}
+		if time.Since(state.Updated) > staleAfter {
+			os.Remove(path)
+			continue
+		}
+		states = append(states, state)
+	}
+	sort.SliceStable(states, func(i, j int) bool {
+		return strings.ToLower(states[i].Host) < strings.ToLower(states[j].Host)
+	})
+	return states, nil
+}
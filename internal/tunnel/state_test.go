@@ -0,0 +1,47 @@
+package tunnel
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+// 测试写入、读取和清理隧道状态
+func TestStates(t *testing.T) {
+	dir := t.TempDir()
+	state := State{Host: "db", PID: 100, Connected: true, Forwards: []Forward{{Spec: "L 127.0.0.1:5432 → db:5432", Active: 2, Total: 5}}}
+	if err := Save(dir, state); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if err := Save(dir, State{Host: "App", PID: 101}); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	// 长时间未更新的状态视为已退出
+	stale, _ := json.Marshal(State{Host: "old", PID: 102, Updated: time.Now().Add(-time.Minute)})
+	if err := os.WriteFile(statePath(dir, 102), stale, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	states, err := Active(dir)
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	if len(states) != 2 || states[0].Host != "App" || states[1].Active() != 2 {
+		t.Errorf("读取结果错误: %+v", states)
+	}
+	if _, err := os.Stat(statePath(dir, 102)); !os.IsNotExist(err) {
+		t.Error("过期的状态文件应被删除")
+	}
+
+	if err := Remove(dir, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := Remove(dir, 100); err != nil {
+		t.Errorf("重复删除不应报错: %v", err)
+	}
+	if states, _ := Active(dir); len(states) != 1 {
+		t.Errorf("删除后仍存在: %+v", states)
+	}
+}
@@ -0,0 +1,699 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nsf/termbox-go"
+
+	"github.com/daihao4371/hostmanager/internal/hostkey"
+	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/ssh"
+	"github.com/daihao4371/hostmanager/internal/transfer"
+)
+
+// 传输队列区域最多显示的任务数
+const maxQueueRows = 3
+
+// 文件浏览器中的一项
+type browserEntry struct {
+	name string
+	dir  bool
+	link bool
+	size int64
+}
+
+// 文件浏览器的一侧（本地或远端）
+type browserPane struct {
+	fs      transfer.FS
+	title   string
+	dir     string
+	entries []browserEntry // 不在根目录时第一项为 ..
+	cursor  int
+	offset  int             // 滚动位置
+	marked  map[string]bool // 空格标记的文件
+}
+
+// 读取目录，失败时保持当前内容
+func (p *browserPane) load(dir string) error {
+	infos, err := p.fs.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var entries []browserEntry
+	for _, info := range infos {
+		entry := browserEntry{name: info.Name(), dir: info.IsDir(), size: info.Size()}
+		if info.Mode()&os.ModeSymlink != 0 {
+			entry.link = true
+			if target, err := p.fs.Stat(p.fs.Join(dir, info.Name())); err == nil {
+				entry.dir = target.IsDir()
+				entry.size = target.Size()
+			}
+		}
+		entries = append(entries, entry)
+	}
+	// 目录在前，按名称排序
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].dir != entries[j].dir {
+			return entries[i].dir
+		}
+		return strings.ToLower(entries[i].name) < strings.ToLower(entries[j].name)
+	})
+	if parent := p.fs.Join(dir, ".."); parent != dir {
+		entries = append([]browserEntry{{name: "..", dir: true}}, entries...)
+	}
+
+	p.dir = dir
+	p.entries = entries
+	p.cursor = 0
+	p.offset = 0
+	p.marked = map[string]bool{}
+	return nil
+}
+
+// 重新读取当前目录，保留光标位置和仍然存在的文件的标记
+func (p *browserPane) reload() error {
+	current, _ := p.selected()
+	marked := p.marked
+	if err := p.load(p.dir); err != nil {
+		return err
+	}
+	p.focus(current.name)
+	for _, entry := range p.entries {
+		if marked[entry.name] {
+			p.marked[entry.name] = true
+		}
+	}
+	return nil
+}
+
+// 把光标移到指定名称的文件上
+func (p *browserPane) focus(name string) {
+	for i, entry := range p.entries {
+		if entry.name == name {
+			p.cursor = i
+			return
+		}
+	}
+}
+
+// 光标所在的项
+func (p *browserPane) selected() (browserEntry, bool) {
+	if p.cursor < 0 || p.cursor >= len(p.entries) {
+		return browserEntry{}, false
+	}
+	return p.entries[p.cursor], true
+}
+
+// 移动光标
+func (p *browserPane) move(delta int) {
+	p.cursor += delta
+	if p.cursor >= len(p.entries) {
+		p.cursor = len(p.entries) - 1
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+}
+
+// 调整滚动位置，保证光标在可见的 rows 行内
+func (p *browserPane) scroll(rows int) {
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	} else if rows > 0 && p.cursor >= p.offset+rows {
+		p.offset = p.cursor - rows + 1
+	}
+}
+
+// 进入光标所在的目录
+func (p *browserPane) enter() error {
+	entry, ok := p.selected()
+	if !ok || !entry.dir {
+		return nil
+	}
+	if entry.name == ".." {
+		return p.up()
+	}
+	return p.load(p.fs.Join(p.dir, entry.name))
+}
+
+// 返回上级目录，光标停在原来的目录上
+func (p *browserPane) up() error {
+	parent := p.fs.Join(p.dir, "..")
+	if parent == p.dir {
+		return nil
+	}
+	name := p.fs.Base(p.dir)
+	if err := p.load(parent); err != nil {
+		return err
+	}
+	p.focus(name)
+	return nil
+}
+
+// 标记或取消标记光标所在的文件，并移到下一项
+func (p *browserPane) toggleMark() {
+	if entry, ok := p.selected(); ok && entry.name != ".." {
+		p.marked[entry.name] = !p.marked[entry.name]
+		if !p.marked[entry.name] {
+			delete(p.marked, entry.name)
+		}
+	}
+	p.move(1)
+}
+
+// 要操作的文件：有标记时为全部标记的文件，否则为光标所在的项
+func (p *browserPane) targets() []browserEntry {
+	var targets []browserEntry
+	for _, entry := range p.entries {
+		if p.marked[entry.name] {
+			targets = append(targets, entry)
+		}
+	}
+	if len(targets) == 0 {
+		if entry, ok := p.selected(); ok && entry.name != ".." {
+			targets = append(targets, entry)
+		}
+	}
+	return targets
+}
+
+// 文件的完整路径
+func (p *browserPane) path(name string) string {
+	return p.fs.Join(p.dir, name)
+}
+
+// 输入提示的类型
+const (
+	promptMkdir  = "mkdir"
+	promptRename = "rename"
+	promptDelete = "delete"
+	promptClose  = "close"
+)
+
+// 底部的输入提示：新建目录、重命名或确认删除
+type browserPrompt struct {
+	kind    string
+	label   string
+	input   string
+	targets []browserEntry
+}
+
+// 双栏文件浏览器：左侧本地目录，右侧远端目录
+type fileBrowser struct {
+	host   models.Host
+	closer io.Closer // 关闭时断开 SFTP 会话
+	panes  [2]*browserPane
+	active int
+	prompt *browserPrompt
+	queue  *transferQueue
+}
+
+// 创建文件浏览器，两侧分别打开 localDir 和 remoteDir
+func newFileBrowser(host models.Host, local, remote transfer.FS, localDir, remoteDir string, closer io.Closer, notify func()) (*fileBrowser, error) {
+	b := &fileBrowser{
+		host:   host,
+		closer: closer,
+		panes: [2]*browserPane{
+			{fs: local, title: "💻 本地"},
+			{fs: remote, title: "🌐 " + host.Name},
+		},
+		queue: newTransferQueue(notify),
+	}
+	if err := b.panes[0].load(localDir); err != nil {
+		return nil, err
+	}
+	if err := b.panes[1].load(remoteDir); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// 当前操作的一侧
+func (b *fileBrowser) pane() *browserPane {
+	return b.panes[b.active]
+}
+
+// 另一侧
+func (b *fileBrowser) other() *browserPane {
+	return b.panes[1-b.active]
+}
+
+// 把选中的文件加入传输队列，复制到另一侧的当前目录
+func (b *fileBrowser) copySelected() int {
+	src, dst := b.pane(), b.other()
+	icon := "⬆"
+	if b.active == 1 {
+		icon = "⬇"
+	}
+	targets := src.targets()
+	for _, entry := range targets {
+		b.queue.add(&transferTask{
+			label:  fmt.Sprintf("%s %s → %s", icon, src.fs.Label(src.path(entry.name)), dst.fs.Label(dst.dir)),
+			source: transfer.Endpoint{FS: src.fs, Path: src.path(entry.name)},
+			dest:   transfer.Endpoint{FS: dst.fs, Path: dst.dir},
+		})
+	}
+	src.marked = map[string]bool{}
+	return len(targets)
+}
+
+// 新建目录
+func (b *fileBrowser) mkdir(name string) error {
+	pane := b.pane()
+	if err := checkFileName(name); err != nil {
+		return err
+	}
+	if _, err := pane.fs.Stat(pane.path(name)); err == nil {
+		return fmt.Errorf("%s 已存在", name)
+	}
+	if err := pane.fs.MkdirAll(pane.path(name), 0755); err != nil {
+		return err
+	}
+	if err := pane.reload(); err != nil {
+		return err
+	}
+	pane.focus(name)
+	return nil
+}
+
+// 重命名光标所在的文件
+func (b *fileBrowser) rename(oldname, newname string) error {
+	pane := b.pane()
+	if err := checkFileName(newname); err != nil {
+		return err
+	}
+	if newname == oldname {
+		return nil
+	}
+	if _, err := pane.fs.Stat(pane.path(newname)); err == nil {
+		return fmt.Errorf("%s 已存在", newname)
+	}
+	if err := pane.fs.Rename(pane.path(oldname), pane.path(newname)); err != nil {
+		return err
+	}
+	if err := pane.reload(); err != nil {
+		return err
+	}
+	pane.focus(newname)
+	return nil
+}
+
+// 删除文件和目录（目录连同内容一起删除）
+func (b *fileBrowser) remove(targets []browserEntry) error {
+	pane := b.pane()
+	var failed error
+	for _, entry := range targets {
+		if err := pane.fs.RemoveAll(pane.path(entry.name)); err != nil && failed == nil {
+			failed = fmt.Errorf("删除 %s 失败: %v", entry.name, err)
+		}
+	}
+	if err := pane.reload(); err != nil && failed == nil {
+		failed = err
+	}
+	return failed
+}
+
+// 文件名不能为空，也不能包含路径分隔符
+func checkFileName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("名称无效")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("名称不能包含 / 或 \\")
+	}
+	return nil
+}
+
+// 传输结束后刷新两侧目录，返回界面是否需要重绘
+func (b *fileBrowser) refresh() bool {
+	if !b.queue.takeChanged() {
+		return b.queue.busy()
+	}
+	for _, pane := range b.panes {
+		pane.reload()
+	}
+	return true
+}
+
+// 关闭浏览器：停止队列并断开 SFTP 会话
+func (b *fileBrowser) close() {
+	b.queue.close()
+	if b.closer != nil {
+		b.closer.Close()
+	}
+}
+
+// 当前选中的主机（主机列表或收藏夹中）
+func (m *Menu) selectedHost() (models.Host, bool) {
+	if m.showFavorites {
+		favorites := m.getFavoriteHosts()
+		if m.currentHost < len(favorites) {
+			return favorites[m.currentHost], true
+		}
+	} else if m.inGroup && m.currentGroup < len(m.filteredGroups) && m.currentHost < len(m.filteredGroups[m.currentGroup].Hosts) {
+		return m.filteredGroups[m.currentGroup].Hosts[m.currentHost], true
+	}
+	return models.Host{}, false
+}
+
+// 打开主机的文件浏览器；连接时可能需要在终端输入密码，因此先退出全屏界面
+func (m *Menu) openBrowser(host models.Host) bool {
+	termbox.Close()
+	fmt.Printf("\n📁 正在打开 %s 的文件浏览器...\n", host.Name)
+	session, err := ssh.OpenSFTP(host)
+	var changed *hostkey.ChangedError
+	if errors.As(err, &changed) {
+		// 保留主机密钥改变的警告，避免被界面覆盖
+		fmt.Printf("按任意键返回主菜单...\n")
+		os.Stdin.Read(make([]byte, 1))
+	}
+
+	if initErr := termbox.Init(); initErr != nil {
+		log.Printf("重新初始化termbox失败: %v", initErr)
+		if session != nil {
+			session.Close()
+		}
+		return false
+	}
+	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+	m.needsRedraw = true
+	m.renderEngine = NewRenderEngine(m.currentTheme)
+
+	if err != nil {
+		m.showToast("打开文件浏览器失败: "+err.Error(), "error", 5*time.Second)
+		return true
+	}
+	localDir, err := os.Getwd()
+	if err != nil {
+		localDir = "."
+	}
+	remoteDir, err := session.Getwd()
+	if err != nil {
+		remoteDir = "."
+	}
+	notify := func() { go termbox.Interrupt() }
+	browser, err := newFileBrowser(host, transfer.Local{}, transfer.Remote{Host: host.Name, Client: session.Client}, localDir, remoteDir, session, notify)
+	if err != nil {
+		session.Close()
+		m.showToast("读取目录失败: "+err.Error(), "error", 5*time.Second)
+		return true
+	}
+	m.browser = browser
+	return true
+}
+
+// 关闭文件浏览器
+func (m *Menu) closeBrowser() {
+	m.browser.close()
+	m.browser = nil
+}
+
+// 文件浏览器的按键处理
+func (m *Menu) handleBrowserInput(ev termbox.Event) bool {
+	b := m.browser
+	if b.prompt != nil {
+		m.handleBrowserPrompt(ev)
+		return true
+	}
+
+	pane := b.pane()
+	var err error
+	switch {
+	case ev.Key == termbox.KeyEsc:
+		if b.queue.busy() {
+			b.prompt = &browserPrompt{kind: promptClose, label: "传输尚未完成，关闭后将中断，确定关闭？(y/N)"}
+		} else {
+			m.closeBrowser()
+		}
+	case ev.Key == termbox.KeyTab, ev.Key == termbox.KeyArrowLeft, ev.Key == termbox.KeyArrowRight:
+		b.active = 1 - b.active
+	case ev.Key == termbox.KeyArrowUp:
+		pane.move(-1)
+	case ev.Key == termbox.KeyArrowDown:
+		pane.move(1)
+	case ev.Key == termbox.KeyPgup:
+		pane.move(-10)
+	case ev.Key == termbox.KeyPgdn:
+		pane.move(10)
+	case ev.Key == termbox.KeyHome:
+		pane.move(-len(pane.entries))
+	case ev.Key == termbox.KeyEnd:
+		pane.move(len(pane.entries))
+	case ev.Key == termbox.KeyEnter:
+		err = pane.enter()
+	case ev.Key == termbox.KeyBackspace, ev.Key == termbox.KeyBackspace2:
+		err = pane.up()
+	case ev.Key == termbox.KeySpace:
+		pane.toggleMark()
+	case ev.Ch == 'c', ev.Key == termbox.KeyF5:
+		if n := b.copySelected(); n > 0 {
+			m.showToast(fmt.Sprintf("已加入传输队列: %d 项", n), "info", 2*time.Second)
+		}
+	case ev.Ch == 'm', ev.Key == termbox.KeyF7:
+		b.prompt = &browserPrompt{kind: promptMkdir, label: "📁 新建目录: "}
+	case ev.Ch == 'n', ev.Key == termbox.KeyF2:
+		if entry, ok := pane.selected(); ok && entry.name != ".." {
+			b.prompt = &browserPrompt{kind: promptRename, label: "✏️  重命名为: ", input: entry.name, targets: []browserEntry{entry}}
+		}
+	case ev.Ch == 'd', ev.Key == termbox.KeyF8, ev.Key == termbox.KeyDelete:
+		if targets := pane.targets(); len(targets) > 0 {
+			label := targets[0].name
+			if len(targets) > 1 {
+				label = fmt.Sprintf("%d 项", len(targets))
+			}
+			b.prompt = &browserPrompt{kind: promptDelete, label: fmt.Sprintf("🗑️  删除 %s（目录连同内容）？(y/N)", label), targets: targets}
+		}
+	case ev.Ch == 'r':
+		err = pane.reload()
+	}
+	if err != nil {
+		m.showToast(err.Error(), "error", 3*time.Second)
+	}
+	return true
+}
+
+// 底部输入提示的按键处理
+func (m *Menu) handleBrowserPrompt(ev termbox.Event) {
+	b := m.browser
+	prompt := b.prompt
+
+	// 确认类提示只接受 y
+	if prompt.kind == promptDelete || prompt.kind == promptClose {
+		b.prompt = nil
+		if ev.Ch != 'y' && ev.Ch != 'Y' {
+			return
+		}
+		if prompt.kind == promptClose {
+			m.closeBrowser()
+		} else if err := b.remove(prompt.targets); err != nil {
+			m.showToast(err.Error(), "error", 3*time.Second)
+		} else {
+			m.showToast(fmt.Sprintf("已删除 %d 项", len(prompt.targets)), "success", 2*time.Second)
+		}
+		return
+	}
+
+	switch ev.Key {
+	case termbox.KeyEsc:
+		b.prompt = nil
+	case termbox.KeyBackspace, termbox.KeyBackspace2:
+		if runes := []rune(prompt.input); len(runes) > 0 {
+			prompt.input = string(runes[:len(runes)-1])
+		}
+	case termbox.KeyEnter:
+		b.prompt = nil
+		var err error
+		if prompt.kind == promptMkdir {
+			err = b.mkdir(prompt.input)
+		} else {
+			err = b.rename(prompt.targets[0].name, prompt.input)
+		}
+		if err != nil {
+			m.showToast(err.Error(), "error", 3*time.Second)
+		}
+	case termbox.KeySpace:
+		prompt.input += " "
+	default:
+		if ev.Ch != 0 {
+			prompt.input += string(ev.Ch)
+		}
+	}
+}
+
+// 绘制文件浏览器：标题、左右两栏、传输队列和底部提示
+func (m *Menu) drawBrowser() {
+	b := m.browser
+	width, height := termbox.Size()
+
+	title := fmt.Sprintf("📁 文件浏览: %s (%s@%s:%d)", b.host.Name, b.host.Username, b.host.IP, b.host.Port)
+	m.printThemedStringInBounds(0, 0, title, m.currentTheme.Info, width)
+
+	tasks := b.queue.snapshot()
+	queueHeight := transferQueueHeight(tasks)
+	paneHeight := height - 2 - queueHeight
+	if paneHeight < 3 {
+		queueHeight, paneHeight = 0, height-2
+	}
+	left, right := splitColumns(Rect{X: 0, Y: 1, Width: width, Height: paneHeight})
+	m.drawBrowserPane(b.panes[0], left, b.active == 0)
+	m.drawBrowserPane(b.panes[1], right, b.active == 1)
+	if queueHeight > 0 {
+		m.drawTransferQueue(tasks, 1+paneHeight, width)
+	}
+
+	if b.prompt != nil {
+		input := ""
+		if b.prompt.kind == promptMkdir || b.prompt.kind == promptRename {
+			input = b.prompt.input + "█"
+		}
+		m.printThemedStringInBounds(0, height-1, b.prompt.label+input, m.currentTheme.Warning, width)
+	} else {
+		help := "Tab 切换 | 回车 进入 | ⌫ 上级 | 空格 标记 | c 复制到另一侧 | m 新建目录 | n 重命名 | d 删除 | r 刷新 | ESC 返回"
+		m.printThemedStringInBounds(0, height-1, truncateStringToWidth(help, width), m.currentTheme.Border, width)
+	}
+}
+
+// 在边框内绘制一侧的文件列表，当前操作的一侧高亮边框
+func (m *Menu) drawBrowserPane(pane *browserPane, bounds Rect, active bool) {
+	style := CreatePremiumStyle(m.currentTheme)
+	style.Padding = Padding{Top: 1, Right: 1, Bottom: 1, Left: 1}
+	titleColor := m.currentTheme.Border
+	if active {
+		style.Border.Color = m.currentTheme.Highlight
+		titleColor = m.currentTheme.Highlight
+	}
+	m.renderEngine.drawRoundedBorder(bounds.X, bounds.Y, bounds.Width, bounds.Height, style.Border)
+
+	title := truncateStringToWidth(fmt.Sprintf(" %s: %s ", pane.title, pane.dir), bounds.Width-4)
+	m.printThemedStringInBounds(bounds.X+2, bounds.Y, title, titleColor, bounds.Width-4)
+
+	x := bounds.X + style.Padding.Left
+	width := bounds.Width - style.Padding.Left - style.Padding.Right
+	rows := bounds.Height - style.Padding.Top - style.Padding.Bottom
+	pane.scroll(rows)
+	for i := 0; i < rows && pane.offset+i < len(pane.entries); i++ {
+		index := pane.offset + i
+		entry := pane.entries[index]
+		y := bounds.Y + style.Padding.Top + i
+
+		color, prefix := m.currentTheme.Foreground, "  "
+		if pane.marked[entry.name] {
+			color, prefix = m.currentTheme.Warning, "* "
+		}
+		if index == pane.cursor && active {
+			color, prefix = m.currentTheme.Highlight, "▶ "
+		} else if index == pane.cursor {
+			prefix = "▷ "
+		}
+
+		icon, name, size := "📄", entry.name, ""
+		switch {
+		case entry.dir:
+			icon, name = "📁", entry.name+"/"
+		case entry.link:
+			icon = "🔗"
+		}
+		if !entry.dir {
+			size = transfer.FormatSize(entry.size)
+		}
+		nameWidth := width - len(size) - 1
+		m.printThemedStringInBounds(x, y, truncateStringToWidth(prefix+icon+" "+name, nameWidth), color, nameWidth)
+		if size != "" {
+			m.printThemedStringInBounds(x+width-len(size), y, size, m.currentTheme.Border, len(size))
+		}
+	}
+	if len(pane.entries) == 0 && rows > 0 {
+		m.printThemedStringInBounds(x, bounds.Y+style.Padding.Top, "  (空目录)", m.currentTheme.Border, width)
+	}
+}
+
+// 传输队列区域的高度：标题、任务列表，传输中再加进度条（两行）和详情
+func transferQueueHeight(tasks []transferTask) int {
+	if len(tasks) == 0 {
+		return 0
+	}
+	height := 1 + minInt(len(tasks), maxQueueRows)
+	if runningTask(tasks) != nil {
+		height += 3
+	}
+	return height
+}
+
+// 正在传输的任务
+func runningTask(tasks []transferTask) *transferTask {
+	for i := range tasks {
+		if tasks[i].status == taskRunning {
+			return &tasks[i]
+		}
+	}
+	return nil
+}
+
+// 队列中显示的任务：优先显示正在传输和等待中的任务，其余显示最近结束的任务
+func visibleTasks(tasks []transferTask) []transferTask {
+	start := len(tasks) - maxQueueRows
+	for i, task := range tasks {
+		if task.status == taskPending || task.status == taskRunning {
+			if i < start {
+				start = i
+			}
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := minInt(start+maxQueueRows, len(tasks))
+	return tasks[start:end]
+}
+
+// 绘制传输队列和当前任务的进度条
+func (m *Menu) drawTransferQueue(tasks []transferTask, y, width int) {
+	counts := map[int]int{}
+	for _, task := range tasks {
+		counts[task.status]++
+	}
+	header := fmt.Sprintf("── 📦 传输队列: %d 个完成 | %d 个失败 | 剩余 %d 个 ", counts[taskDone], counts[taskFailed], counts[taskPending]+counts[taskRunning])
+	header += strings.Repeat("─", maxInt(0, width-getDisplayWidth(header)-1))
+	m.printThemedString(0, y, header, m.currentTheme.Border)
+	y++
+
+	for _, task := range visibleTasks(tasks) {
+		color := m.currentTheme.Foreground
+		text := task.icon() + " " + task.label
+		switch task.status {
+		case taskRunning:
+			color = m.currentTheme.Info
+		case taskDone:
+			color = m.currentTheme.Success
+		case taskFailed:
+			color = m.currentTheme.Error
+			text += ": " + task.err.Error()
+		}
+		m.printThemedString(0, y, truncateStringToWidth(text, width-1), color)
+		y++
+	}
+
+	if task := runningTask(tasks); task != nil && task.progress != nil {
+		status := task.progress.Status()
+		m.renderEngine.RenderAdvancedProgressBar(1, y, width-2, float32(status.Ratio()), 1, true)
+		detail := fmt.Sprintf("%d/%d  %s/%s  %s/s  %s", status.Index, status.Files,
+			transfer.FormatSize(status.Done), transfer.FormatSize(status.Total), transfer.FormatSize(status.Speed), status.Name)
+		m.printThemedString(1, y+2, truncateStringToWidth(detail, width-2), m.currentTheme.Border)
+	}
+}
+
+// 辅助函数：获取两个整数的较大值
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
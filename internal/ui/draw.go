@@ -13,7 +13,9 @@ import (
 func (m *Menu) draw() {
 	termbox.Clear(m.currentTheme.Background, m.currentTheme.Background)
 
-	if m.config.UIConfig.Layout.Type == "columns" {
+	if m.browser != nil {
+		m.drawBrowser()
+	} else if m.config.UIConfig.Layout.Type == "columns" {
 		m.drawColumnsLayout()
 	} else {
 		m.drawSingleLayout()
@@ -42,18 +44,18 @@ func (m *Menu) drawSingleLayout() {
 // 分栏布局绘制
 func (m *Menu) drawColumnsLayout() {
 	width, height := termbox.Size()
-	leftWidth := width / 2
+	left, right := splitColumns(Rect{Width: width, Height: height})
 
 	// 左栏：分组和快速连接
-	m.drawLeftColumn(0, 0, leftWidth, height)
+	m.drawLeftColumn(left.X, left.Y, left.Width, left.Height)
 
 	// 分隔线
 	for i := 0; i < height; i++ {
-		termbox.SetCell(leftWidth, i, '│', m.currentTheme.Border, m.currentTheme.Background)
+		termbox.SetCell(left.Width, i, '│', m.currentTheme.Border, m.currentTheme.Background)
 	}
 
 	// 右栏：主机列表或详细信息
-	m.drawRightColumn(leftWidth+1, 0, width-leftWidth-1, height)
+	m.drawRightColumn(right.X, right.Y, right.Width, right.Height)
 }
 
 // 绘制搜索模式
@@ -72,7 +74,9 @@ func (m *Menu) drawSearchMode(y int) int {
 	y += 2
 
 	// 搜索结果提示
-	if len(m.filteredGroups) == 0 {
+	if m.searchError != nil {
+		m.printThemedString(0, y, "❌ "+m.searchError.Error(), m.currentTheme.Error)
+	} else if len(m.filteredGroups) == 0 {
 		m.printThemedString(0, y, "❌ "+m.texts.NoMatches, m.currentTheme.Error)
 	} else {
 		totalHosts := 0
@@ -116,6 +120,9 @@ func (m *Menu) drawHeader(y int) int {
 
 	// 显示当前主题和布局信息
 	themeInfo := fmt.Sprintf("主题: %s | 布局: %s", m.config.UIConfig.Theme, m.config.UIConfig.Layout.Type)
+	if len(m.tunnels) > 0 {
+		themeInfo += fmt.Sprintf(" | 🔀 运行中的隧道: %d", len(m.tunnels))
+	}
 	m.printThemedString(0, y, themeInfo, m.currentTheme.Border)
 	y++
 
@@ -130,9 +137,9 @@ func (m *Menu) drawHeader(y int) int {
 		y++
 	} else if maxOperationWidth < 80 {
 		// 中等宽度：分两行显示
-		m.printThemedString(0, y, "操作: ↑↓选择 | 回车连接 | /搜索 | f收藏夹", m.currentTheme.Foreground)
+		m.printThemedString(0, y, "操作: ↑↓选择 | 回车连接 | /搜索 | f收藏夹 | #标签", m.currentTheme.Foreground)
 		y++
-		m.printThemedString(0, y, "s状态检查 | r重载 | t主题 | l布局 | ESC退出", m.currentTheme.Foreground)
+		m.printThemedString(0, y, "s状态检查 | p隧道 | b文件 | r重载 | t主题 | l布局 | ESC退出", m.currentTheme.Foreground)
 		y++
 	} else if getDisplayWidth(operations) > maxOperationWidth {
 		// 宽度充足但操作文本太长：使用智能分割
@@ -180,8 +187,18 @@ func (m *Menu) drawQuickConnect(y int) int {
 
 // 绘制主要内容区域
 func (m *Menu) drawMainContent(y int) {
+	if m.showTunnels {
+		width, height := termbox.Size()
+		m.drawTunnelPanel(0, y, width, height)
+		return
+	}
+
 	groups := m.filteredGroups
 	if len(groups) == 0 {
+		if m.tagMode && !m.searchMode {
+			m.printThemedString(0, y, m.groupsTitle(), m.currentTheme.Success)
+			m.printThemedString(0, y+1, "   "+m.texts.NoTags, m.currentTheme.Border)
+		}
 		return
 	}
 
@@ -210,7 +227,7 @@ func (m *Menu) getStatusIcon(status string) string {
 
 // 获取认证类型图标
 func (m *Menu) getAuthIcon(host models.Host) string {
-	if host.AuthType == "password" || (host.AuthType == "key" && host.KeyPath == "" && host.Password != "") {
+	if host.AuthType == "password" || (host.AuthType == "key" && host.KeyPath == "" && host.HasStoredPassword()) {
 		return "🔐" // 密码认证
 	}
 	return "🔑" // 密钥认证
@@ -218,6 +235,11 @@ func (m *Menu) getAuthIcon(host models.Host) string {
 
 // 带主题的字符串打印（正确处理宽字符）
 func (m *Menu) printThemedString(x, y int, str string, color termbox.Attribute) {
+	m.printHighlightedString(x, y, str, color, nil)
+}
+
+// 带主题的字符串打印，highlights 中的字符（按 rune 下标）使用搜索高亮色
+func (m *Menu) printHighlightedString(x, y int, str string, color termbox.Attribute, highlights map[int]bool) {
 	width, height := termbox.Size()
 	
 	// 边界检查
@@ -226,14 +248,14 @@ func (m *Menu) printThemedString(x, y int, str string, color termbox.Attribute)
 	}
 	
 	screenX := x
-	for _, r := range str {
+	for i, r := range []rune(str) {
 		// 检查是否超出屏幕右边界
 		if screenX >= width {
 			break
 		}
 		
 		// 设置主字符
-		termbox.SetCell(screenX, y, r, color, m.currentTheme.Background)
+		termbox.SetCell(screenX, y, r, m.highlightColor(color, highlights[i]), m.currentTheme.Background)
 		screenX++
 		
 		// 如果是宽字符（中文、emoji等），需要设置占位符
@@ -250,6 +272,14 @@ func (m *Menu) printThemedString(x, y int, str string, color termbox.Attribute)
 	}
 }
 
+// 搜索匹配字符的颜色
+func (m *Menu) highlightColor(color termbox.Attribute, matched bool) termbox.Attribute {
+	if matched {
+		return m.currentTheme.Warning | termbox.AttrBold
+	}
+	return color
+}
+
 // 绘制Toast通知
 func (m *Menu) drawToasts() {
 	width, height := termbox.Size()
@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// 带搜索高亮标记的一行文本（标记按 rune 下标记录）
+type highlightedLine struct {
+	text  strings.Builder
+	runes int
+	marks map[int]bool
+}
+
+// 追加普通文本
+func (l *highlightedLine) add(s string) {
+	l.text.WriteString(s)
+	l.runes += utf8.RuneCountInString(s)
+}
+
+// 追加字段文本，positions 为字段内匹配字符的下标
+func (l *highlightedLine) addMatched(s string, positions []int) {
+	if len(positions) > 0 && l.marks == nil {
+		l.marks = map[int]bool{}
+	}
+	for _, p := range positions {
+		l.marks[l.runes+p] = true
+	}
+	l.add(s)
+}
+
+func (l *highlightedLine) String() string {
+	return l.text.String()
+}
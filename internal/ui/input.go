@@ -15,14 +15,25 @@ func (m *Menu) handleInput() bool {
 	switch ev.Type {
 	case termbox.EventKey:
 		m.needsRedraw = true
-		if m.searchMode {
+		if m.browser != nil {
+			return m.handleBrowserInput(ev)
+		} else if m.searchMode {
 			return m.handleSearchInput(ev)
+		} else if m.showTunnels {
+			return m.handleTunnelPanelInput(ev)
 		} else {
 			return m.handleNormalInput(ev)
 		}
 	case termbox.EventResize:
 		m.renderEngine = NewRenderEngine(m.currentTheme)
 		m.needsRedraw = true
+	case termbox.EventInterrupt:
+		if m.refreshTunnels() {
+			m.needsRedraw = true
+		}
+		if m.browser != nil && m.browser.refresh() {
+			m.needsRedraw = true
+		}
 	case termbox.EventError:
 		log.Printf("Termbox事件错误: %v", ev.Err)
 		return false
@@ -94,6 +105,8 @@ func (m *Menu) handleNormalInput(ev termbox.Event) bool {
 			m.currentHost = 0
 		} else if m.showFavorites {
 			m.showFavorites = false
+		} else if m.tagMode {
+			m.toggleTagMode()
 		} else {
 			return false // 退出程序
 		}
@@ -118,6 +131,16 @@ func (m *Menu) handleNormalInput(ev termbox.Event) bool {
 		case 'f', 'F':
 			m.showFavorites = !m.showFavorites
 			m.currentHost = 0
+		case '#':
+			m.toggleTagMode()
+		case 'p', 'P':
+			m.showTunnels = !m.showTunnels
+			m.refreshTunnels()
+		case 'b', 'B':
+			if host, ok := m.selectedHost(); ok {
+				return m.openBrowser(host)
+			}
+			m.showToast("请先在主机列表或收藏夹中选中主机", "info", 2*time.Second)
 		case 's', 'S':
 			m.checkAllHostsStatus()
 			m.showToast("正在检查主机状态...", "info", 3*time.Second)
@@ -131,6 +154,17 @@ func (m *Menu) handleNormalInput(ev termbox.Event) bool {
 	return true
 }
 
+// 隧道面板打开时只响应关闭和退出
+func (m *Menu) handleTunnelPanelInput(ev termbox.Event) bool {
+	switch {
+	case ev.Key == termbox.KeyEsc, ev.Ch == 'p', ev.Ch == 'P':
+		m.showTunnels = false
+	case ev.Ch == 'q', ev.Ch == 'Q':
+		return false
+	}
+	return true
+}
+
 // 处理向上移动
 func (m *Menu) moveUp() {
 	if m.showFavorites {
@@ -2,9 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/nsf/termbox-go"
 
+	"github.com/daihao4371/hostmanager/internal/fuzzy"
 	"github.com/daihao4371/hostmanager/internal/models"
 )
 
@@ -40,7 +43,7 @@ func (m *Menu) drawFavorites(y int) {
 // 绘制分组列表
 func (m *Menu) drawGroups(y int, groups []models.Group) {
 	if !m.searchMode {
-		m.printThemedString(0, y, "📁 "+m.texts.ServerGroups, m.currentTheme.Success)
+		m.printThemedString(0, y, m.groupsTitle(), m.currentTheme.Success)
 		y++
 	}
 
@@ -79,6 +82,14 @@ func (m *Menu) drawGroups(y int, groups []models.Group) {
 	}
 }
 
+// 分组列表标题（标签模式下显示标签）
+func (m *Menu) groupsTitle() string {
+	if m.tagMode {
+		return "🏷️  " + m.texts.TagView
+	}
+	return "📁 " + m.texts.ServerGroups
+}
+
 // 绘制主机列表
 func (m *Menu) drawHosts(y int, groups []models.Group) {
 	if m.currentGroup >= len(groups) {
@@ -88,6 +99,9 @@ func (m *Menu) drawHosts(y int, groups []models.Group) {
 
 	if !m.searchMode {
 		groupHeader := fmt.Sprintf("📂 分组: %s (按ESC返回，空格收藏)", group.Name)
+		if m.tagMode {
+			groupHeader = fmt.Sprintf("🏷️  标签: %s (按ESC返回，空格收藏)", group.Name)
+		}
 		m.printThemedString(0, y, groupHeader, m.currentTheme.Info)
 		y++
 		m.printThemedString(0, y, "───────────────────────────────────────────────────────────", m.currentTheme.Border)
@@ -109,15 +123,34 @@ func (m *Menu) drawHosts(y int, groups []models.Group) {
 			favoriteIcon = "⭐"
 		}
 
-		hostInfo := fmt.Sprintf("%s%s%s%s %s (%s@%s:%d)", prefix, statusIcon, authIcon, favoriteIcon, host.Name, host.Username, host.IP, host.Port)
+		var line highlightedLine
+		line.add(prefix + statusIcon + authIcon + favoriteIcon + m.tunnelIcon(host) + " ")
+		line.addMatched(host.Name, m.matchPositions(host, fuzzy.FieldName))
+		line.add(" (")
+		line.addMatched(host.Username, m.matchPositions(host, fuzzy.FieldUsername))
+		line.add("@")
+		line.addMatched(host.IP, m.matchPositions(host, fuzzy.FieldIP))
+		line.add(fmt.Sprintf(":%d)", host.Port))
+		if len(host.Via) > 0 {
+			line.add(fmt.Sprintf(" 🛡️ %s", host.ViaDescription()))
+		}
 		if host.Description != "" {
-			hostInfo += fmt.Sprintf(" - %s", host.Description)
+			line.add(" - ")
+			line.addMatched(host.Description, m.matchPositions(host, fuzzy.FieldDescription))
 		}
-		m.printThemedString(0, y, hostInfo, color)
+		m.printHighlightedString(0, y, line.String(), color, line.marks)
 		y++
 	}
 }
 
+// 左右分栏：左栏占一半宽度，两栏之间留一列分隔
+func splitColumns(bounds Rect) (left, right Rect) {
+	leftWidth := bounds.Width / 2
+	left = Rect{X: bounds.X, Y: bounds.Y, Width: leftWidth, Height: bounds.Height}
+	right = Rect{X: bounds.X + leftWidth + 1, Y: bounds.Y, Width: bounds.Width - leftWidth - 1, Height: bounds.Height}
+	return left, right
+}
+
 // 左栏绘制（分栏布局）
 func (m *Menu) drawLeftColumn(x, y, width, height int) {
 	currentY := y
@@ -144,7 +177,7 @@ func (m *Menu) drawLeftColumn(x, y, width, height int) {
 	}
 
 	// 分组列表
-	m.printThemedStringInBounds(x, currentY, "📁 "+m.texts.ServerGroups, m.currentTheme.Success, width)
+	m.printThemedStringInBounds(x, currentY, m.groupsTitle(), m.currentTheme.Success, width)
 	currentY++
 	for i, group := range m.filteredGroups {
 		if currentY >= height-1 {
@@ -176,7 +209,9 @@ func (m *Menu) drawLeftColumn(x, y, width, height int) {
 
 // 右栏绘制（分栏布局）
 func (m *Menu) drawRightColumn(x, y, width, height int) {
-	if m.showFavorites {
+	if m.showTunnels {
+		m.drawTunnelPanel(x, y, width, height)
+	} else if m.showFavorites {
 		m.drawFavoritesInColumn(x, y, width, height)
 	} else if m.inGroup && m.currentGroup < len(m.filteredGroups) {
 		m.drawHostsInColumn(x, y, width, height)
@@ -188,6 +223,12 @@ func (m *Menu) drawRightColumn(x, y, width, height int) {
 		y++
 		m.printThemedStringInBounds(x, y, "回车 进入分组/连接主机", m.currentTheme.Foreground, width)
 		y++
+		m.printThemedStringInBounds(x, y, "# 按标签浏览", m.currentTheme.Foreground, width)
+		y++
+		m.printThemedStringInBounds(x, y, "p 查看隧道", m.currentTheme.Foreground, width)
+		y++
+		m.printThemedStringInBounds(x, y, "b 浏览选中主机的文件", m.currentTheme.Foreground, width)
+		y++
 		m.printThemedStringInBounds(x, y, "t 切换主题", m.currentTheme.Foreground, width)
 		y++
 		m.printThemedStringInBounds(x, y, "l 切换布局", m.currentTheme.Foreground, width)
@@ -196,6 +237,11 @@ func (m *Menu) drawRightColumn(x, y, width, height int) {
 
 // 在指定范围内绘制字符串（正确处理宽字符）
 func (m *Menu) printThemedStringInBounds(x, y int, str string, color termbox.Attribute, maxWidth int) {
+	m.printHighlightedStringInBounds(x, y, str, color, maxWidth, nil)
+}
+
+// 在指定范围内绘制带搜索高亮的字符串
+func (m *Menu) printHighlightedStringInBounds(x, y int, str string, color termbox.Attribute, maxWidth int, highlights map[int]bool) {
 	if maxWidth <= 0 {
 		return
 	}
@@ -204,7 +250,7 @@ func (m *Menu) printThemedStringInBounds(x, y int, str string, color termbox.Att
 	currentDisplayWidth := 0
 	screenX := x
 	
-	for _, r := range runes {
+	for i, r := range runes {
 		// 计算字符显示宽度
 		charWidth := 1
 		if r >= 0x1F300 && r <= 0x1F9FF { // Emoji范围
@@ -225,7 +271,7 @@ func (m *Menu) printThemedStringInBounds(x, y int, str string, color termbox.Att
 		}
 		
 		// 设置主字符
-		termbox.SetCell(screenX, y, r, color, m.currentTheme.Background)
+		termbox.SetCell(screenX, y, r, m.highlightColor(color, highlights[i]), m.currentTheme.Background)
 		screenX++
 		currentDisplayWidth++
 		
@@ -270,6 +316,9 @@ func (m *Menu) drawHostsInColumn(x, y, width, height int) {
 	group := m.filteredGroups[m.currentGroup]
 
 	groupHeader := fmt.Sprintf("📂 %s", group.Name)
+	if m.tagMode {
+		groupHeader = fmt.Sprintf("🏷️  %s", group.Name)
+	}
 	m.printThemedStringInBounds(x, y, groupHeader, m.currentTheme.Info, width)
 	y++
 
@@ -291,18 +340,51 @@ func (m *Menu) drawHostsInColumn(x, y, width, height int) {
 			favoriteIcon = "⭐"
 		}
 
-		hostInfo := fmt.Sprintf("%s%s%s%s %s", prefix, statusIcon, authIcon, favoriteIcon, host.Name)
-		m.printThemedStringInBounds(x, y, hostInfo, color, width)
+		var line highlightedLine
+		line.add(prefix + statusIcon + authIcon + favoriteIcon + m.tunnelIcon(host) + " ")
+		line.addMatched(host.Name, m.matchPositions(host, fuzzy.FieldName))
+		m.printHighlightedStringInBounds(x, y, line.String(), color, width, line.marks)
 		y++
 
 		// 在分栏模式下显示更多详细信息
 		if m.config.UIConfig.Layout.ShowDetails && prefix == "▶ " {
-			detailInfo := fmt.Sprintf("    %s@%s:%d", host.Username, host.IP, host.Port)
-			m.printThemedStringInBounds(x, y, detailInfo, m.currentTheme.Border, width)
+			var detail highlightedLine
+			detail.add("    ")
+			detail.addMatched(host.Username, m.matchPositions(host, fuzzy.FieldUsername))
+			detail.add("@")
+			detail.addMatched(host.IP, m.matchPositions(host, fuzzy.FieldIP))
+			detail.add(fmt.Sprintf(":%d", host.Port))
+			m.printHighlightedStringInBounds(x, y, detail.String(), m.currentTheme.Border, width, detail.marks)
 			y++
+			if len(host.Via) > 0 {
+				jumpInfo := fmt.Sprintf("    跳板机: %s", host.ViaDescription())
+				m.printThemedStringInBounds(x, y, jumpInfo, m.currentTheme.Border, width)
+				y++
+			}
+			for _, state := range m.hostTunnels(host) {
+				for _, forward := range state.Forwards {
+					info := fmt.Sprintf("    🔀 %s (%d 个连接)", forward.Spec, forward.Active)
+					m.printThemedStringInBounds(x, y, info, m.currentTheme.Success, width)
+					y++
+				}
+			}
+			if host.Source != "" && host.Source != m.config.Path() {
+				source := fmt.Sprintf("    来源: %s", filepath.Base(host.Source))
+				m.printThemedStringInBounds(x, y, source, m.currentTheme.Border, width)
+				y++
+			}
+			if len(host.Tags) > 0 {
+				var tags highlightedLine
+				tags.add("    标签: ")
+				tags.addMatched(strings.Join(host.Tags, " "), m.matchPositions(host, fuzzy.FieldTags))
+				m.printHighlightedStringInBounds(x, y, tags.String(), m.currentTheme.Border, width, tags.marks)
+				y++
+			}
 			if host.Description != "" {
-				descInfo := fmt.Sprintf("    %s", host.Description)
-				m.printThemedStringInBounds(x, y, descInfo, m.currentTheme.Border, width)
+				var desc highlightedLine
+				desc.add("    ")
+				desc.addMatched(host.Description, m.matchPositions(host, fuzzy.FieldDescription))
+				m.printHighlightedStringInBounds(x, y, desc.String(), m.currentTheme.Border, width, desc.marks)
 				y++
 			}
 		}
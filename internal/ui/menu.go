@@ -4,18 +4,26 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/nsf/termbox-go"
 
 	"github.com/daihao4371/hostmanager/internal/config"
+	"github.com/daihao4371/hostmanager/internal/fuzzy"
+	"github.com/daihao4371/hostmanager/internal/history"
 	"github.com/daihao4371/hostmanager/internal/i18n"
 	"github.com/daihao4371/hostmanager/internal/models"
+	"github.com/daihao4371/hostmanager/internal/query"
 	"github.com/daihao4371/hostmanager/internal/ssh"
 	"github.com/daihao4371/hostmanager/internal/theme"
+	"github.com/daihao4371/hostmanager/internal/tunnel"
 )
 
+// 快速连接列表中最多显示的主机数
+const maxRecentHosts = 5
+
 // 菜单管理器（增强版）
 type Menu struct {
 	groups            []models.Group
@@ -25,8 +33,12 @@ type Menu struct {
 	inGroup           bool
 	searchMode        bool
 	searchQuery       string
-	connectionHistory []models.Host
+	connectionHistory []models.Host              // 快速连接列表（按使用频率排序）
+	frecency          history.Scores             // 主机使用频率评分
+	searchMatches     map[string]fuzzy.HostMatch // 当前搜索的匹配结果
+	searchError       error                      // 搜索条件的解析错误
 	showFavorites     bool
+	tagMode           bool // 按标签而不是分组浏览
 	statusCheckMode   bool
 	config            *config.Config
 	currentTheme      *theme.Theme
@@ -39,6 +51,12 @@ type Menu struct {
 	loadingStates    map[string]bool   // 加载状态
 	lastFrame        time.Time         // 上一帧时间
 	needsRedraw      bool              // 是否需要重绘
+
+	tunnels       []tunnel.State // 运行中的端口转发隧道（hostmanager tunnel）
+	tunnelEntries []tunnel.Entry // 隧道面板列出的隧道（包括已退出的后台隧道）
+	showTunnels   bool           // 显示隧道面板
+
+	browser *fileBrowser // 打开的文件浏览器
 }
 
 // Toast通知管理器
@@ -64,6 +82,9 @@ type AnimationManager struct {
 	running    bool
 }
 
+// 隧道状态的刷新间隔
+const tunnelRefreshInterval = 2 * time.Second
+
 // 创建新的菜单实例（增强版）
 func NewMenu(cfg *config.Config) *Menu {
 	menu := &Menu{
@@ -82,6 +103,9 @@ func NewMenu(cfg *config.Config) *Menu {
 		needsRedraw:       true,
 	}
 
+	// 根据连接历史初始化快速连接列表
+	menu.refreshFrecency()
+
 	// 初始化主题和国际化
 	menu.currentTheme = cfg.UIConfig.Themes.GetTheme(cfg.UIConfig.Theme)
 	menu.texts = i18n.GetTexts(cfg.UIConfig.Language)
@@ -97,6 +121,8 @@ func NewMenu(cfg *config.Config) *Menu {
 		running:    false,
 	}
 
+	menu.refreshTunnels()
+	menu.showDiagnostics()
 	return menu
 }
 
@@ -106,6 +132,10 @@ func (m *Menu) Run() {
 	m.startAnimationManager()
 	defer m.stopAnimationManager()
 
+	// 定期唤醒事件循环以刷新隧道状态
+	stopTunnelWatch := m.watchTunnels()
+	defer stopTunnelWatch()
+
 	for {
 		currentTime := time.Now()
 
@@ -151,6 +181,23 @@ func (m *Menu) showToast(message, toastType string, duration time.Duration) {
 	m.needsRedraw = true
 }
 
+// 配置存在问题时提示，详细信息通过 hostmanager config lint 查看
+func (m *Menu) showDiagnostics() {
+	diagnostics := m.config.Validate()
+	if len(diagnostics) == 0 {
+		return
+	}
+	toastType := "warning"
+	if config.HasErrors(diagnostics) {
+		toastType = "error"
+	}
+	message := diagnostics[0].Message
+	if len(diagnostics) > 1 {
+		message = fmt.Sprintf("%s 等 %d 个配置问题", message, len(diagnostics))
+	}
+	m.showToast(message+"，运行 hostmanager config lint 查看", toastType, 6*time.Second)
+}
+
 // 更新Toast状态
 func (m *Menu) updateToasts(currentTime time.Time) {
 	activeToasts := []Toast{}
@@ -185,6 +232,80 @@ func (m *Menu) startAnimationManager() {
 	}()
 }
 
+// 每隔一段时间中断 PollEvent，由 handleInput 刷新隧道状态
+func (m *Menu) watchTunnels() func() {
+	ticker := time.NewTicker(tunnelRefreshInterval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				termbox.Interrupt()
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// 重新读取隧道状态，有变化时返回 true
+func (m *Menu) refreshTunnels() bool {
+	entries, err := tunnel.List(tunnel.Dir())
+	if err != nil {
+		return false
+	}
+	changed := len(entries) != len(m.tunnelEntries)
+	for i := 0; !changed && i < len(entries); i++ {
+		changed = tunnelEntryKey(entries[i]) != tunnelEntryKey(m.tunnelEntries[i])
+	}
+
+	m.tunnelEntries = entries
+	m.tunnels = nil
+	for _, entry := range entries {
+		if entry.State != nil {
+			m.tunnels = append(m.tunnels, *entry.State)
+		}
+	}
+	return changed
+}
+
+// 隧道中会影响显示的内容
+func tunnelEntryKey(entry tunnel.Entry) string {
+	key := fmt.Sprintf("%s|%d|%s", entry.Name, entry.PID, entry.Status)
+	if entry.State != nil {
+		key += fmt.Sprintf("|%d|%v", entry.State.Restarts, entry.State.Forwards)
+	}
+	return key
+}
+
+// 主机上运行中的隧道
+func (m *Menu) hostTunnels(host models.Host) []tunnel.State {
+	var states []tunnel.State
+	for _, state := range m.tunnels {
+		if strings.EqualFold(state.Host, host.Name) {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// 主机列表中的隧道标记，例如 "🔀2" 表示隧道当前有 2 个连接
+func (m *Menu) tunnelIcon(host models.Host) string {
+	states := m.hostTunnels(host)
+	if len(states) == 0 {
+		return ""
+	}
+	var active int64
+	for _, state := range states {
+		active += state.Active()
+	}
+	return fmt.Sprintf("🔀%d", active)
+}
+
 // 停止动画管理器
 func (m *Menu) stopAnimationManager() {
 	m.animationManager.running = false
@@ -201,41 +322,78 @@ func (m *Menu) updateAnimations() {
 
 // 过滤主机基于搜索查询
 func (m *Menu) filterHosts() {
+	m.searchMatches = map[string]fuzzy.HostMatch{}
+	m.searchError = nil
 	if m.searchQuery == "" {
-		m.filteredGroups = m.groups
+		m.filteredGroups = m.browseGroups(m.groups)
 		return
 	}
 
 	m.filteredGroups = []models.Group{}
+	q, err := query.Parse(m.searchQuery)
+	if err != nil {
+		m.searchError = err
+		return
+	}
 	for _, group := range m.groups {
 		filteredGroup := models.Group{Name: group.Name, Hosts: []models.Host{}}
 		for _, host := range group.Hosts {
-			if containsIgnoreCase(host.Name, m.searchQuery) ||
-				containsIgnoreCase(host.IP, m.searchQuery) ||
-				containsIgnoreCase(host.Username, m.searchQuery) {
+			if match, ok := q.Match(group.Name, host); ok {
+				m.searchMatches[searchMatchKey(host)] = match
 				filteredGroup.Hosts = append(filteredGroup.Hosts, host)
 			}
 		}
 		if len(filteredGroup.Hosts) > 0 {
+			sort.SliceStable(filteredGroup.Hosts, func(i, j int) bool {
+				return m.searchRankLess(filteredGroup.Hosts[i], filteredGroup.Hosts[j])
+			})
 			m.filteredGroups = append(m.filteredGroups, filteredGroup)
 		}
 	}
+
+	m.filteredGroups = m.browseGroups(m.filteredGroups)
+
+	// 最佳匹配所在分组排在前面
+	sort.SliceStable(m.filteredGroups, func(i, j int) bool {
+		return m.searchRankLess(m.filteredGroups[i].Hosts[0], m.filteredGroups[j].Hosts[0])
+	})
 }
 
-// 字符串包含检查（忽略大小写）
-func containsIgnoreCase(str, substr string) bool {
-	return len(str) >= len(substr) &&
-		len(substr) > 0 &&
-		findSubstring(strings.ToLower(str), strings.ToLower(substr))
+// 标签模式下把主机按标签重新分组，否则保持原有分组
+func (m *Menu) browseGroups(groups []models.Group) []models.Group {
+	if !m.tagMode {
+		return groups
+	}
+	return models.GroupByTag(groups)
 }
 
-func findSubstring(str, substr string) bool {
-	for i := 0; i <= len(str)-len(substr); i++ {
-		if str[i:i+len(substr)] == substr {
-			return true
-		}
+// 切换分组/标签浏览模式
+func (m *Menu) toggleTagMode() {
+	m.tagMode = !m.tagMode
+	m.showFavorites = false
+	m.inGroup = false
+	m.currentGroup = 0
+	m.currentHost = 0
+	m.filterHosts()
+}
+
+// 搜索结果排序：匹配评分优先，评分相同时按使用频率
+func (m *Menu) searchRankLess(a, b models.Host) bool {
+	scoreA, scoreB := m.searchMatches[searchMatchKey(a)].Score, m.searchMatches[searchMatchKey(b)].Score
+	if scoreA != scoreB {
+		return scoreA > scoreB
 	}
-	return false
+	return m.frecency.Score(a) > m.frecency.Score(b)
+}
+
+// 主机的匹配位置（用于高亮），未处于搜索状态时为空
+func (m *Menu) matchPositions(host models.Host, field string) []int {
+	return m.searchMatches[searchMatchKey(host)].Positions[field]
+}
+
+// 搜索结果的索引键
+func searchMatchKey(host models.Host) string {
+	return host.Name + "|" + history.Key(host)
 }
 
 // 获取收藏的主机列表
@@ -255,25 +413,54 @@ func (m *Menu) getFavoriteHosts() []models.Host {
 func (m *Menu) toggleFavorite() {
 	if m.inGroup && m.currentGroup < len(m.filteredGroups) && m.currentHost < len(m.filteredGroups[m.currentGroup].Hosts) {
 		targetHost := m.filteredGroups[m.currentGroup].Hosts[m.currentHost]
-		for i := range m.groups {
-			for j := range m.groups[i].Hosts {
-				if m.groups[i].Hosts[j].IP == targetHost.IP &&
-					m.groups[i].Hosts[j].Port == targetHost.Port &&
-					m.groups[i].Hosts[j].Username == targetHost.Username {
-					m.groups[i].Hosts[j].Favorite = !m.groups[i].Hosts[j].Favorite
-					m.saveConfig()
-					m.filterHosts()
-					return
+		favorite := !targetHost.Favorite
+		m.updateConfig(func(cfg *config.Config) {
+			for i := range cfg.Groups {
+				for j := range cfg.Groups[i].Hosts {
+					host := &cfg.Groups[i].Hosts[j]
+					// 同名主机按来源文件区分，引入文件中的主机收藏状态写入主配置文件
+					if host.Source == targetHost.Source && strings.EqualFold(host.Name, targetHost.Name) {
+						host.Favorite = favorite
+						return
+					}
 				}
 			}
-		}
+		})
 	}
 }
 
-// 保存配置到文件
-func (m *Menu) saveConfig() {
-	m.config.Groups = m.groups
-	m.config.Save("config.yaml")
+// 在文件锁内基于磁盘上的最新配置应用修改并保存，避免覆盖其他进程（如命令行）的修改
+func (m *Menu) updateConfig(modify func(cfg *config.Config)) {
+	newConfig, err := config.Update(m.config.Path(), func(cfg *config.Config) error {
+		modify(cfg)
+		return nil
+	})
+	if err != nil {
+		m.showToast("保存配置失败: "+err.Error(), "error", 3*time.Second)
+		return
+	}
+	for _, path := range newConfig.Reformatted() {
+		m.showToast(config.ReformatWarning(path), "warning", 5*time.Second)
+	}
+
+	// 保留已检查的主机状态
+	statuses := map[string]string{}
+	for _, group := range m.groups {
+		for _, host := range group.Hosts {
+			statuses[searchMatchKey(host)] = host.Status
+		}
+	}
+	for i := range newConfig.Groups {
+		for j := range newConfig.Groups[i].Hosts {
+			host := &newConfig.Groups[i].Hosts[j]
+			host.Status = statuses[searchMatchKey(*host)]
+		}
+	}
+
+	m.config = newConfig
+	m.groups = newConfig.Groups
+	m.connectionHistory = m.frecency.Top(m.groups, maxRecentHosts)
+	m.filterHosts()
 }
 
 // 批量检查所有主机状态
@@ -295,64 +482,64 @@ func (m *Menu) checkAllHostsStatus() {
 	}
 }
 
-// 添加主机到连接历史
-func (m *Menu) addToHistory(host models.Host) {
-	// 检查是否已在历史中
-	for i, h := range m.connectionHistory {
-		if h.IP == host.IP && h.Port == host.Port && h.Username == host.Username {
-			// 移到最前面
-			m.connectionHistory = append([]models.Host{host}, append(m.connectionHistory[:i], m.connectionHistory[i+1:]...)...)
-			return
-		}
-	}
-
-	// 添加到历史前面，保持最多5个
-	m.connectionHistory = append([]models.Host{host}, m.connectionHistory...)
-	if len(m.connectionHistory) > 5 {
-		m.connectionHistory = m.connectionHistory[:5]
-	}
+// 从历史文件重新计算使用频率，刷新快速连接列表（已从配置中删除的主机不再显示）
+func (m *Menu) refreshFrecency() {
+	m.frecency = history.LoadScores(history.DefaultPath())
+	m.connectionHistory = m.frecency.Top(m.groups, maxRecentHosts)
 }
 
 // 切换主题
 func (m *Menu) toggleTheme() {
+	newTheme := "light"
 	if m.config.UIConfig.Theme == "light" {
-		m.config.UIConfig.Theme = "dark"
-	} else {
-		m.config.UIConfig.Theme = "light"
+		newTheme = "dark"
 	}
+	m.updateConfig(func(cfg *config.Config) {
+		cfg.UIConfig.Theme = newTheme
+	})
 	m.currentTheme = m.config.UIConfig.Themes.GetTheme(m.config.UIConfig.Theme)
-	m.saveConfig()
 }
 
 // 切换布局
 func (m *Menu) toggleLayout() {
+	newLayout := "single"
 	if m.config.UIConfig.Layout.Type == "single" {
-		m.config.UIConfig.Layout.Type = "columns"
-	} else {
-		m.config.UIConfig.Layout.Type = "single"
+		newLayout = "columns"
 	}
-	m.saveConfig()
+	m.updateConfig(func(cfg *config.Config) {
+		cfg.UIConfig.Layout.Type = newLayout
+	})
 }
 
 // 重新加载配置
 func (m *Menu) reloadConfig() {
-	newConfig, err := config.LoadConfig("config.yaml")
+	newConfig, err := config.LoadConfig(m.config.Path())
 	if err != nil {
 		return
 	}
 	m.config = newConfig
 	m.groups = newConfig.Groups
+	m.connectionHistory = m.frecency.Top(m.groups, maxRecentHosts)
 	m.currentTheme = m.config.UIConfig.Themes.GetTheme(m.config.UIConfig.Theme)
 	m.texts = i18n.GetTexts(m.config.UIConfig.Language)
 	m.filterHosts()
 	m.currentGroup = 0
 	m.currentHost = 0
 	m.inGroup = false
+	m.showDiagnostics()
 }
 
 // 连接SSH（包装函数）
 func (m *Menu) connectSSH(host models.Host) {
-	ssh.Connect(host, m.addToHistory)
+	start := time.Now()
+	err := ssh.Connect(host, nil)
+
+	// 持久化连接历史
+	entry := history.NewEntry(host, history.SourceTUI, start, ssh.ExitStatus(err))
+	if histErr := history.Append(history.DefaultPath(), entry); histErr != nil {
+		fmt.Printf("⚠️  保存连接历史失败: %v\n", histErr)
+	}
+	m.refreshFrecency()
 
 	// 连接断开后的恢复处理
 	m.recoverFromSSHDisconnect()
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/daihao4371/hostmanager/internal/transfer"
+)
+
+// 传输任务状态
+const (
+	taskPending = iota // 等待中
+	taskRunning        // 传输中
+	taskDone           // 已完成
+	taskFailed         // 失败
+)
+
+// 传输中刷新界面的间隔
+const queueRefreshInterval = 250 * time.Millisecond
+
+// 传输队列中的一个任务：把一个文件或目录复制到另一侧的目录
+type transferTask struct {
+	label    string
+	source   transfer.Endpoint
+	dest     transfer.Endpoint
+	status   int
+	err      error
+	progress *transfer.Progress // 开始传输后才有
+}
+
+// 任务状态图标
+func (t transferTask) icon() string {
+	switch t.status {
+	case taskRunning:
+		return "🔄"
+	case taskDone:
+		return "✅"
+	case taskFailed:
+		return "❌"
+	}
+	return "⏳"
+}
+
+// 传输队列：在后台依次执行任务，界面通过 snapshot 读取状态
+type transferQueue struct {
+	mu      sync.Mutex
+	tasks   []*transferTask
+	running bool
+	changed bool   // 有任务结束，需要刷新目录
+	closed  bool   // 浏览器已关闭，不再开始新任务
+	notify  func() // 状态变化时唤醒界面
+}
+
+func newTransferQueue(notify func()) *transferQueue {
+	return &transferQueue{notify: notify}
+}
+
+// 添加任务，空闲时启动后台传输
+func (q *transferQueue) add(task *transferTask) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.tasks = append(q.tasks, task)
+	if !q.running {
+		q.running = true
+		go q.run()
+	}
+}
+
+func (q *transferQueue) run() {
+	for {
+		q.mu.Lock()
+		task := q.next()
+		if task == nil {
+			q.running = false
+			q.mu.Unlock()
+			q.notify()
+			return
+		}
+		task.status = taskRunning
+		q.mu.Unlock()
+
+		err := q.execute(task)
+
+		q.mu.Lock()
+		task.err = err
+		task.status = taskDone
+		if err != nil {
+			task.status = taskFailed
+		}
+		q.changed = true
+		q.mu.Unlock()
+		q.notify()
+	}
+}
+
+// 下一个等待中的任务，队列关闭后不再返回
+func (q *transferQueue) next() *transferTask {
+	if q.closed {
+		return nil
+	}
+	for _, task := range q.tasks {
+		if task.status == taskPending {
+			return task
+		}
+	}
+	return nil
+}
+
+// 执行任务，传输过程中定期唤醒界面刷新进度
+func (q *transferQueue) execute(task *transferTask) error {
+	jobs, err := transfer.Plan([]transfer.Endpoint{task.source}, task.dest, true)
+	if err != nil {
+		return err
+	}
+	files := 0
+	for _, job := range jobs {
+		if !job.Dir {
+			files++
+		}
+	}
+	progress := transfer.NewProgress(nil, false, files, transfer.TotalSize(jobs))
+	q.mu.Lock()
+	task.progress = progress
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(queueRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				q.notify()
+			}
+		}
+	}()
+
+	copier := transfer.Copier{Progress: progress}
+	if _, errs := copier.Run(jobs); len(errs) > 0 {
+		if len(errs) > 1 {
+			return fmt.Errorf("%v 等 %d 个错误", errs[0], len(errs))
+		}
+		return errs[0]
+	}
+	return nil
+}
+
+// 任务状态的副本
+func (q *transferQueue) snapshot() []transferTask {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	tasks := make([]transferTask, len(q.tasks))
+	for i, task := range q.tasks {
+		tasks[i] = *task
+	}
+	return tasks
+}
+
+// 是否有任务正在传输或等待
+func (q *transferQueue) busy() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.running
+}
+
+// 是否有任务在上次调用后结束
+func (q *transferQueue) takeChanged() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	changed := q.changed
+	q.changed = false
+	return changed
+}
+
+// 关闭队列，等待中的任务不再执行
+func (q *transferQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nsf/termbox-go"
+
+	"github.com/daihao4371/hostmanager/internal/tunnel"
+)
+
+// 面板中的一行文本
+type panelLine struct {
+	text  string
+	color termbox.Attribute
+}
+
+// 隧道面板内容：每个隧道一行状态，下面列出各转发的连接数和健康检查结果
+func (m *Menu) tunnelPanelLines() []panelLine {
+	lines := []panelLine{{"🔀 隧道 (p/ESC 返回):", m.currentTheme.Success}}
+	if len(m.tunnelEntries) == 0 {
+		lines = append(lines, panelLine{"   暂无隧道，使用 hostmanager tunnel start <主机> 在后台启动", m.currentTheme.Border})
+		return lines
+	}
+
+	for _, entry := range m.tunnelEntries {
+		name := entry.Name
+		if name == "" {
+			name = "(前台)"
+		}
+		text := fmt.Sprintf("   %s %s", entry.Icon(), name)
+		if entry.State != nil {
+			text += " → " + entry.State.Host
+		}
+		text += " | " + tunnel.StatusName(entry.Status)
+		if entry.Running() {
+			text += fmt.Sprintf(" | PID %d", entry.PID)
+		}
+		if state := entry.State; state != nil {
+			text += fmt.Sprintf(" | 已运行 %s", time.Since(state.Started).Round(time.Second))
+			if state.Restarts > 0 {
+				text += fmt.Sprintf(" | 重连 %d 次", state.Restarts)
+			}
+		}
+		lines = append(lines, panelLine{text, m.tunnelColor(entry)})
+
+		if entry.State == nil {
+			if entry.Status == tunnel.StatusExited {
+				lines = append(lines, panelLine{"      查看日志: hostmanager tunnel logs " + entry.Name, m.currentTheme.Border})
+			}
+			continue
+		}
+		for _, forward := range entry.State.Forwards {
+			icon, color := "⏳", m.currentTheme.Border
+			switch forward.Health {
+			case "":
+			case tunnel.HealthOK:
+				icon, color = "✅", m.currentTheme.Foreground
+			default:
+				icon, color = "⚠️", m.currentTheme.Warning
+			}
+			lines = append(lines, panelLine{fmt.Sprintf("      %s %s  %d 个连接 (共 %d)", icon, forward.Spec, forward.Active, forward.Total), color})
+			if forward.Health != "" && forward.Health != tunnel.HealthOK {
+				lines = append(lines, panelLine{"         " + forward.Health, m.currentTheme.Warning})
+			}
+		}
+	}
+	return lines
+}
+
+// 隧道状态的颜色
+func (m *Menu) tunnelColor(entry tunnel.Entry) termbox.Attribute {
+	switch {
+	case entry.Status == tunnel.StatusExited:
+		return m.currentTheme.Error
+	case entry.Status == tunnel.StatusConnected && !entry.State.Unhealthy():
+		return m.currentTheme.Success
+	}
+	return m.currentTheme.Warning
+}
+
+// 在指定区域绘制隧道面板，超出高度的行不显示
+func (m *Menu) drawTunnelPanel(x, y, width, height int) {
+	for _, line := range m.tunnelPanelLines() {
+		if y >= height {
+			break
+		}
+		m.printThemedStringInBounds(x, y, line.text, line.color, width)
+		y++
+	}
+}
@@ -1,10 +1,16 @@
 package ui
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/daihao4371/hostmanager/internal/models"
 	"github.com/daihao4371/hostmanager/internal/theme"
+	"github.com/daihao4371/hostmanager/internal/transfer"
+	"github.com/daihao4371/hostmanager/internal/tunnel"
 	"github.com/nsf/termbox-go"
 )
 
@@ -76,7 +82,6 @@ func TestStatusBadge(t *testing.T) {
 func TestProgressBar(t *testing.T) {
 	testTheme := createTestTheme()
 	renderer := NewRenderEngine(testTheme)
-	style := CreatePremiumStyle(testTheme)
 
 	// 测试不同进度值
 	progressValues := []float32{0.0, 0.25, 0.5, 0.75, 1.0}
@@ -250,3 +255,232 @@ func TestUIIntegration(t *testing.T) {
 
 	t.Log("UI集成测试完成 - 所有组件渲染正常")
 }
+
+// 测试模糊搜索过滤和高亮位置
+func TestFilterHostsFuzzy(t *testing.T) {
+	menu := &Menu{
+		groups: []models.Group{
+			{Name: "开发", Hosts: []models.Host{{Name: "dev-box", IP: "10.0.0.9", Username: "dev"}}},
+			{Name: "生产", Hosts: []models.Host{
+				{Name: "db-backup", IP: "10.0.1.2", Username: "root", Description: "web 备份"},
+				{Name: "web-01", IP: "10.0.1.1", Username: "root", Tags: []string{"nginx"}},
+			}},
+		},
+		searchQuery: "web",
+	}
+	menu.filterHosts()
+
+	if len(menu.filteredGroups) != 1 || menu.filteredGroups[0].Hosts[0].Name != "web-01" {
+		t.Fatalf("过滤结果错误: %+v", menu.filteredGroups)
+	}
+	if positions := menu.matchPositions(menu.filteredGroups[0].Hosts[0], "name"); len(positions) != 3 {
+		t.Errorf("名称高亮位置错误: %v", positions)
+	}
+
+	// 按标签搜索
+	menu.searchQuery = "nginx"
+	menu.filterHosts()
+	if len(menu.filteredGroups) != 1 || len(menu.filteredGroups[0].Hosts) != 1 {
+		t.Errorf("标签搜索结果错误: %+v", menu.filteredGroups)
+	}
+}
+
+// 测试高亮行的位置偏移
+func TestHighlightedLine(t *testing.T) {
+	var line highlightedLine
+	line.add("🔹 ")
+	line.addMatched("web", []int{0, 2})
+	if line.String() != "🔹 web" || !line.marks[2] || !line.marks[4] || line.marks[3] {
+		t.Errorf("高亮位置错误: %q %v", line.String(), line.marks)
+	}
+}
+
+// 测试标签浏览模式
+func TestTagMode(t *testing.T) {
+	menu := &Menu{
+		groups: []models.Group{
+			{Name: "开发", Hosts: []models.Host{{Name: "dev-web", Tags: []string{"web"}}}},
+			{Name: "生产", Hosts: []models.Host{
+				{Name: "prod-web", Tags: []string{"web", "nginx"}},
+				{Name: "prod-db", Tags: []string{"db"}},
+			}},
+		},
+	}
+	menu.toggleTagMode()
+
+	if len(menu.filteredGroups) != 3 {
+		t.Fatalf("标签数量错误: %+v", menu.filteredGroups)
+	}
+	web := menu.filteredGroups[2]
+	if web.Name != "web" || len(web.Hosts) != 2 {
+		t.Errorf("web 标签应包含两个环境的主机: %+v", web)
+	}
+
+	// 标签模式下搜索
+	menu.searchQuery = "group:生产"
+	menu.filterHosts()
+	for _, group := range menu.filteredGroups {
+		if group.Name == "web" && len(group.Hosts) != 1 {
+			t.Errorf("搜索后 web 标签应只包含生产主机: %+v", group)
+		}
+	}
+
+	menu.searchQuery = ""
+	menu.toggleTagMode()
+	if len(menu.filteredGroups) != 2 || menu.filteredGroups[0].Name != "开发" {
+		t.Errorf("退出标签模式后应恢复分组: %+v", menu.filteredGroups)
+	}
+}
+
+// 测试隧道面板内容
+func TestTunnelPanel(t *testing.T) {
+	menu := &Menu{currentTheme: createTestTheme()}
+	menu.tunnelEntries = []tunnel.Entry{
+		{Name: "pg", PID: 100, Status: tunnel.StatusConnected, State: &tunnel.State{
+			Host: "db", Started: time.Now(), Restarts: 2,
+			Forwards: []tunnel.Forward{{Spec: "pg: L 127.0.0.1:5432 → db:5432", Active: 1, Total: 3, Health: tunnel.HealthOK}},
+		}},
+		{Name: "web", PID: 101, Status: tunnel.StatusExited},
+	}
+
+	lines := menu.tunnelPanelLines()
+	if len(lines) != 5 {
+		t.Fatalf("面板行数错误: %+v", lines)
+	}
+	if !strings.Contains(lines[1].text, "pg → db") || !strings.Contains(lines[1].text, "重连 2 次") || lines[1].color != termbox.ColorGreen {
+		t.Errorf("运行中的隧道显示错误: %+v", lines[1])
+	}
+	if !strings.Contains(lines[2].text, "✅") || !strings.Contains(lines[2].text, "1 个连接 (共 3)") {
+		t.Errorf("转发显示错误: %+v", lines[2])
+	}
+	if lines[3].color != termbox.ColorRed || !strings.Contains(lines[4].text, "tunnel logs web") {
+		t.Errorf("已退出的隧道显示错误: %+v", lines[3:])
+	}
+
+	// 未通过健康检查时显示为警告
+	menu.tunnelEntries[0].State.Forwards[0].Health = "目标不可达"
+	lines = menu.tunnelPanelLines()
+	if lines[1].color != termbox.ColorYellow || lines[3].text != "         目标不可达" {
+		t.Errorf("健康检查失败显示错误: %+v", lines)
+	}
+}
+
+// 测试分栏布局
+func TestSplitColumns(t *testing.T) {
+	left, right := splitColumns(Rect{X: 0, Y: 1, Width: 81, Height: 20})
+	if left.Width != 40 || right.X != 41 || right.Width != 40 || right.Y != 1 || right.Height != 20 {
+		t.Errorf("分栏错误: %+v %+v", left, right)
+	}
+}
+
+// 测试文件浏览器：目录导航、新建、重命名、删除和传输队列（两侧均使用本地目录）
+func TestFileBrowser(t *testing.T) {
+	localDir, remoteDir := t.TempDir(), t.TempDir()
+	for _, name := range []string{"b.txt", "A.txt", "sub/c.txt"} {
+		path := filepath.Join(localDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	browser, err := newFileBrowser(models.Host{Name: "web1"}, transfer.Local{}, transfer.Local{}, localDir, remoteDir, nil, func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pane := browser.pane()
+	var names []string
+	for _, entry := range pane.entries {
+		names = append(names, entry.name)
+	}
+	if strings.Join(names, ",") != "..,sub,A.txt,b.txt" {
+		t.Fatalf("目录应排在前面并按名称排序: %v", names)
+	}
+
+	// 进入子目录后返回，光标停在子目录上
+	pane.move(1)
+	if err := pane.enter(); err != nil || pane.dir != filepath.Join(localDir, "sub") {
+		t.Fatalf("进入目录失败: %v %s", err, pane.dir)
+	}
+	if err := pane.up(); err != nil || pane.dir != localDir {
+		t.Fatalf("返回上级目录失败: %v %s", err, pane.dir)
+	}
+	if entry, _ := pane.selected(); entry.name != "sub" {
+		t.Errorf("返回后光标应在 sub 上: %+v", entry)
+	}
+
+	// 新建目录、重命名
+	if err := browser.mkdir("logs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := browser.mkdir("logs"); err == nil {
+		t.Error("目录已存在时应报错")
+	}
+	if err := browser.mkdir("a/b"); err == nil {
+		t.Error("名称包含 / 时应报错")
+	}
+	if err := browser.rename("logs", "log"); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := pane.selected(); entry.name != "log" {
+		t.Errorf("重命名后光标应在新名称上: %+v", entry)
+	}
+	if err := browser.rename("log", "b.txt"); err == nil {
+		t.Error("目标已存在时不应覆盖")
+	}
+	if err := browser.remove([]browserEntry{{name: "log", dir: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(localDir, "log")); !os.IsNotExist(err) {
+		t.Errorf("删除失败: %v", err)
+	}
+
+	// 标记两个文件和一个目录复制到另一侧
+	pane.focus("sub")
+	pane.toggleMark()
+	pane.toggleMark()
+	pane.toggleMark()
+	if len(pane.targets()) != 3 {
+		t.Fatalf("应标记 3 项: %v", pane.marked)
+	}
+	if n := browser.copySelected(); n != 3 || len(pane.marked) != 0 {
+		t.Fatalf("加入队列的任务数错误: %d", n)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for browser.queue.busy() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	tasks := browser.queue.snapshot()
+	for _, task := range tasks {
+		if task.status != taskDone {
+			t.Errorf("任务未完成: %s %v", task.label, task.err)
+		}
+	}
+	if !strings.HasPrefix(tasks[0].label, "⬆") {
+		t.Errorf("本地到远端应显示为上传: %s", tasks[0].label)
+	}
+	if !browser.refresh() || len(browser.other().entries) != 4 {
+		t.Errorf("传输完成后应刷新另一侧: %+v", browser.other().entries)
+	}
+	if data, err := os.ReadFile(filepath.Join(remoteDir, "sub", "c.txt")); err != nil || string(data) != "sub/c.txt" {
+		t.Errorf("目录复制失败: %q %v", data, err)
+	}
+	if transferQueueHeight(tasks) != 4 || len(visibleTasks(tasks)) != 3 {
+		t.Errorf("队列显示错误: %d", transferQueueHeight(tasks))
+	}
+}
+
+// 测试传输队列优先显示未完成的任务
+func TestVisibleTasks(t *testing.T) {
+	tasks := []transferTask{{label: "1", status: taskDone}, {label: "2", status: taskRunning}, {label: "3"}, {label: "4"}, {label: "5"}}
+	visible := visibleTasks(tasks)
+	if len(visible) != 3 || visible[0].label != "2" {
+		t.Errorf("应从正在传输的任务开始显示: %+v", visible)
+	}
+	if transferQueueHeight(tasks) != 7 {
+		t.Errorf("传输中队列高度错误: %d", transferQueueHeight(tasks))
+	}
+	if transferQueueHeight(nil) != 0 {
+		t.Error("空队列不应占用空间")
+	}
+}
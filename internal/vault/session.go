@@ -0,0 +1,108 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// 会话内缓存的已解锁保险库，进程内只需输入一次口令
+var session struct {
+	mu    sync.Mutex
+	vault *Vault
+}
+
+// 保险库未解锁且不允许提示输入口令
+var ErrLocked = errors.New("保险库未解锁")
+
+// 解锁默认保险库，优先使用 HOSTMANAGER_VAULT_PASSPHRASE，否则在终端提示输入
+func Unlock() (*Vault, error) {
+	return unlock(true)
+}
+
+func unlock(interactive bool) (*Vault, error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.vault != nil {
+		return session.vault, nil
+	}
+
+	path := DefaultPath()
+	if !Exists(path) {
+		return nil, fmt.Errorf("保险库未初始化，请先运行 'hostmanager vault init'")
+	}
+
+	passphrase := os.Getenv("HOSTMANAGER_VAULT_PASSPHRASE")
+	if passphrase == "" {
+		if !interactive {
+			return nil, ErrLocked
+		}
+		var err error
+		passphrase, err = PromptPassphrase("🔒 保险库口令: ")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	v, err := Open(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	session.vault = v
+	return v, nil
+}
+
+// 缓存已解锁的保险库（初始化或更换口令后调用）
+func Remember(v *Vault) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.vault = v
+}
+
+// 清除缓存的保险库
+func Lock() {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.vault = nil
+}
+
+// 通过条目ID读取密码
+func Lookup(id string) (string, error) {
+	return lookup(id, true)
+}
+
+// 通过条目ID读取密码，保险库未解锁时不提示输入口令（用于后台任务）
+func LookupCached(id string) (string, error) {
+	return lookup(id, false)
+}
+
+func lookup(id string, interactive bool) (string, error) {
+	v, err := unlock(interactive)
+	if err != nil {
+		return "", err
+	}
+	secret, err := v.Get(id)
+	if err != nil {
+		return "", fmt.Errorf("读取保险库条目 %s 失败: %v", id, err)
+	}
+	return secret, nil
+}
+
+// 在终端提示输入口令（不回显）
+func PromptPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("无法读取口令: 标准输入不是终端")
+	}
+	fmt.Print(prompt)
+	secret, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
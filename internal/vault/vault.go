@@ -0,0 +1,351 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v2"
+
+	"github.com/daihao4371/hostmanager/internal/fsutil"
+)
+
+// scrypt 参数（交互式场景推荐值）
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	keyLength    = 32
+	saltLength   = 16
+	checkContent = "hostmanager-vault"
+	// 版本 2 起条目ID作为 AES-GCM 的附加数据，密文不能在条目之间互换
+	currentVersion = 2
+)
+
+var (
+	ErrWrongPassphrase = errors.New("保险库口令错误")
+	ErrNotFound        = errors.New("保险库中不存在该条目")
+	ErrTampered        = errors.New("保险库条目已损坏或被篡改")
+)
+
+// 保险库文件结构
+type vaultFile struct {
+	Version int               `yaml:"version"`
+	KDF     kdfParams         `yaml:"kdf"`
+	Check   string            `yaml:"check"`   // 加密的校验串，用于验证口令
+	Entries map[string]string `yaml:"entries"` // 条目ID -> 密文(base64)
+}
+
+// 密钥派生参数
+type kdfParams struct {
+	Name string `yaml:"name"`
+	Salt string `yaml:"salt"`
+	N    int    `yaml:"n"`
+	R    int    `yaml:"r"`
+	P    int    `yaml:"p"`
+}
+
+// 已解锁的密码保险库
+type Vault struct {
+	path string
+	file vaultFile
+	key  []byte
+}
+
+// 获取保险库默认路径，可通过 HOSTMANAGER_VAULT 覆盖
+func DefaultPath() string {
+	if path := os.Getenv("HOSTMANAGER_VAULT"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "vault.yaml"
+	}
+	return filepath.Join(home, ".hostmanager", "vault.yaml")
+}
+
+// 检查保险库文件是否存在
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// 创建新的保险库
+func Create(path, passphrase string) (*Vault, error) {
+	if Exists(path) {
+		return nil, fmt.Errorf("保险库已存在: %s", path)
+	}
+
+	v := &Vault{
+		path: path,
+		file: vaultFile{Version: currentVersion, Entries: map[string]string{}},
+	}
+	if err := v.setPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	return v, v.Save()
+}
+
+// 使用口令打开保险库
+func Open(path, passphrase string) (*Vault, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file vaultFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析保险库失败: %v", err)
+	}
+	if file.Entries == nil {
+		file.Entries = map[string]string{}
+	}
+
+	v := &Vault{path: path, file: file}
+	v.key, err = deriveKey(passphrase, file.KDF)
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := v.decrypt(file.Check, nil)
+	if err != nil || check != checkContent {
+		return nil, ErrWrongPassphrase
+	}
+	if file.Version < currentVersion {
+		if err := v.upgrade(); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// 旧版本的条目没有附加数据，解锁后在内存中重新加密，下次保存时写入新格式
+func (v *Vault) upgrade() error {
+	for id, sealed := range v.file.Entries {
+		secret, err := v.decrypt(sealed, nil)
+		if err != nil {
+			return fmt.Errorf("读取保险库条目 %s 失败: %w", id, ErrTampered)
+		}
+		if err := v.Set(id, secret); err != nil {
+			return err
+		}
+	}
+	v.file.Version = currentVersion
+	return nil
+}
+
+// 读取条目
+func (v *Vault) Get(id string) (string, error) {
+	sealed, ok := v.file.Entries[id]
+	if !ok {
+		return "", ErrNotFound
+	}
+	secret, err := v.decrypt(sealed, []byte(id))
+	if err != nil {
+		return "", ErrTampered
+	}
+	return secret, nil
+}
+
+// 写入条目（需调用 Save 持久化）
+func (v *Vault) Set(id, secret string) error {
+	sealed, err := v.encrypt(secret, []byte(id))
+	if err != nil {
+		return err
+	}
+	v.file.Entries[id] = sealed
+	return nil
+}
+
+// 删除条目（需调用 Save 持久化）
+func (v *Vault) Delete(id string) error {
+	if _, ok := v.file.Entries[id]; !ok {
+		return ErrNotFound
+	}
+	delete(v.file.Entries, id)
+	return nil
+}
+
+// 从默认保险库删除条目，不需要口令（删除主机后清理密码）；
+// 已解锁的保险库同步删除，避免之后保存时写回
+func Remove(ids ...string) error {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.vault != nil {
+		for _, id := range ids {
+			delete(session.vault.file.Entries, id)
+		}
+	}
+
+	path := DefaultPath()
+	if len(ids) == 0 || !Exists(path) {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var file vaultFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("解析保险库失败: %v", err)
+	}
+	removed := false
+	for _, id := range ids {
+		if _, ok := file.Entries[id]; ok {
+			delete(file.Entries, id)
+			removed = true
+		}
+	}
+	if !removed {
+		return nil
+	}
+	v := &Vault{path: path, file: file}
+	return v.Save()
+}
+
+// 列出所有条目ID
+func (v *Vault) IDs() []string {
+	ids := make([]string, 0, len(v.file.Entries))
+	for id := range v.file.Entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// 更换口令并重新加密所有条目
+func (v *Vault) Rekey(newPassphrase string) error {
+	secrets := make(map[string]string, len(v.file.Entries))
+	for id := range v.file.Entries {
+		secret, err := v.Get(id)
+		if err != nil {
+			return err
+		}
+		secrets[id] = secret
+	}
+
+	if err := v.setPassphrase(newPassphrase); err != nil {
+		return err
+	}
+	for id, secret := range secrets {
+		if err := v.Set(id, secret); err != nil {
+			return err
+		}
+	}
+	return v.Save()
+}
+
+// 保存保险库到文件（仅当前用户可读写）
+func (v *Vault) Save() error {
+	data, err := yaml.Marshal(&v.file)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(v.path, data, 0600)
+}
+
+// 获取保险库文件路径
+func (v *Vault) Path() string {
+	return v.path
+}
+
+// 生成新的条目ID
+func NewID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return "pw-" + hex.EncodeToString(buf)
+}
+
+// 设置新口令：生成新盐值、派生密钥并更新校验串
+func (v *Vault) setPassphrase(passphrase string) error {
+	if passphrase == "" {
+		return errors.New("保险库口令不能为空")
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	params := kdfParams{
+		Name: "scrypt",
+		Salt: base64.StdEncoding.EncodeToString(salt),
+		N:    scryptN,
+		R:    scryptR,
+		P:    scryptP,
+	}
+
+	key, err := deriveKey(passphrase, params)
+	if err != nil {
+		return err
+	}
+	v.key = key
+	v.file.KDF = params
+
+	v.file.Check, err = v.encrypt(checkContent, nil)
+	return err
+}
+
+// 派生加密密钥
+func deriveKey(passphrase string, params kdfParams) ([]byte, error) {
+	if params.Name != "scrypt" {
+		return nil, fmt.Errorf("不支持的密钥派生算法: %s", params.Name)
+	}
+	salt, err := base64.StdEncoding.DecodeString(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("保险库盐值无效: %v", err)
+	}
+	return scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, keyLength)
+}
+
+// AES-GCM 加密，输出 base64(nonce + 密文)；additional 为绑定的附加数据（条目ID）
+func (v *Vault) encrypt(plaintext string, additional []byte) (string, error) {
+	gcm, err := v.newGCM()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), additional)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// AES-GCM 解密，附加数据必须与加密时一致
+func (v *Vault) decrypt(encoded string, additional []byte) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := v.newGCM()
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("密文长度无效")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additional)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(plaintext), nil
+}
+
+func (v *Vault) newGCM() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(v.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,158 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 测试保险库的创建、读写与口令校验
+func TestVaultRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.yaml")
+
+	v, err := Create(path, "correct horse")
+	if err != nil {
+		t.Fatalf("创建保险库失败: %v", err)
+	}
+	if err := v.Set("pw-1", "s3cret"); err != nil {
+		t.Fatalf("写入条目失败: %v", err)
+	}
+	if err := v.Save(); err != nil {
+		t.Fatalf("保存保险库失败: %v", err)
+	}
+
+	// 文件中不应出现明文
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "s3cret") {
+		t.Error("保险库文件中出现了明文密码")
+	}
+
+	if _, err := Open(path, "wrong"); err != ErrWrongPassphrase {
+		t.Errorf("错误口令应返回 ErrWrongPassphrase，实际: %v", err)
+	}
+
+	reopened, err := Open(path, "correct horse")
+	if err != nil {
+		t.Fatalf("打开保险库失败: %v", err)
+	}
+	if secret, _ := reopened.Get("pw-1"); secret != "s3cret" {
+		t.Errorf("读取条目错误: %q", secret)
+	}
+	if _, err := reopened.Get("missing"); err != ErrNotFound {
+		t.Errorf("不存在的条目应返回 ErrNotFound，实际: %v", err)
+	}
+}
+
+// 测试更换口令
+func TestVaultRekey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.yaml")
+
+	v, err := Create(path, "old")
+	if err != nil {
+		t.Fatalf("创建保险库失败: %v", err)
+	}
+	v.Set("pw-1", "s3cret")
+	if err := v.Rekey("new"); err != nil {
+		t.Fatalf("更换口令失败: %v", err)
+	}
+
+	if _, err := Open(path, "old"); err != ErrWrongPassphrase {
+		t.Error("旧口令不应再能打开保险库")
+	}
+	reopened, err := Open(path, "new")
+	if err != nil {
+		t.Fatalf("新口令打开失败: %v", err)
+	}
+	if secret, _ := reopened.Get("pw-1"); secret != "s3cret" {
+		t.Errorf("更换口令后条目内容错误: %q", secret)
+	}
+}
+
+// 测试条目ID绑定到密文：互换两个条目的密文后无法读取
+func TestVaultEntryBinding(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.yaml")
+	v, err := Create(path, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Set("pw-1", "one")
+	v.Set("pw-2", "two")
+	v.file.Entries["pw-1"], v.file.Entries["pw-2"] = v.file.Entries["pw-2"], v.file.Entries["pw-1"]
+	if _, err := v.Get("pw-1"); err != ErrTampered {
+		t.Errorf("互换后的密文应无法读取, 得到 %v", err)
+	}
+}
+
+// 测试旧版本（无附加数据）的保险库打开后可以读取，保存后升级为新格式
+func TestVaultUpgrade(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.yaml")
+	v, err := Create(path, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := v.encrypt("legacy", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.file.Version = 1
+	v.file.Entries["pw-old"] = sealed
+	if err := v.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	opened, err := Open(path, "pass")
+	if err != nil {
+		t.Fatalf("打开旧版本保险库失败: %v", err)
+	}
+	if secret, err := opened.Get("pw-old"); err != nil || secret != "legacy" {
+		t.Errorf("读取旧条目失败: %q %v", secret, err)
+	}
+	if err := opened.Save(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := Open(path, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.file.Version != currentVersion {
+		t.Errorf("保存后应升级到版本 %d", currentVersion)
+	}
+	if secret, err := reopened.decrypt(reopened.file.Entries["pw-old"], []byte("pw-old")); err != nil || secret != "legacy" {
+		t.Errorf("升级后的条目应绑定条目ID: %q %v", secret, err)
+	}
+}
+
+// 测试不需要口令删除条目，已解锁的保险库同步删除
+func TestVaultRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.yaml")
+	t.Setenv("HOSTMANAGER_VAULT", path)
+	v, err := Create(path, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Set("pw-1", "one")
+	v.Set("pw-2", "two")
+	if err := v.Save(); err != nil {
+		t.Fatal(err)
+	}
+	Remember(v)
+	defer Lock()
+
+	if err := Remove("pw-1", "missing"); err != nil {
+		t.Fatalf("删除条目失败: %v", err)
+	}
+	if ids := v.IDs(); len(ids) != 1 || ids[0] != "pw-2" {
+		t.Errorf("已解锁的保险库应同步删除: %v", ids)
+	}
+	reopened, err := Open(path, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.Get("pw-1"); err != ErrNotFound {
+		t.Errorf("条目应已从文件删除: %v", err)
+	}
+	if secret, _ := reopened.Get("pw-2"); secret != "two" {
+		t.Errorf("其他条目应保留: %q", secret)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"log"
 	"os"
 
@@ -12,22 +13,33 @@ import (
 )
 
 func main() {
-	// 加载配置
-	cfg, err := config.LoadConfig("config.yaml")
+	// 检查命令行参数
+	configFlag, args, err := cli.ParseConfigFlag(os.Args[1:]) // 去掉程序名
 	if err != nil {
-		log.Fatalf("无法加载配置文件: %v", err)
+		log.Printf("❌ 错误: %v", err)
+		os.Exit(cli.ExitCode(err))
 	}
 
-	// 检查命令行参数
-	args := os.Args[1:] // 去掉程序名
+	// 加载配置：--config > HOSTMANAGER_CONFIG > 默认查找路径
+	configPath := config.FindConfig(configFlag)
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) || !cli.WorksWithoutConfig(args) {
+			log.Fatalf("无法加载配置文件: %v (可运行 hostmanager init 创建)", err)
+		}
+		cfg = config.NewConfig(configPath)
+	}
 
 	if len(args) > 0 {
 		// CLI模式：有命令行参数时使用命令行接口
+		if !cli.IsLintCommand(args) {
+			cli.PrintDiagnostics(os.Stderr, cfg.Validate())
+		}
 		cliHandler := cli.NewCLI(cfg)
 		err := cliHandler.HandleCommand(args)
 		if err != nil {
 			log.Printf("❌ 错误: %v", err)
-			os.Exit(1)
+			os.Exit(cli.ExitCode(err))
 		}
 	} else {
 		// UI模式：无参数时启动交互式全屏界面
@@ -41,4 +53,4 @@ func main() {
 		menu := ui.NewMenu(cfg)
 		menu.Run()
 	}
-}
\ No newline at end of file
+}